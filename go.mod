@@ -21,6 +21,7 @@ require (
 	github.com/gorilla/websocket v1.4.1
 	github.com/iotaledger/hive.go v0.0.0-20200107205115-986a54f82a30
 	github.com/iotaledger/iota.go v1.0.0-beta.13
+	github.com/klauspost/compress v1.9.7
 	github.com/labstack/echo v3.3.10+incompatible
 	github.com/labstack/gommon v0.3.0 // indirect
 	github.com/mitchellh/mapstructure v1.1.2
@@ -7,6 +7,9 @@ import (
 
 	"github.com/iotaledger/hive.go/node"
 
+	"github.com/gohornet/hornet/packages/toolset"
+	"github.com/gohornet/hornet/plugins/archive"
+	"github.com/gohornet/hornet/plugins/bundletracker"
 	"github.com/gohornet/hornet/plugins/cli"
 	"github.com/gohornet/hornet/plugins/gossip"
 	"github.com/gohornet/hornet/plugins/gracefulshutdown"
@@ -24,8 +27,10 @@ import (
 )
 
 func main() {
+	toolset.ParseArgs()
 	cli.PrintVersion()
 	cli.ParseConfig()
+	toolset.HandleTools()
 
 	runtime.SetMutexProfileFraction(5)
 	runtime.SetBlockProfileRate(5)
@@ -40,6 +45,7 @@ func main() {
 			tangle.PLUGIN,
 			tipselection.PLUGIN,
 			metrics.PLUGIN,
+			archive.PLUGIN,
 			snapshot.PLUGIN,
 			webapi.PLUGIN,
 			spa.PLUGIN,
@@ -48,6 +54,7 @@ func main() {
 			graph.PLUGIN,
 			monitor.PLUGIN,
 			spammer.PLUGIN,
+			bundletracker.PLUGIN,
 		),
 	)
 }
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	// the maximum number of pending writes while a backup is loaded
+	restoreMaxPendingWrites = 256
+)
+
+var (
+	ErrBackupAborted = errors.New("backup aborted")
+)
+
+// abortableWriter stops a running backup by failing the next write after the abort signal was received.
+type abortableWriter struct {
+	writer      io.Writer
+	abortSignal <-chan struct{}
+}
+
+func (w *abortableWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.abortSignal:
+		return 0, ErrBackupAborted
+	default:
+	}
+	return w.writer.Write(p)
+}
+
+// Backup streams a backup of the DB to the writer and returns the version of the backup.
+// If since is not zero, only the entries which were changed after that version are written (incremental backup).
+// The backup is read from a single snapshot of the DB, snapshotTaken is called once the snapshot was taken.
+// The DB is not blocked during the backup, the caller only has to prevent writes which must be consistent
+// with each other until snapshotTaken is called.
+func Backup(w io.Writer, since uint64, snapshotTaken func()) (uint64, error) {
+
+	var snapshotOnce sync.Once
+	notifySnapshotTaken := func() {
+		if snapshotTaken != nil {
+			snapshotOnce.Do(snapshotTaken)
+		}
+	}
+	// an empty DB doesn't contain any key
+	defer notifySnapshotTaken()
+
+	stream := GetBadgerInstance().NewStream()
+	stream.LogPrefix = "DB.Backup"
+	// every iterating goroutine of the stream reads from its own snapshot, a single one reads everything from the same snapshot
+	stream.NumGo = 1
+	stream.ChooseKey = func(item *badger.Item) bool {
+		// the snapshot of the iterator exists once the first key is read
+		notifySnapshotTaken()
+		return true
+	}
+
+	return stream.Backup(w, since)
+}
+
+// BackupToFile writes a backup of the DB to the given file and returns the version of the backup.
+// The backup is written to a temporary file first, so that an interrupted backup doesn't replace an older one.
+// snapshotTaken is called once the snapshot of the backup was taken, it may be nil.
+func BackupToFile(filePath string, since uint64, abortSignal <-chan struct{}, snapshotTaken func()) (uint64, error) {
+
+	filePathTmp := filePath + "_tmp"
+
+	// Remove old temp file
+	os.Remove(filePathTmp)
+
+	backupFile, err := os.OpenFile(filePathTmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return 0, err
+	}
+
+	buf := bufio.NewWriter(backupFile)
+
+	version, err := Backup(&abortableWriter{writer: buf, abortSignal: abortSignal}, since, snapshotTaken)
+	if err == nil {
+		err = buf.Flush()
+	}
+	if closeErr := backupFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filePathTmp)
+		return 0, err
+	}
+
+	if err := os.Rename(filePathTmp, filePath); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// RestoreFromFile loads a full or an incremental backup into the DB.
+// Incremental backups have to be restored in the order they were created, on top of the full backup.
+// The node must not be running at the same time.
+func RestoreFromFile(filePath string) error {
+
+	backupFile, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer backupFile.Close()
+
+	return GetBadgerInstance().Load(bufio.NewReader(backupFile), restoreMaxPendingWrites)
+}
@@ -1,7 +1,6 @@
 package database
 
 import (
-	"fmt"
 	"runtime"
 	"sync"
 
@@ -17,6 +16,7 @@ var (
 	once       sync.Once
 	directory  = "mainnetdb"
 	badgerOpts *profile.BadgerOpts
+	readOnly   bool
 )
 
 // Settings sets DB dir and the badger options
@@ -25,6 +25,17 @@ func Settings(dir string, options *profile.BadgerOpts) {
 	badgerOpts = options
 }
 
+// SetReadOnly opens the DB in read-only mode, so that it can be inspected without modifying it.
+// It has to be called before the instance is created.
+func SetReadOnly(enabled bool) {
+	readOnly = enabled
+}
+
+// IsReadOnly returns whether the DB is opened in read-only mode.
+func IsReadOnly() bool {
+	return readOnly
+}
+
 func GetBadgerInstance() *badger.DB {
 	once.Do(func() {
 
@@ -55,7 +66,8 @@ func GetBadgerInstance() *badger.DB {
 			WithValueThreshold(badgerOpts.ValueThreshold).
 			WithTruncate(badgerOpts.WithTruncate).
 			WithLogRotatesToFlush(badgerOpts.LogRotatesToFlush).
-			WithEventLogging(badgerOpts.EventLogging)
+			WithEventLogging(badgerOpts.EventLogging).
+			WithReadOnly(readOnly)
 
 		if runtime.GOOS == "windows" {
 			opts = opts.WithTruncate(true)
@@ -70,15 +82,3 @@ func GetBadgerInstance() *badger.DB {
 	})
 	return instance
 }
-
-func CleanupBadgerInstance() {
-
-	db := GetBadgerInstance()
-
-	fmt.Println("Run badger garbage collection")
-
-	var err error
-	for err == nil {
-		err = db.RunValueLogGC(0.7)
-	}
-}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+var (
+	// serializes the value log garbage collection and the compaction, badger rejects concurrent runs
+	maintenanceLock    sync.Mutex
+	lastGCResultLock   sync.RWMutex
+	lastGCResult       *GarbageCollectionResult
+	lastCompactionLock sync.RWMutex
+	lastCompactionTime time.Time
+)
+
+// GarbageCollectionResult is the result of a value log garbage collection run.
+type GarbageCollectionResult struct {
+	Time int64 `json:"time"`
+	// the duration of the run in milliseconds
+	Duration       int64   `json:"duration"`
+	DiscardRatio   float64 `json:"discardRatio"`
+	RewrittenFiles int     `json:"rewrittenFiles"`
+	VlogSizeBefore int64   `json:"vlogSizeBefore"`
+	VlogSizeAfter  int64   `json:"vlogSizeAfter"`
+	ReclaimedBytes int64   `json:"reclaimedBytes"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// RunGarbageCollection rewrites the value log files which contain at least the given ratio of discardable data
+// and returns the result of the run, which is also kept as the last result.
+func RunGarbageCollection(discardRatio float64) *GarbageCollectionResult {
+
+	maintenanceLock.Lock()
+	defer maintenanceLock.Unlock()
+
+	ts := time.Now()
+	result := &GarbageCollectionResult{
+		Time:         ts.Unix(),
+		DiscardRatio: discardRatio,
+	}
+
+	_, result.VlogSizeBefore, _ = Size()
+
+	rewrittenFiles, err := RunValueLogGC(discardRatio)
+	result.RewrittenFiles = rewrittenFiles
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	_, result.VlogSizeAfter, _ = Size()
+	result.ReclaimedBytes = result.VlogSizeBefore - result.VlogSizeAfter
+	result.Duration = time.Since(ts).Milliseconds()
+
+	lastGCResultLock.Lock()
+	lastGCResult = result
+	lastGCResultLock.Unlock()
+
+	return result
+}
+
+// GetLastGarbageCollectionResult returns the result of the last value log garbage collection, or nil if it didn't run yet.
+func GetLastGarbageCollectionResult() *GarbageCollectionResult {
+	lastGCResultLock.RLock()
+	defer lastGCResultLock.RUnlock()
+
+	return lastGCResult
+}
+
+// Compact merges all levels of the LSM tree into the last level, so that deleted and overwritten keys
+// are dropped from the tables. The value log files are only shrunk by the following garbage collection.
+func Compact() error {
+
+	maintenanceLock.Lock()
+	defer maintenanceLock.Unlock()
+
+	if err := GetBadgerInstance().Flatten(runtime.NumCPU()); err != nil {
+		return err
+	}
+
+	lastCompactionLock.Lock()
+	lastCompactionTime = time.Now()
+	lastCompactionLock.Unlock()
+
+	return nil
+}
+
+// GetLastCompactionTime returns the time of the last compaction, or the zero time if it didn't run yet.
+func GetLastCompactionTime() time.Time {
+	lastCompactionLock.RLock()
+	defer lastCompactionLock.RUnlock()
+
+	return lastCompactionTime
+}
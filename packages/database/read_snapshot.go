@@ -0,0 +1,54 @@
+package database
+
+import (
+	"github.com/dgraph-io/badger/v2"
+)
+
+// ReadSnapshot is a consistent read-only view of the DB at the time it was created.
+// Writes to the DB which happen afterwards are not visible in the snapshot.
+type ReadSnapshot struct {
+	txn *badger.Txn
+}
+
+// NewReadSnapshot creates a read-only view of the current state of the DB.
+// Discard has to be called once the snapshot is not needed anymore.
+func NewReadSnapshot() *ReadSnapshot {
+	return &ReadSnapshot{txn: GetBadgerInstance().NewTransaction(false)}
+}
+
+// ForEachPrefix iterates over all entries with the given key prefix until the consumer returns true.
+// The prefix is not removed from the keys.
+func (s *ReadSnapshot) ForEachPrefix(prefix []byte, consumer func(key []byte, value []byte) bool) error {
+	return s.ForEachPrefixFrom(prefix, prefix, consumer)
+}
+
+// ForEachPrefixFrom iterates over the entries with the given key prefix, starting at the first key which is equal
+// to or greater than the start key, until the consumer returns true. The prefix is not removed from the keys.
+func (s *ReadSnapshot) ForEachPrefixFrom(prefix []byte, start []byte, consumer func(key []byte, value []byte) bool) error {
+
+	iterOpts := badger.DefaultIteratorOptions
+	iterOpts.Prefix = prefix
+
+	it := s.txn.NewIterator(iterOpts)
+	defer it.Close()
+
+	for it.Seek(start); it.ValidForPrefix(prefix); it.Next() {
+		item := it.Item()
+
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return err
+		}
+
+		if consumer(item.KeyCopy(nil), value) {
+			break
+		}
+	}
+
+	return nil
+}
+
+// Discard releases the snapshot.
+func (s *ReadSnapshot) Discard() {
+	s.txn.Discard()
+}
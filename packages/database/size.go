@@ -0,0 +1,55 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dgraph-io/badger/v2"
+)
+
+// Size returns the size of the LSM tree and the value log files of the DB on the disk.
+// The sizes are read from the files, because the values of badger are only updated once a minute.
+func Size() (lsmSize int64, vlogSize int64, err error) {
+
+	err = filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case info.IsDir():
+		case strings.HasSuffix(info.Name(), ".sst"):
+			lsmSize += info.Size()
+		case strings.HasSuffix(info.Name(), ".vlog"):
+			vlogSize += info.Size()
+		}
+		return nil
+	})
+
+	return lsmSize, vlogSize, err
+}
+
+// FormatSize formats a size of the DB in MB for the log.
+func FormatSize(size int64) string {
+	return fmt.Sprintf("%.2f MB", float64(size)/1024/1024)
+}
+
+// RunValueLogGC rewrites value log files until no file with at least the given ratio of discardable data is left
+// and returns the amount of rewritten files.
+func RunValueLogGC(discardRatio float64) (int, error) {
+
+	db := GetBadgerInstance()
+
+	var rewrittenFiles int
+	for {
+		if err := db.RunValueLogGC(discardRatio); err != nil {
+			if err == badger.ErrNoRewrite {
+				return rewrittenFiles, nil
+			}
+			return rewrittenFiles, err
+		}
+		rewrittenFiles++
+	}
+}
@@ -38,6 +38,11 @@ func (s *SolidEntryPoints) Contains(transactionHash trinary.Hash) bool {
 	return exists
 }
 
+func (s *SolidEntryPoints) Index(transactionHash trinary.Hash) (milestone_index.MilestoneIndex, bool) {
+	index, exists := s.entryPointsMap[transactionHash]
+	return index, exists
+}
+
 func (s *SolidEntryPoints) Add(transactionHash trinary.Hash, milestoneIndex milestone_index.MilestoneIndex) {
 	if _, exists := s.entryPointsMap[transactionHash]; !exists {
 		s.entryPointsMap[transactionHash] = milestoneIndex
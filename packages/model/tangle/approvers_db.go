@@ -72,3 +72,16 @@ func DeleteApproversInDatabase(approvers []*Approvers) error {
 
 	return nil
 }
+
+// ForEachApproverInDatabase iterates over all approver entries in the database without using the cache.
+// The consumer can stop the iteration by returning true.
+func ForEachApproverInDatabase(consumer func(txHash trinary.Hash, approverHash trinary.Hash) bool) error {
+	err := approversDatabase.ForEachPrefixKeyOnly(database.KeyPrefix{}, func(entry database.KeyOnlyEntry) (stop bool) {
+		return consumer(trinary.MustBytesToTrytes(entry.Key[:49], 81), trinary.MustBytesToTrytes(entry.Key[49:], 81))
+	})
+
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to iterate over approvers")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package tangle
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/database"
+
+	hornetDB "github.com/gohornet/hornet/packages/database"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+)
+
+var (
+	balanceHistoryPrefix = []byte("history")
+)
+
+// BalanceChange is a change of the balance of an address caused by a milestone.
+type BalanceChange struct {
+	MilestoneIndex milestone_index.MilestoneIndex
+	Change         int64
+	// The balance of the address after the change was applied
+	Balance uint64
+}
+
+func databaseKeyPrefixForBalanceHistory(address trinary.Hash) []byte {
+	return append(balanceHistoryPrefix, trinary.MustTrytesToBytes(address)[:49]...)
+}
+
+// the milestone index is stored in big endian to iterate the history in ascending order
+func databaseKeyForBalanceHistory(address trinary.Hash, milestoneIndex milestone_index.MilestoneIndex) []byte {
+	indexBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(indexBytes, uint32(milestoneIndex))
+	return append(databaseKeyPrefixForBalanceHistory(address), indexBytes...)
+}
+
+func entryForBalanceHistory(address trinary.Hash, milestoneIndex milestone_index.MilestoneIndex, change int64, balance uint64) database.Entry {
+	return database.Entry{
+		Key:   databaseKeyForBalanceHistory(address, milestoneIndex),
+		Value: append(bytesFromDiff(change), bytesFromBalance(balance)...),
+	}
+}
+
+// GetBalanceHistoryForAddress returns the balance changes of the address, starting at the given milestone index.
+// The history only contains changes which were applied after the index was introduced
+// and which were not pruned together with the ledger diffs yet.
+// The second return value is the milestone index to continue with, or 0 if there are no further changes.
+func GetBalanceHistoryForAddress(address trinary.Hash, startIndex milestone_index.MilestoneIndex, limit int) ([]*BalanceChange, milestone_index.MilestoneIndex, error) {
+
+	// the ledger is only locked while the view is created
+	ReadLockLedger()
+	snapshot := hornetDB.NewReadSnapshot()
+	ReadUnlockLedger()
+	defer snapshot.Discard()
+
+	var changes []*BalanceChange
+	var nextIndex milestone_index.MilestoneIndex
+
+	prefix := append([]byte{DBPrefixLedgerState}, databaseKeyPrefixForBalanceHistory(address)...)
+	start := append([]byte{DBPrefixLedgerState}, databaseKeyForBalanceHistory(address, startIndex)...)
+
+	err := snapshot.ForEachPrefixFrom(prefix, start, func(key []byte, value []byte) bool {
+		milestoneIndex := milestone_index.MilestoneIndex(binary.BigEndian.Uint32(key[len(prefix):]))
+
+		if len(changes) >= limit {
+			nextIndex = milestoneIndex
+			return true
+		}
+
+		changes = append(changes, &BalanceChange{
+			MilestoneIndex: milestoneIndex,
+			Change:         diffFromBytes(value[:8]),
+			Balance:        balanceFromBytes(value[8:16]),
+		})
+		return false
+	})
+
+	if err != nil {
+		return nil, 0, errors.Wrap(NewDatabaseError(err), "failed to retrieve balance history")
+	}
+
+	return changes, nextIndex, nil
+}
@@ -726,6 +726,11 @@ func (bundle *Bundle) IsConfirmed() bool {
 	}
 }
 
+// ResetConfirmed removes the cached confirmation flag of the bundle, e.g. after a ledger rollback.
+func (bundle *Bundle) ResetConfirmed() {
+	bundle.setConfirmed(false)
+}
+
 func (bundle *Bundle) setConfirmed(confirmed bool) {
 	bundle.metadataMutex.Lock()
 	defer bundle.metadataMutex.Unlock()
@@ -0,0 +1,71 @@
+package tangle
+
+import (
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+)
+
+// BundleTailStatus contains the state of a single (re)attachment of a bundle.
+type BundleTailStatus struct {
+	TailHash                      trinary.Hash
+	FirstSeenTimestamp            int64
+	FirstSeenLatestMilestoneIndex milestone_index.MilestoneIndex
+	Complete                      bool
+	Valid                         bool
+	ConfirmationIndex             milestone_index.MilestoneIndex
+}
+
+func (s *BundleTailStatus) IsConfirmed() bool {
+	return s.ConfirmationIndex != 0
+}
+
+// GetTailStatus returns the state of all known tails of the bucket.
+// Tails which are not yet mapped to a bundle instance are included as incomplete.
+func (bucket *BundleBucket) GetTailStatus() ([]*BundleTailStatus, error) {
+
+	bundleTails, err := ReadBundleTailsFromDatabase(bucket.GetHash())
+	if err != nil {
+		return nil, err
+	}
+
+	statusByTail := make(map[trinary.Hash]*BundleTailStatus)
+	for _, bundleTail := range bundleTails {
+		statusByTail[bundleTail.TailHash] = &BundleTailStatus{
+			TailHash:                      bundleTail.TailHash,
+			FirstSeenTimestamp:            bundleTail.FirstSeenTimestamp,
+			FirstSeenLatestMilestoneIndex: bundleTail.FirstSeenLatestMilestoneIndex,
+		}
+	}
+
+	for _, bundle := range bucket.Bundles() {
+		tailHash := bundle.GetTailHash()
+		status, exists := statusByTail[tailHash]
+		if !exists {
+			// the tail was not yet persisted
+			status = &BundleTailStatus{TailHash: tailHash}
+			statusByTail[tailHash] = status
+		}
+
+		status.Complete = bundle.IsComplete()
+		status.Valid = status.Complete && bundle.IsValid()
+	}
+
+	result := make([]*BundleTailStatus, 0, len(statusByTail))
+	for tailHash, status := range statusByTail {
+		tx, err := GetTransaction(tailHash)
+		if err != nil {
+			return nil, err
+		}
+		if tx == nil {
+			// the tail was pruned in the meantime
+			continue
+		}
+		if confirmed, at := tx.GetConfirmed(); confirmed {
+			status.ConfirmationIndex = at
+		}
+		result = append(result, status)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package tangle
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/database"
+
+	hornetDB "github.com/gohornet/hornet/packages/database"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+)
+
+var (
+	bundleTailsDatabase database.Database
+)
+
+func configureBundleTailsDatabase() {
+	if db, err := database.Get(DBPrefixBundleTails, hornetDB.GetBadgerInstance()); err != nil {
+		panic(err)
+	} else {
+		bundleTailsDatabase = db
+	}
+}
+
+func databaseKeyForBundleTail(bundleHash trinary.Hash, tailHash trinary.Hash) []byte {
+	return append(databaseKeyPrefixForBundleHash(bundleHash), trinary.MustTrytesToBytes(tailHash)...)
+}
+
+// BundleTail is an attachment (reattachment) of a bundle, identified by its tail transaction.
+type BundleTail struct {
+	BundleHash trinary.Hash
+	TailHash   trinary.Hash
+	// Unix time when the tail was first seen by the node
+	FirstSeenTimestamp            int64
+	FirstSeenLatestMilestoneIndex milestone_index.MilestoneIndex
+}
+
+func (t *BundleTail) GetBytes() []byte {
+	bytes := make([]byte, 12)
+	binary.LittleEndian.PutUint64(bytes[:8], uint64(t.FirstSeenTimestamp))
+	binary.LittleEndian.PutUint32(bytes[8:], uint32(t.FirstSeenLatestMilestoneIndex))
+	return bytes
+}
+
+func StoreBundleTailsInDatabase(bundleTails []*BundleTail) error {
+
+	// Create entries for all tails
+	var entries []database.Entry
+
+	for _, bundleTail := range bundleTails {
+		entry := database.Entry{
+			Key:   databaseKeyForBundleTail(bundleTail.BundleHash, bundleTail.TailHash),
+			Value: bundleTail.GetBytes(),
+		}
+		entries = append(entries, entry)
+	}
+
+	// Now batch insert all entries
+	if err := bundleTailsDatabase.Apply(entries, []database.Key{}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store bundle tails in database")
+	}
+
+	return nil
+}
+
+// DeleteBundleTailsInDatabase deletes the given tails (tail hash => bundle hash) from the database.
+func DeleteBundleTailsInDatabase(tails map[trinary.Hash]trinary.Hash) error {
+
+	var deletions []database.Key
+
+	for tailHash, bundleHash := range tails {
+		deletions = append(deletions, databaseKeyForBundleTail(bundleHash, tailHash))
+	}
+
+	// Now batch delete all entries
+	if err := bundleTailsDatabase.Apply([]database.Entry{}, deletions); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete bundle tails")
+	}
+
+	return nil
+}
+
+func ReadBundleTailsFromDatabase(bundleHash trinary.Hash) ([]*BundleTail, error) {
+
+	var bundleTails []*BundleTail
+
+	err := bundleTailsDatabase.ForEachPrefix(databaseKeyPrefixForBundleHash(bundleHash), func(entry database.Entry) (stop bool) {
+		if len(entry.Value) != 12 {
+			// skip invalid entries
+			return false
+		}
+
+		bundleTails = append(bundleTails, &BundleTail{
+			BundleHash:                    bundleHash,
+			TailHash:                      transactionHashFromDatabaseKey(entry.Key),
+			FirstSeenTimestamp:            int64(binary.LittleEndian.Uint64(entry.Value[:8])),
+			FirstSeenLatestMilestoneIndex: milestoneIndexFromBytes(entry.Value[8:12]),
+		})
+		return false
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to read bundle tails from database")
+	}
+
+	return bundleTails, nil
+}
@@ -11,4 +11,7 @@ const (
 	DBPrefixSnapshot              byte = 7
 	DBPrefixFirstSeenTransactions byte = 8
 	DBPrefixSpentAddresses        byte = 9
+	DBPrefixTrackedBundles        byte = 10
+	DBPrefixBundleTails           byte = 11
+	DBPrefixWatchlist             byte = 12
 )
@@ -7,10 +7,11 @@ import (
 	"github.com/iotaledger/hive.go/typeutils"
 
 	hornetDB "github.com/gohornet/hornet/packages/database"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
 )
 
 const (
-	DbVersion = 3
+	DbVersion = 4
 )
 
 var (
@@ -24,7 +25,9 @@ func configureHealthDatabase() {
 		healthDatabase = db
 	}
 
-	setDatabaseVersion()
+	if !hornetDB.IsReadOnly() {
+		setDatabaseVersion()
+	}
 }
 
 func MarkDatabaseCorrupted() {
@@ -67,6 +70,33 @@ func setDatabaseVersion() {
 	}
 }
 
+// GetDatabaseVersion returns the version of the database scheme.
+func GetDatabaseVersion() (byte, error) {
+
+	entry, err := healthDatabase.Get(typeutils.StringToBytes("dbVersion"))
+	if err != nil {
+		return 0, errors.Wrap(NewDatabaseError(err), "failed to read database version")
+	}
+
+	if len(entry.Value) == 0 {
+		return 0, errors.New("database version is empty")
+	}
+
+	return entry.Value[0], nil
+}
+
+func storeDatabaseVersion(version byte) error {
+
+	if err := healthDatabase.Set(
+		database.Entry{
+			Key:   typeutils.StringToBytes("dbVersion"),
+			Value: []byte{version},
+		}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to set database version")
+	}
+	return nil
+}
+
 func IsCorrectDatabaseVersion() bool {
 
 	entry, err := healthDatabase.Get(typeutils.StringToBytes("dbVersion"))
@@ -80,3 +110,31 @@ func IsCorrectDatabaseVersion() bool {
 
 	return false
 }
+
+// StoreBackupMilestoneIndex stores the confirmed milestone index of the ledger state, so that it is part of the next backup.
+func StoreBackupMilestoneIndex(index milestone_index.MilestoneIndex) error {
+
+	if err := healthDatabase.Set(
+		database.Entry{
+			Key:   typeutils.StringToBytes("backupMilestoneIndex"),
+			Value: bytesFromMilestoneIndex(index),
+		}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to set backup milestone index")
+	}
+	return nil
+}
+
+// GetBackupMilestoneIndex returns the confirmed milestone index of the latest backup which is contained in the database.
+// It returns false if the database doesn't contain a backup milestone index.
+func GetBackupMilestoneIndex() (milestone_index.MilestoneIndex, bool, error) {
+
+	entry, err := healthDatabase.Get(typeutils.StringToBytes("backupMilestoneIndex"))
+	if err != nil {
+		if err == database.ErrKeyNotFound {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrap(NewDatabaseError(err), "failed to read backup milestone index")
+	}
+
+	return milestoneIndexFromBytes(entry.Value), true, nil
+}
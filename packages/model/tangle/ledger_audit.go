@@ -0,0 +1,221 @@
+package tangle
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/database"
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/packages/compressed"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+)
+
+var (
+	ErrLedgerInvariantViolated = errors.New("ledger invariant violated")
+
+	// running total of all balances, which is updated with the persisted balances after every milestone.
+	// a valid ledger is assumed at startup until a full recount determines the actual supply.
+	ledgerSupply     = compressed.TOTAL_SUPPLY
+	ledgerSupplyLock syncutils.Mutex
+
+	// the reason of the first detected ledger invariant violation, empty if the ledger is valid
+	ledgerInvariantViolation     string
+	ledgerInvariantViolationLock syncutils.RWMutex
+)
+
+// LedgerAuditResult is the result of a full recount of the ledger.
+type LedgerAuditResult struct {
+	MilestoneIndex milestone_index.MilestoneIndex
+	AddressCount   int
+	Supply         uint64
+	Violations     []string
+}
+
+// SetLedgerInvariantViolated marks the ledger as invalid and the database as corrupted.
+// Balances must not be served until an operator resets the violation.
+func SetLedgerInvariantViolated(reason string) {
+	ledgerInvariantViolationLock.Lock()
+	defer ledgerInvariantViolationLock.Unlock()
+
+	if ledgerInvariantViolation == "" {
+		ledgerInvariantViolation = reason
+	}
+	MarkDatabaseCorrupted()
+}
+
+// ResetLedgerInvariantViolation marks the ledger as valid again.
+// The database stays marked as corrupted until the node is shut down cleanly.
+func ResetLedgerInvariantViolation() {
+	ledgerInvariantViolationLock.Lock()
+	defer ledgerInvariantViolationLock.Unlock()
+
+	ledgerInvariantViolation = ""
+}
+
+// GetLedgerInvariantViolation returns the reason of the detected ledger invariant violation, or an empty string.
+func GetLedgerInvariantViolation() string {
+	ledgerInvariantViolationLock.RLock()
+	defer ledgerInvariantViolationLock.RUnlock()
+
+	return ledgerInvariantViolation
+}
+
+// IsLedgerInvariantViolated returns whether a ledger invariant violation was detected.
+func IsLedgerInvariantViolated() bool {
+	return GetLedgerInvariantViolation() != ""
+}
+
+// CheckLedgerDiffInvariantsWithoutLocking checks the persisted balances of all addresses touched by the milestone
+// against the balances which were applied with its ledger diff, and updates the running supply total with them.
+// It has to be called right after the ledger diff was applied, while the ledger is still write locked.
+// It returns a description for every violation found.
+func CheckLedgerDiffInvariantsWithoutLocking(index milestone_index.MilestoneIndex) ([]string, error) {
+
+	if index != ledgerMilestoneIndex {
+		return nil, fmt.Errorf("milestone %d is not the milestone of the ledger state %d", index, ledgerMilestoneIndex)
+	}
+
+	diff, err := GetLedgerDiffForMilestoneWithoutLocking(index, nil)
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to retrieve ledger diff")
+	}
+
+	var violations []string
+	var supplyChange int64
+
+	for address, change := range diff {
+		balance, _, err := GetBalanceForAddressWithoutLocking(address)
+		if err != nil {
+			return nil, err
+		}
+
+		if balance > compressed.TOTAL_SUPPLY {
+			violations = append(violations, fmt.Sprintf("balance of address %s at milestone %d exceeds the total supply: %d", address, index, balance))
+		}
+
+		// the balance history contains the balance which was applied with the ledger diff
+		balanceBefore := int64(balance) - change
+		entry, err := ledgerDatabase.Get(databaseKeyForBalanceHistory(address, index))
+		if err != nil && err != database.ErrKeyNotFound {
+			return nil, errors.Wrap(NewDatabaseError(err), "failed to retrieve balance history")
+		}
+		if err == nil {
+			appliedBalance := balanceFromBytes(entry.Value[8:16])
+			if appliedBalance != balance {
+				violations = append(violations, fmt.Sprintf("persisted balance of address %s at milestone %d differs from the applied balance: %d != %d", address, index, balance, appliedBalance))
+			}
+			balanceBefore = int64(appliedBalance) - change
+		}
+
+		supplyChange += int64(balance) - balanceBefore
+	}
+
+	ledgerSupplyLock.Lock()
+	defer ledgerSupplyLock.Unlock()
+
+	ledgerSupply = uint64(int64(ledgerSupply) + supplyChange)
+	if ledgerSupply != compressed.TOTAL_SUPPLY {
+		violations = append(violations, fmt.Sprintf("ledger does not sum up to the total supply after milestone %d: %d != %d", index, ledgerSupply, compressed.TOTAL_SUPPLY))
+	}
+
+	return violations, nil
+}
+
+// CheckStoredLedgerDiffInvariants checks that the stored ledger diff of the milestone sums up to zero and that
+// the stored balance history of the touched addresses was neither negative nor above the total supply.
+// It is used to find corrupted entries on disk, the diffs are already checked before they are applied.
+// It returns a description for every violation found.
+func CheckStoredLedgerDiffInvariants(index milestone_index.MilestoneIndex) ([]string, error) {
+
+	ReadLockLedger()
+	defer ReadUnlockLedger()
+
+	var violations []string
+	var diffSum int64
+
+	diff := make(map[trinary.Hash]int64)
+	err := ledgerDatabase.ForEachPrefix(databaseKeyPrefixForLedgerDiff(index), func(entry database.Entry) bool {
+		diff[trinary.MustBytesToTrytes(entry.Key, 81)] = diffFromBytes(entry.Value)
+		return false
+	})
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to retrieve ledger diff")
+	}
+
+	for address, change := range diff {
+		diffSum += change
+
+		entry, err := ledgerDatabase.Get(databaseKeyForBalanceHistory(address, index))
+		if err != nil {
+			if err == database.ErrKeyNotFound {
+				// the history index may not exist for milestones which were applied before it was introduced
+				continue
+			}
+			return nil, errors.Wrap(NewDatabaseError(err), "failed to retrieve balance history")
+		}
+
+		balance := balanceFromBytes(entry.Value[8:16])
+		if balance > compressed.TOTAL_SUPPLY {
+			violations = append(violations, fmt.Sprintf("balance of address %s at milestone %d exceeds the total supply: %d", address, index, balance))
+		}
+		if int64(balance)-change < 0 {
+			violations = append(violations, fmt.Sprintf("balance of address %s before milestone %d was negative: %d", address, index, int64(balance)-change))
+		}
+	}
+
+	if diffSum != 0 {
+		violations = append(violations, fmt.Sprintf("ledger diff of milestone %d does not sum up to zero: %d", index, diffSum))
+	}
+
+	return violations, nil
+}
+
+// AuditLedger recounts all balances of the ledger and checks them against the total supply.
+func AuditLedger(abortSignal <-chan struct{}) (*LedgerAuditResult, error) {
+
+	ReadLockLedger()
+	defer ReadUnlockLedger()
+
+	result := &LedgerAuditResult{MilestoneIndex: ledgerMilestoneIndex}
+
+	var aborted bool
+	err := ForEachBalanceWithoutLocking(func(address trinary.Hash, balance uint64) bool {
+		select {
+		case <-abortSignal:
+			aborted = true
+			return true
+		default:
+		}
+
+		result.AddressCount++
+		result.Supply += balance
+
+		if balance == 0 {
+			result.Violations = append(result.Violations, fmt.Sprintf("address %s is stored with a zero balance", address))
+		}
+		if balance > compressed.TOTAL_SUPPLY {
+			result.Violations = append(result.Violations, fmt.Sprintf("balance of address %s exceeds the total supply: %d", address, balance))
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if aborted {
+		return nil, ErrOperationAborted
+	}
+
+	ledgerSupplyLock.Lock()
+	ledgerSupply = result.Supply
+	ledgerSupplyLock.Unlock()
+
+	if result.Supply != compressed.TOTAL_SUPPLY {
+		result.Violations = append(result.Violations, fmt.Sprintf("ledger does not sum up to the total supply: %d != %d", result.Supply, compressed.TOTAL_SUPPLY))
+	}
+
+	return result, nil
+}
@@ -159,7 +159,9 @@ func DeleteLedgerDiffForMilestone(index milestone_index.MilestoneIndex) error {
 	var deletions []database.Key
 
 	err := ledgerDatabase.StreamForEachPrefixKeyOnly(databaseKeyPrefixForLedgerDiff(index), func(entry database.KeyOnlyEntry) error {
-		deletions = append(deletions, entry.Key)
+		address := trinary.MustBytesToTrytes(entry.Key, 81)
+		deletions = append(deletions, databaseKeyForLedgerDiffAndAddress(index, address))
+		deletions = append(deletions, databaseKeyForBalanceHistory(address, index))
 		return nil
 	})
 
@@ -222,6 +224,7 @@ func GetLedgerDiffForMilestone(index milestone_index.MilestoneIndex, abortSignal
 func ApplyLedgerDiffWithoutLocking(diff map[trinary.Hash]int64, index milestone_index.MilestoneIndex) error {
 
 	var diffEntries []database.Entry
+	var historyEntries []database.Entry
 	var balanceChanges []database.Entry
 	var emptyAddresses []database.Key
 
@@ -253,6 +256,8 @@ func ApplyLedgerDiffWithoutLocking(diff map[trinary.Hash]int64, index milestone_
 			Value: bytesFromDiff(change),
 		})
 
+		historyEntries = append(historyEntries, entryForBalanceHistory(address, index, change, uint64(newBalance)))
+
 		diffSum += change
 	}
 
@@ -262,6 +267,7 @@ func ApplyLedgerDiffWithoutLocking(diff map[trinary.Hash]int64, index milestone_
 
 	entries := balanceChanges
 	entries = append(entries, diffEntries...)
+	entries = append(entries, historyEntries...)
 	entries = append(entries, entryForMilestoneIndex(index))
 	deletions := emptyAddresses
 
@@ -274,6 +280,59 @@ func ApplyLedgerDiffWithoutLocking(diff map[trinary.Hash]int64, index milestone_
 	return nil
 }
 
+// RollbackLedgerDiffWithoutLocking reverts the changes of the milestone, which must be the milestone of the current ledger state.
+// The ledger diff and the balance history of the milestone are removed.
+// WriteLockLedger must be held while entering this function.
+func RollbackLedgerDiffWithoutLocking(index milestone_index.MilestoneIndex) error {
+
+	if index != ledgerMilestoneIndex {
+		return fmt.Errorf("milestone %d is not the milestone of the ledger state %d", index, ledgerMilestoneIndex)
+	}
+
+	diff, err := GetLedgerDiffForMilestoneWithoutLocking(index, nil)
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to retrieve ledger diff")
+	}
+
+	var balanceChanges []database.Entry
+	var deletions []database.Key
+
+	for address, change := range diff {
+
+		balance, _, err := GetBalanceForAddressWithoutLocking(address)
+		if err != nil {
+			return err
+		}
+
+		newBalance := int64(balance) - change
+
+		if newBalance < 0 {
+			return fmt.Errorf("rollback of milestone %d creates negative balance for address %s: current %d, diff %d", index, address, balance, change)
+		} else if newBalance > 0 {
+			balanceChanges = append(balanceChanges, database.Entry{
+				Key:   databaseKeyForAddressBalance(address),
+				Value: bytesFromBalance(uint64(newBalance)),
+			})
+		} else {
+			// Balance is zero, so we can remove this address from the ledger
+			deletions = append(deletions, databaseKeyForAddressBalance(address))
+		}
+
+		deletions = append(deletions, databaseKeyForLedgerDiffAndAddress(index, address))
+		deletions = append(deletions, databaseKeyForBalanceHistory(address, index))
+	}
+
+	entries := append(balanceChanges, entryForMilestoneIndex(index-1))
+
+	// Now batch insert/delete all entries
+	if err := ledgerDatabase.Apply(entries, deletions); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to rollback ledger diff")
+	}
+
+	ledgerMilestoneIndex = index - 1
+	return nil
+}
+
 func StoreBalancesInDatabase(balances map[trinary.Hash]uint64, index milestone_index.MilestoneIndex) error {
 
 	WriteLockLedger()
@@ -339,6 +398,82 @@ func GetAllBalancesWithoutLocking(abortSignal <-chan struct{}) (map[trinary.Hash
 	return balances, ledgerMilestoneIndex, err
 }
 
+// ForEachBalanceWithoutLocking iterates over all balances for the current solid milestone in ascending address order.
+// Iteration stops if the consumer returns true.
+// ReadLockLedger must be held while entering this function.
+func ForEachBalanceWithoutLocking(consumer func(address trinary.Hash, balance uint64) bool) error {
+
+	err := ledgerDatabase.ForEachPrefix(balancePrefix, func(entry database.Entry) bool {
+		return consumer(trinary.MustBytesToTrytes(entry.Key, 81), balanceFromBytes(entry.Value))
+	})
+
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to iterate over balances")
+	}
+
+	return nil
+}
+
+// LedgerSnapshot is a consistent view of the balances at a milestone, which can be read without holding the ledger lock.
+type LedgerSnapshot struct {
+	MilestoneIndex milestone_index.MilestoneIndex
+	snapshot       *hornetDB.ReadSnapshot
+}
+
+// NewLedgerSnapshot creates a view of the balances at the current ledger milestone. The ledger is only locked while the view is created.
+// Discard has to be called once the snapshot is not needed anymore.
+func NewLedgerSnapshot() *LedgerSnapshot {
+
+	ReadLockLedger()
+	defer ReadUnlockLedger()
+
+	return &LedgerSnapshot{
+		MilestoneIndex: ledgerMilestoneIndex,
+		snapshot:       hornetDB.NewReadSnapshot(),
+	}
+}
+
+// ForEachBalance iterates over all balances of the snapshot until the consumer returns true.
+func (s *LedgerSnapshot) ForEachBalance(consumer func(address trinary.Hash, balance uint64) bool) error {
+
+	prefix := append([]byte{DBPrefixLedgerState}, balancePrefix...)
+	err := s.snapshot.ForEachPrefix(prefix, func(key []byte, value []byte) bool {
+		return consumer(trinary.MustBytesToTrytes(key[len(prefix):], 81), balanceFromBytes(value))
+	})
+
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to iterate over balances")
+	}
+
+	return nil
+}
+
+// Discard releases the snapshot.
+func (s *LedgerSnapshot) Discard() {
+	s.snapshot.Discard()
+}
+
+// GetStoredLedgerMilestoneIndex reads the milestone index of the ledger state from the database,
+// e.g. to check a database which was restored from a backup. It returns 0 if the database contains no ledger state.
+func GetStoredLedgerMilestoneIndex() (milestone_index.MilestoneIndex, error) {
+
+	entry, err := ledgerDatabase.Get(typeutils.StringToBytes("ledgerMilestoneIndex"))
+	if err != nil {
+		if err == database.ErrKeyNotFound {
+			return 0, nil
+		}
+		return 0, errors.Wrap(NewDatabaseError(err), "failed to retrieve ledger milestone index")
+	}
+
+	return milestoneIndexFromBytes(entry.Value), nil
+}
+
+// GetLedgerMilestoneIndexWithoutLocking returns the milestone index of the current ledger state.
+// ReadLockLedger must be held while entering this function.
+func GetLedgerMilestoneIndexWithoutLocking() milestone_index.MilestoneIndex {
+	return ledgerMilestoneIndex
+}
+
 // GetAllBalances returns all balances for the current solid milestone.
 func GetAllBalances(abortSignal <-chan struct{}) (map[trinary.Hash]uint64, milestone_index.MilestoneIndex, error) {
 
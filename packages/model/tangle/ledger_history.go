@@ -0,0 +1,238 @@
+package tangle
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/database"
+	"github.com/iotaledger/hive.go/syncutils"
+	"github.com/iotaledger/hive.go/typeutils"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+)
+
+var (
+	snapshotBalancePrefix           = []byte("snapshotBalance")
+	snapshotLedgerMilestoneIndexKey = typeutils.StringToBytes("snapshotLedgerMilestoneIndex")
+
+	// serializes the rewrites of the snapshot ledger
+	snapshotLedgerLock syncutils.Mutex
+
+	ErrMilestoneBelowPruningIndex = errors.New("milestone index is below the pruning index")
+	ErrMilestoneAboveSolidIndex   = errors.New("milestone index is above the solid milestone index")
+	ErrSnapshotInfoMissing        = errors.New("no snapshot info found")
+)
+
+func databaseKeyForSnapshotAddressBalance(address trinary.Hash) []byte {
+	return append(snapshotBalancePrefix, trinary.MustTrytesToBytes(address)...)
+}
+
+func entryForSnapshotLedgerMilestoneIndex(index milestone_index.MilestoneIndex) database.Entry {
+	return database.Entry{
+		Key:   snapshotLedgerMilestoneIndexKey,
+		Value: bytesFromMilestoneIndex(index),
+	}
+}
+
+// readSnapshotLedgerMilestoneIndexWithoutLocking returns the milestone index of the stored snapshot ledger.
+// ReadLockLedger must be held while entering this function.
+func readSnapshotLedgerMilestoneIndexWithoutLocking() (milestone_index.MilestoneIndex, bool, error) {
+
+	entry, err := ledgerDatabase.Get(snapshotLedgerMilestoneIndexKey)
+	if err != nil {
+		if err == database.ErrKeyNotFound {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrap(NewDatabaseError(err), "failed to retrieve snapshot ledger milestone index")
+	}
+
+	return milestoneIndexFromBytes(entry.Value), true, nil
+}
+
+// HasSnapshotLedger returns whether a complete snapshot ledger is stored in the database.
+// The snapshot ledger is missing if the node was stopped while it was rewritten.
+func HasSnapshotLedger() (bool, error) {
+
+	ReadLockLedger()
+	defer ReadUnlockLedger()
+
+	_, hasSnapshotLedger, err := readSnapshotLedgerMilestoneIndexWithoutLocking()
+	return hasSnapshotLedger, err
+}
+
+// StoreSnapshotBalancesInDatabase replaces the stored snapshot ledger with the balances at the given snapshot index.
+// The snapshot ledger is used as an additional starting point to calculate historical balances.
+func StoreSnapshotBalancesInDatabase(balances map[trinary.Hash]uint64, index milestone_index.MilestoneIndex) error {
+
+	snapshotLedgerLock.Lock()
+	defer snapshotLedgerLock.Unlock()
+
+	// the milestone index is deleted first and written last, so that a partially written snapshot ledger is never used.
+	// the ledger lock is only needed for the switch of the index, the balances are not read without a valid index.
+	WriteLockLedger()
+	err := ledgerDatabase.Delete(snapshotLedgerMilestoneIndexKey)
+	WriteUnlockLedger()
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete snapshot ledger milestone index")
+	}
+
+	var deletions []database.Key
+
+	err = ledgerDatabase.StreamForEachPrefixKeyOnly(snapshotBalancePrefix, func(entry database.KeyOnlyEntry) error {
+		deletions = append(deletions, append(snapshotBalancePrefix, entry.Key...))
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete snapshot ledger state")
+	}
+
+	var entries []database.Entry
+	for address, balance := range balances {
+		if balance == 0 {
+			continue
+		}
+		entries = append(entries, database.Entry{
+			Key:   databaseKeyForSnapshotAddressBalance(address),
+			Value: bytesFromBalance(balance),
+		})
+	}
+
+	// Now batch delete/insert all entries
+	if err := ledgerDatabase.Apply([]database.Entry{}, deletions); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete snapshot ledger state")
+	}
+	if err := ledgerDatabase.Apply(entries, []database.Key{}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store snapshot ledger state")
+	}
+
+	WriteLockLedger()
+	err = ledgerDatabase.Set(entryForSnapshotLedgerMilestoneIndex(index))
+	WriteUnlockLedger()
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store snapshot ledger milestone index")
+	}
+
+	return nil
+}
+
+func getSnapshotBalanceForAddressWithoutLocking(address trinary.Hash) (uint64, error) {
+
+	entry, err := ledgerDatabase.Get(databaseKeyForSnapshotAddressBalance(address))
+	if err != nil {
+		if err == database.ErrKeyNotFound {
+			return 0, nil
+		}
+		return 0, errors.Wrap(NewDatabaseError(err), "failed to retrieve snapshot balance")
+	}
+
+	return balanceFromBytes(entry.Value), nil
+}
+
+func getLedgerDiffForAddressWithoutLocking(address trinary.Hash, index milestone_index.MilestoneIndex) (int64, error) {
+
+	entry, err := ledgerDatabase.Get(databaseKeyForLedgerDiffAndAddress(index, address))
+	if err != nil {
+		if err == database.ErrKeyNotFound {
+			return 0, nil
+		}
+		return 0, errors.Wrap(NewDatabaseError(err), "failed to retrieve ledger diff")
+	}
+
+	return diffFromBytes(entry.Value), nil
+}
+
+// GetBalancesAtMilestone returns the balances of the addresses at the given milestone index.
+// The ledger diffs are either rolled back from the solid ledger or applied to the snapshot ledger,
+// depending on which of both needs less milestones to be walked.
+func GetBalancesAtMilestone(addresses []trinary.Hash, targetIndex milestone_index.MilestoneIndex, abortSignal <-chan struct{}) (map[trinary.Hash]uint64, error) {
+
+	ReadLockLedger()
+	defer ReadUnlockLedger()
+
+	snapshotInfo := GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return nil, ErrSnapshotInfoMissing
+	}
+
+	if targetIndex < snapshotInfo.PruningIndex {
+		return nil, errors.Wrapf(ErrMilestoneBelowPruningIndex, "minimum: %d, actual: %d", snapshotInfo.PruningIndex, targetIndex)
+	}
+
+	if targetIndex > ledgerMilestoneIndex {
+		return nil, errors.Wrapf(ErrMilestoneAboveSolidIndex, "maximum: %d, actual: %d", ledgerMilestoneIndex, targetIndex)
+	}
+
+	snapshotLedgerIndex, hasSnapshotLedger, err := readSnapshotLedgerMilestoneIndexWithoutLocking()
+	if err != nil {
+		return nil, err
+	}
+
+	// the diffs between the snapshot ledger and the target index must not be pruned
+	useSnapshotLedger := hasSnapshotLedger && snapshotLedgerIndex >= snapshotInfo.PruningIndex && snapshotLedgerIndex <= ledgerMilestoneIndex
+	if useSnapshotLedger {
+		distanceSnapshot := snapshotLedgerIndex - targetIndex
+		if targetIndex > snapshotLedgerIndex {
+			distanceSnapshot = targetIndex - snapshotLedgerIndex
+		}
+		useSnapshotLedger = distanceSnapshot < ledgerMilestoneIndex-targetIndex
+	}
+
+	balances := make(map[trinary.Hash]uint64)
+
+	for _, address := range addresses {
+		select {
+		case <-abortSignal:
+			return nil, ErrOperationAborted
+		default:
+		}
+
+		var balance int64
+
+		if !useSnapshotLedger {
+			solidBalance, _, err := GetBalanceForAddressWithoutLocking(address)
+			if err != nil {
+				return nil, err
+			}
+			balance = int64(solidBalance)
+
+			for index := ledgerMilestoneIndex; index > targetIndex; index-- {
+				change, err := getLedgerDiffForAddressWithoutLocking(address, index)
+				if err != nil {
+					return nil, err
+				}
+				balance -= change
+			}
+		} else {
+			snapshotBalance, err := getSnapshotBalanceForAddressWithoutLocking(address)
+			if err != nil {
+				return nil, err
+			}
+			balance = int64(snapshotBalance)
+
+			for index := snapshotLedgerIndex + 1; index <= targetIndex; index++ {
+				change, err := getLedgerDiffForAddressWithoutLocking(address, index)
+				if err != nil {
+					return nil, err
+				}
+				balance += change
+			}
+
+			for index := snapshotLedgerIndex; index > targetIndex; index-- {
+				change, err := getLedgerDiffForAddressWithoutLocking(address, index)
+				if err != nil {
+					return nil, err
+				}
+				balance -= change
+			}
+		}
+
+		if balance < 0 {
+			return nil, errors.Errorf("historical balance of address %s at milestone %d is negative: %d", address, targetIndex, balance)
+		}
+
+		balances[address] = uint64(balance)
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,106 @@
+package tangle
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrDatabaseVersionTooNew    = errors.New("database version is newer than the supported version")
+	ErrDatabaseMigrationMissing = errors.New("no migration found for database version")
+
+	// migrations by the database version they migrate from
+	databaseMigrations = make(map[byte]*DatabaseMigration)
+)
+
+// DatabaseMigrationFunc converts the database of the previous version in place.
+// In a dry run the database must not be modified, the function should only log what it would change.
+type DatabaseMigrationFunc func(dryRun bool, logProgress func(format string, args ...interface{})) error
+
+// DatabaseMigration is a step which migrates the database from one version to the next one.
+type DatabaseMigration struct {
+	FromVersion byte
+	ToVersion   byte
+	Description string
+	Migrate     DatabaseMigrationFunc
+}
+
+// RegisterDatabaseMigration registers the step which migrates the database from the given version to the next one.
+func RegisterDatabaseMigration(fromVersion byte, description string, migrate DatabaseMigrationFunc) {
+	if _, exists := databaseMigrations[fromVersion]; exists {
+		panic(fmt.Sprintf("database migration from version %d registered twice", fromVersion))
+	}
+
+	databaseMigrations[fromVersion] = &DatabaseMigration{
+		FromVersion: fromVersion,
+		ToVersion:   fromVersion + 1,
+		Description: description,
+		Migrate:     migrate,
+	}
+}
+
+// GetPendingDatabaseMigrations returns the steps which are needed to migrate the database to the current version, in order.
+func GetPendingDatabaseMigrations() ([]*DatabaseMigration, error) {
+
+	version, err := GetDatabaseVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	if version > DbVersion {
+		return nil, errors.Wrapf(ErrDatabaseVersionTooNew, "%d > %d", version, DbVersion)
+	}
+
+	var migrations []*DatabaseMigration
+	for ; version < DbVersion; version++ {
+		migration, exists := databaseMigrations[version]
+		if !exists {
+			return nil, errors.Wrapf(ErrDatabaseMigrationMissing, "%d", version)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	return migrations, nil
+}
+
+// MigrateDatabase runs all pending migration steps and stores the new version of the database after every step.
+// The database is marked as corrupted while the migration is running, if a step fails it stays marked
+// and has to be restored from the backup point. In a dry run all steps are executed without modifying the database.
+func MigrateDatabase(dryRun bool, logProgress func(format string, args ...interface{})) error {
+
+	migrations, err := GetPendingDatabaseMigrations()
+	if err != nil {
+		return err
+	}
+
+	if len(migrations) == 0 {
+		return nil
+	}
+
+	if !dryRun {
+		MarkDatabaseCorrupted()
+	}
+
+	for _, migration := range migrations {
+		logProgress("Migrating database from version %d to %d (%s) ...", migration.FromVersion, migration.ToVersion, migration.Description)
+
+		if err := migration.Migrate(dryRun, logProgress); err != nil {
+			return errors.Wrapf(err, "migration from version %d to %d failed", migration.FromVersion, migration.ToVersion)
+		}
+
+		if !dryRun {
+			if err := storeDatabaseVersion(migration.ToVersion); err != nil {
+				return err
+			}
+		}
+
+		logProgress("Migrating database from version %d to %d ... done", migration.FromVersion, migration.ToVersion)
+	}
+
+	if !dryRun {
+		MarkDatabaseHealthy()
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package tangle
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/database"
+)
+
+func init() {
+	RegisterDatabaseMigration(3, "build the balance history index from the ledger diffs", migrateBalanceHistoryIndex)
+}
+
+// migrateBalanceHistoryIndex creates the balance history entries for all ledger diffs which were applied
+// before the index was introduced. The balances are calculated backwards, starting at the current ledger state.
+func migrateBalanceHistoryIndex(dryRun bool, logProgress func(format string, args ...interface{})) error {
+
+	WriteLockLedger()
+	defer WriteUnlockLedger()
+
+	snapshotInfo, err := readSnapshotInfoFromDatabase()
+	if err != nil {
+		return err
+	}
+	if snapshotInfo == nil {
+		// fresh database without any ledger diffs
+		return nil
+	}
+
+	// there are no ledger diffs for the snapshot milestone and the pruned milestones
+	firstIndex := snapshotInfo.SnapshotIndex
+	if snapshotInfo.PruningIndex > firstIndex {
+		firstIndex = snapshotInfo.PruningIndex
+	}
+
+	// the balances of all addresses touched by the already processed milestones, after the processed milestone was applied
+	balances := make(map[trinary.Hash]uint64)
+	var entryCount int
+
+	for msIndex := ledgerMilestoneIndex; msIndex > firstIndex; msIndex-- {
+
+		diff, err := GetLedgerDiffForMilestoneWithoutLocking(msIndex, nil)
+		if err != nil {
+			return err
+		}
+
+		var entries []database.Entry
+		for address, change := range diff {
+			balance, exists := balances[address]
+			if !exists {
+				if balance, _, err = GetBalanceForAddressWithoutLocking(address); err != nil {
+					return err
+				}
+			}
+
+			entries = append(entries, entryForBalanceHistory(address, msIndex, change, balance))
+
+			// the balance before the milestone was applied
+			balances[address] = uint64(int64(balance) - change)
+		}
+		entryCount += len(entries)
+
+		if !dryRun {
+			if err := ledgerDatabase.Apply(entries, []database.Key{}); err != nil {
+				return errors.Wrap(NewDatabaseError(err), "failed to store balance history")
+			}
+		}
+
+		if (ledgerMilestoneIndex-msIndex)%1000 == 0 {
+			logProgress("Building balance history index: milestone %d, %d milestones left", msIndex, msIndex-firstIndex-1)
+		}
+	}
+
+	logProgress("Building balance history index: %d entries for milestones %d-%d", entryCount, firstIndex+1, ledgerMilestoneIndex)
+	return nil
+}
@@ -83,6 +83,11 @@ func setSolidMilestoneIndex(index milestone_index.MilestoneIndex) {
 	updateNodeSynced(index, GetLatestMilestoneIndex())
 }
 
+// ResetSolidMilestoneIndex sets the solid milestone index to an older milestone, e.g. after a ledger rollback.
+func ResetSolidMilestoneIndex(index milestone_index.MilestoneIndex) {
+	setSolidMilestoneIndex(index)
+}
+
 func GetSolidMilestoneIndex() milestone_index.MilestoneIndex {
 	solidMilestoneLock.RLock()
 	defer solidMilestoneLock.RUnlock()
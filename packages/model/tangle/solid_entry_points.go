@@ -69,6 +69,18 @@ func SolidEntryPointsContain(transactionHash trinary.Hash) bool {
 	}
 }
 
+// SolidEntryPointsIndex returns the milestone index at which the solid entry point was referenced.
+func SolidEntryPointsIndex(transactionHash trinary.Hash) (milestone_index.MilestoneIndex, bool) {
+	ReadLockSolidEntryPoints()
+	defer ReadUnlockSolidEntryPoints()
+
+	if solidEntryPoints != nil {
+		return solidEntryPoints.Index(transactionHash)
+	} else {
+		panic(ErrSolidEntryPointsNotInitialized)
+	}
+}
+
 // WriteLockSolidEntryPoints must be held while entering this function
 func SolidEntryPointsAdd(transactionHash trinary.Hash, milestoneIndex milestone_index.MilestoneIndex) {
 	if solidEntryPoints != nil {
@@ -17,9 +17,13 @@ func ConfigureDatabases(directory string, badgerOpts *profile.BadgerOpts) {
 	configureSnapshotDatabase()
 	configureTransactionHashesForAddressDatabase()
 	configureFirstSeenTransactionsDatabase()
+	configureTrackedBundlesDatabase()
+	configureBundleTailsDatabase()
+	configureWatchlistDatabase()
 }
 
 func LoadInitialValuesFromDatabase() {
 	loadSnapshotInfo()
 	loadSolidEntryPoints()
+	loadWatchlist()
 }
@@ -0,0 +1,121 @@
+package tangle
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/database"
+
+	hornetDB "github.com/gohornet/hornet/packages/database"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+)
+
+var (
+	trackedBundlesDatabase database.Database
+
+	ErrParseTrackedBundleFailed = errors.New("Parsing of tracked bundle failed")
+)
+
+func configureTrackedBundlesDatabase() {
+	if db, err := database.Get(DBPrefixTrackedBundles, hornetDB.GetBadgerInstance()); err != nil {
+		panic(err)
+	} else {
+		trackedBundlesDatabase = db
+	}
+}
+
+// TrackedBundle is a bundle which is watched by the node until it is confirmed.
+type TrackedBundle struct {
+	// The tail transaction the bundle was registered with
+	TailHash   trinary.Hash
+	BundleHash trinary.Hash
+	// The tail transaction of the latest reattachment
+	LatestTailHash trinary.Hash
+	RegisteredAt   int64
+	Promotions     uint32
+	Reattachments  uint32
+	// The milestone which confirmed the bundle (0 if not yet confirmed)
+	ConfirmationIndex milestone_index.MilestoneIndex
+}
+
+func (t *TrackedBundle) IsConfirmed() bool {
+	return t.ConfirmationIndex != 0
+}
+
+func (t *TrackedBundle) GetBytes() []byte {
+	bytes := trinary.MustTrytesToBytes(t.BundleHash)[:49]
+	bytes = append(bytes, trinary.MustTrytesToBytes(t.LatestTailHash)[:49]...)
+
+	registeredAtBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(registeredAtBytes, uint64(t.RegisteredAt))
+	bytes = append(bytes, registeredAtBytes...)
+
+	countersBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint32(countersBytes[:4], t.Promotions)
+	binary.LittleEndian.PutUint32(countersBytes[4:], t.Reattachments)
+	bytes = append(bytes, countersBytes...)
+
+	return append(bytes, bytesFromMilestoneIndex(t.ConfirmationIndex)...)
+}
+
+func trackedBundleFromDatabaseEntry(key []byte, value []byte) (*TrackedBundle, error) {
+
+	if len(value) != 118 {
+		return nil, errors.Wrapf(ErrParseTrackedBundleFailed, "Invalid length %d != 118", len(value))
+	}
+
+	return &TrackedBundle{
+		TailHash:          transactionHashFromDatabaseKey(key),
+		BundleHash:        trinary.MustBytesToTrytes(value[:49], 81),
+		LatestTailHash:    trinary.MustBytesToTrytes(value[49:98], 81),
+		RegisteredAt:      int64(binary.LittleEndian.Uint64(value[98:106])),
+		Promotions:        binary.LittleEndian.Uint32(value[106:110]),
+		Reattachments:     binary.LittleEndian.Uint32(value[110:114]),
+		ConfirmationIndex: milestoneIndexFromBytes(value[114:118]),
+	}, nil
+}
+
+func StoreTrackedBundle(trackedBundle *TrackedBundle) error {
+
+	if err := trackedBundlesDatabase.Set(database.Entry{
+		Key:   databaseKeyForTransactionHash(trackedBundle.TailHash),
+		Value: trackedBundle.GetBytes(),
+	}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store tracked bundle")
+	}
+
+	return nil
+}
+
+func DeleteTrackedBundle(tailHash trinary.Hash) error {
+
+	if err := trackedBundlesDatabase.Delete(databaseKeyForTransactionHash(tailHash)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete tracked bundle")
+	}
+
+	return nil
+}
+
+func ReadTrackedBundles() ([]*TrackedBundle, error) {
+
+	var trackedBundles []*TrackedBundle
+
+	err := trackedBundlesDatabase.ForEach(func(entry database.Entry) (stop bool) {
+		trackedBundle, err := trackedBundleFromDatabaseEntry(entry.Key, entry.Value)
+		if err != nil {
+			// skip entries which can't be parsed
+			return false
+		}
+		trackedBundles = append(trackedBundles, trackedBundle)
+		return false
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(NewDatabaseError(err), "failed to read tracked bundles from database")
+	}
+
+	return trackedBundles, nil
+}
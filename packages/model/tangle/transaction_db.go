@@ -103,6 +103,10 @@ func readTransactionFromDatabase(transactionHash trinary.Hash) (*hornet.Transact
 		}
 	}
 
+	return transactionFromDatabaseEntry(transactionHash, entry)
+}
+
+func transactionFromDatabaseEntry(transactionHash trinary.Hash, entry database.Entry) (*hornet.Transaction, error) {
 	confirmationIndex := milestone_index.MilestoneIndex(binary.LittleEndian.Uint32(entry.Value[:4]))
 	solidificationTimestamp := int32(binary.LittleEndian.Uint32(entry.Value[4:8]))
 	rawBytes := entry.Value[8:]
@@ -115,6 +119,24 @@ func readTransactionFromDatabase(transactionHash trinary.Hash) (*hornet.Transact
 	}
 }
 
+// ForEachTransactionInDatabase iterates over all transactions in the database without using the cache.
+// The consumer can stop the iteration by returning true.
+func ForEachTransactionInDatabase(consumer func(tx *hornet.Transaction) bool) error {
+	var decodeErr error
+	err := transactionDatabase.ForEach(func(entry database.Entry) bool {
+		tx, err := transactionFromDatabaseEntry(transactionHashFromDatabaseKey(entry.Key), entry)
+		if err != nil {
+			decodeErr = err
+			return true
+		}
+		return consumer(tx)
+	})
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to iterate over transactions")
+	}
+	return decodeErr
+}
+
 func databaseContainsTransaction(transactionHash trinary.Hash) (bool, error) {
 	if contains, err := transactionDatabase.Contains(databaseKeyForTransactionHash(transactionHash)); err != nil {
 		return contains, errors.Wrap(NewDatabaseError(err), "failed to check if the transaction exists")
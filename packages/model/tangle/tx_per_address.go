@@ -78,3 +78,24 @@ func ReadTransactionHashesForAddressFromDatabase(address trinary.Hash, maxNumber
 		return transactionHashes, nil
 	}
 }
+
+// ForEachTransactionHashForAddressInDatabase iterates over all entries of the address index.
+// The consumer can stop the iteration by returning true.
+func ForEachTransactionHashForAddressInDatabase(consumer func(address trinary.Hash, txHash trinary.Hash) bool) error {
+	err := transactionsHashesForAddressDatabase.ForEachPrefixKeyOnly(database.KeyPrefix{}, func(entry database.KeyOnlyEntry) (stop bool) {
+		return consumer(trinary.MustBytesToTrytes(entry.Key[:49], 81), trinary.MustBytesToTrytes(entry.Key[49:], 81))
+	})
+
+	if err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to iterate over txs for addresses")
+	}
+	return nil
+}
+
+// DeleteAllTransactionHashesForAddressesInDatabase removes the whole address index, e.g. to rebuild it.
+func DeleteAllTransactionHashesForAddressesInDatabase() error {
+	if err := transactionsHashesForAddressDatabase.DeletePrefix(database.KeyPrefix{}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete txs for addresses")
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package tangle
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/database"
+	"github.com/iotaledger/hive.go/syncutils"
+	"github.com/iotaledger/hive.go/typeutils"
+
+	hornetDB "github.com/gohornet/hornet/packages/database"
+	"github.com/gohornet/hornet/packages/model/hornet"
+)
+
+const (
+	watchlistKeyTypeAddress byte = 0
+	watchlistKeyTypeTag     byte = 1
+)
+
+var (
+	watchlistDatabase database.Database
+
+	watchlistLock      syncutils.RWMutex
+	watchlistAddresses = make(map[trinary.Hash]struct{})
+	watchlistTags      = make(map[trinary.Trytes]struct{})
+)
+
+func configureWatchlistDatabase() {
+	if db, err := database.Get(DBPrefixWatchlist, hornetDB.GetBadgerInstance()); err != nil {
+		panic(err)
+	} else {
+		watchlistDatabase = db
+	}
+}
+
+func databaseKeyForWatchlistEntry(keyType byte, trytes trinary.Trytes) []byte {
+	return append([]byte{keyType}, typeutils.StringToBytes(trytes)...)
+}
+
+// NormalizeWatchlistTag pads the tag to the length of the tag field of a transaction.
+func NormalizeWatchlistTag(tag trinary.Trytes) trinary.Trytes {
+	return trinary.Pad(tag, consts.TagTrinarySize/3)
+}
+
+func loadWatchlist() {
+
+	watchlistLock.Lock()
+	defer watchlistLock.Unlock()
+
+	err := watchlistDatabase.ForEach(func(entry database.Entry) (stop bool) {
+		if len(entry.Key) < 2 {
+			return false
+		}
+
+		switch entry.Key[0] {
+		case watchlistKeyTypeAddress:
+			watchlistAddresses[string(entry.Key[1:])] = struct{}{}
+		case watchlistKeyTypeTag:
+			watchlistTags[string(entry.Key[1:])] = struct{}{}
+		}
+		return false
+	})
+
+	if err != nil {
+		panic(errors.Wrap(NewDatabaseError(err), "failed to load watchlist"))
+	}
+}
+
+func addWatchlistEntry(keyType byte, trytes trinary.Trytes, entries map[string]struct{}) error {
+
+	watchlistLock.Lock()
+	defer watchlistLock.Unlock()
+
+	if _, exists := entries[trytes]; exists {
+		return nil
+	}
+
+	if err := watchlistDatabase.Set(database.Entry{Key: databaseKeyForWatchlistEntry(keyType, trytes)}); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to store watchlist entry")
+	}
+	entries[trytes] = struct{}{}
+
+	return nil
+}
+
+func removeWatchlistEntry(keyType byte, trytes trinary.Trytes, entries map[string]struct{}) error {
+
+	watchlistLock.Lock()
+	defer watchlistLock.Unlock()
+
+	if err := watchlistDatabase.Delete(databaseKeyForWatchlistEntry(keyType, trytes)); err != nil {
+		return errors.Wrap(NewDatabaseError(err), "failed to delete watchlist entry")
+	}
+	delete(entries, trytes)
+
+	return nil
+}
+
+// AddWatchlistAddress adds the address (without checksum) to the watchlist.
+func AddWatchlistAddress(address trinary.Hash) error {
+	return addWatchlistEntry(watchlistKeyTypeAddress, address, watchlistAddresses)
+}
+
+// RemoveWatchlistAddress removes the address (without checksum) from the watchlist.
+func RemoveWatchlistAddress(address trinary.Hash) error {
+	return removeWatchlistEntry(watchlistKeyTypeAddress, address, watchlistAddresses)
+}
+
+// AddWatchlistTag adds the tag to the watchlist.
+func AddWatchlistTag(tag trinary.Trytes) error {
+	return addWatchlistEntry(watchlistKeyTypeTag, NormalizeWatchlistTag(tag), watchlistTags)
+}
+
+// RemoveWatchlistTag removes the tag from the watchlist.
+func RemoveWatchlistTag(tag trinary.Trytes) error {
+	return removeWatchlistEntry(watchlistKeyTypeTag, NormalizeWatchlistTag(tag), watchlistTags)
+}
+
+// GetWatchlist returns the watched addresses and tags.
+func GetWatchlist() ([]trinary.Hash, []trinary.Trytes) {
+
+	watchlistLock.RLock()
+	defer watchlistLock.RUnlock()
+
+	addresses := make([]trinary.Hash, 0, len(watchlistAddresses))
+	for address := range watchlistAddresses {
+		addresses = append(addresses, address)
+	}
+
+	tags := make([]trinary.Trytes, 0, len(watchlistTags))
+	for tag := range watchlistTags {
+		tags = append(tags, tag)
+	}
+
+	return addresses, tags
+}
+
+// IsWatchlistEmpty returns true if neither addresses nor tags are watched.
+func IsWatchlistEmpty() bool {
+
+	watchlistLock.RLock()
+	defer watchlistLock.RUnlock()
+
+	return len(watchlistAddresses) == 0 && len(watchlistTags) == 0
+}
+
+// IsWatchedTransaction returns true if the address or the tag of the transaction is on the watchlist.
+func IsWatchedTransaction(tx *hornet.Transaction) bool {
+
+	watchlistLock.RLock()
+	defer watchlistLock.RUnlock()
+
+	if _, exists := watchlistAddresses[tx.Tx.Address]; exists {
+		return true
+	}
+
+	_, exists := watchlistTags[tx.Tx.Tag]
+	return exists
+}
+
+// IsWatchedBundleBucket returns true if any transaction in the bundle bucket touches the watchlist.
+func IsWatchedBundleBucket(bundleBucket *BundleBucket) bool {
+	for _, tx := range bundleBucket.Transactions() {
+		if IsWatchedTransaction(tx) {
+			return true
+		}
+	}
+	return false
+}
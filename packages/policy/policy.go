@@ -0,0 +1,250 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/atomic"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/packages/model/hornet"
+)
+
+// Verdict is the result of evaluating a transaction against the policy chain.
+type Verdict byte
+
+const (
+	VerdictAccept Verdict = iota
+	VerdictDeprioritize
+	VerdictReject
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case VerdictDeprioritize:
+		return ActionDeprioritize
+	case VerdictReject:
+		return ActionReject
+	default:
+		return "accept"
+	}
+}
+
+const (
+	ActionReject       = "reject"
+	ActionDeprioritize = "deprioritize"
+
+	RuleTypeTag                 = "tag"
+	RuleTypeAddress             = "address"
+	RuleTypeValue               = "value"
+	RuleTypeAttachmentTimestamp = "attachmentTimestamp"
+	RuleTypeNeighborRate        = "neighborRate"
+
+	// SourceAPI is the source identifier used for transactions received through the API
+	SourceAPI = "api"
+)
+
+var (
+	ErrUnknownRuleType   = errors.New("unknown rule type")
+	ErrUnknownRuleAction = errors.New("unknown rule action")
+	ErrInvalidRule       = errors.New("invalid rule")
+
+	chainLock syncutils.RWMutex
+	chain     []*rule
+)
+
+// RuleConfig is the configuration of a single rule of the policy chain.
+type RuleConfig struct {
+	// Name of the rule, used for the drop counters
+	Name string `json:"name"`
+	// Type of the rule (tag, address, value, attachmentTimestamp, neighborRate)
+	Type string `json:"type"`
+	// Action if the rule matches (reject, deprioritize)
+	Action string `json:"action"`
+	// Tags (prefixes) or addresses the rule matches on
+	Values []string `json:"values"`
+	// Allowed value range of a transaction
+	MinValue int64 `json:"minValue"`
+	MaxValue int64 `json:"maxValue"`
+	// Allowed window of the attachment timestamp in seconds around the current time
+	MaxPastSeconds   int64 `json:"maxPastSeconds"`
+	MaxFutureSeconds int64 `json:"maxFutureSeconds"`
+	// Allowed transactions per second per neighbor
+	Limit uint64 `json:"limit"`
+}
+
+// RuleStatus contains the configuration and the amount of dropped transactions of a rule.
+type RuleStatus struct {
+	RuleConfig
+	// Transactions rejected by the rule, deprioritized transactions are not counted
+	Drops uint64 `json:"drops"`
+}
+
+type rule struct {
+	config  RuleConfig
+	verdict Verdict
+	values  map[string]struct{}
+	rates   *neighborRates
+	drops   atomic.Uint64
+}
+
+// SetRules validates the given rule configurations and replaces the current policy chain.
+// The drop counters of rules with the same name are kept.
+func SetRules(configs []RuleConfig) error {
+
+	newChain := make([]*rule, 0, len(configs))
+	for i, config := range configs {
+		r, err := newRule(config)
+		if err != nil {
+			return errors.Wrapf(err, "rule %d (%s)", i, config.Name)
+		}
+		newChain = append(newChain, r)
+	}
+
+	chainLock.Lock()
+	defer chainLock.Unlock()
+
+	for _, newR := range newChain {
+		for _, oldR := range chain {
+			if oldR.config.Name == newR.config.Name {
+				newR.drops.Store(oldR.drops.Load())
+				break
+			}
+		}
+	}
+	chain = newChain
+
+	return nil
+}
+
+// Evaluate checks the transaction against all rules of the chain.
+// The most restrictive verdict of all matching rules is returned.
+// Every matching reject rule counts the transaction as dropped.
+func Evaluate(tx *hornet.Transaction, source string) Verdict {
+	chainLock.RLock()
+	defer chainLock.RUnlock()
+
+	verdict := VerdictAccept
+	for _, r := range chain {
+		if !r.match(tx, source) {
+			continue
+		}
+
+		if r.verdict == VerdictReject {
+			r.drops.Inc()
+		}
+		if r.verdict > verdict {
+			verdict = r.verdict
+		}
+	}
+
+	return verdict
+}
+
+// GetRuleStatus returns the configuration and the drop counters of all rules.
+func GetRuleStatus() []*RuleStatus {
+	chainLock.RLock()
+	defer chainLock.RUnlock()
+
+	status := make([]*RuleStatus, 0, len(chain))
+	for _, r := range chain {
+		status = append(status, &RuleStatus{RuleConfig: r.config, Drops: r.drops.Load()})
+	}
+	return status
+}
+
+func newRule(config RuleConfig) (*rule, error) {
+
+	if config.Name == "" {
+		config.Name = config.Type
+	}
+
+	r := &rule{config: config}
+
+	switch strings.ToLower(config.Action) {
+	case ActionReject:
+		r.verdict = VerdictReject
+	case ActionDeprioritize:
+		r.verdict = VerdictDeprioritize
+	default:
+		return nil, errors.Wrapf(ErrUnknownRuleAction, "%s", config.Action)
+	}
+
+	switch config.Type {
+	case RuleTypeTag, RuleTypeAddress:
+		if len(config.Values) == 0 {
+			return nil, errors.Wrap(ErrInvalidRule, "no values given")
+		}
+		r.values = make(map[string]struct{})
+		for _, value := range config.Values {
+			if err := trinary.ValidTrytes(value); err != nil {
+				return nil, errors.Wrapf(ErrInvalidRule, "invalid trytes: %s", value)
+			}
+			if config.Type == RuleTypeAddress && len(value) >= 81 {
+				value = value[:81]
+			}
+			r.values[value] = struct{}{}
+		}
+
+	case RuleTypeValue:
+		if config.MinValue > config.MaxValue {
+			return nil, errors.Wrapf(ErrInvalidRule, "minValue %d is bigger than maxValue %d", config.MinValue, config.MaxValue)
+		}
+
+	case RuleTypeAttachmentTimestamp:
+		if config.MaxPastSeconds <= 0 && config.MaxFutureSeconds <= 0 {
+			return nil, errors.Wrap(ErrInvalidRule, "neither maxPastSeconds nor maxFutureSeconds given")
+		}
+
+	case RuleTypeNeighborRate:
+		if config.Limit == 0 {
+			return nil, errors.Wrap(ErrInvalidRule, "no limit given")
+		}
+		r.rates = newNeighborRates(config.Limit)
+
+	default:
+		return nil, errors.Wrapf(ErrUnknownRuleType, "%s", config.Type)
+	}
+
+	return r, nil
+}
+
+func (r *rule) match(tx *hornet.Transaction, source string) bool {
+	switch r.config.Type {
+	case RuleTypeTag:
+		for tag := range r.values {
+			if strings.HasPrefix(tx.Tx.Tag, tag) {
+				return true
+			}
+		}
+		return false
+
+	case RuleTypeAddress:
+		_, has := r.values[tx.Tx.Address]
+		return has
+
+	case RuleTypeValue:
+		return tx.Tx.Value < r.config.MinValue || tx.Tx.Value > r.config.MaxValue
+
+	case RuleTypeAttachmentTimestamp:
+		now := time.Now().Unix()
+		timestamp := tx.GetTimestamp()
+		if r.config.MaxPastSeconds > 0 && timestamp < now-r.config.MaxPastSeconds {
+			return true
+		}
+		if r.config.MaxFutureSeconds > 0 && timestamp > now+r.config.MaxFutureSeconds {
+			return true
+		}
+		return false
+
+	case RuleTypeNeighborRate:
+		return !r.rates.allow(source)
+	}
+
+	panic(fmt.Sprintf("unknown rule type: %s", r.config.Type))
+}
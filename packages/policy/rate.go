@@ -0,0 +1,38 @@
+package policy
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/syncutils"
+)
+
+// neighborRates counts the transactions per source within the current one second window.
+type neighborRates struct {
+	mu     syncutils.Mutex
+	limit  uint64
+	window int64
+	counts map[string]uint64
+}
+
+func newNeighborRates(limit uint64) *neighborRates {
+	return &neighborRates{
+		limit:  limit,
+		counts: make(map[string]uint64),
+	}
+}
+
+// allow returns whether the source is still below the limit and counts the transaction.
+func (nr *neighborRates) allow(source string) bool {
+	nr.mu.Lock()
+	defer nr.mu.Unlock()
+
+	now := time.Now().Unix()
+	if now != nr.window {
+		// new window => reset all counters
+		nr.window = now
+		nr.counts = make(map[string]uint64)
+	}
+
+	nr.counts[source]++
+	return nr.counts[source] <= nr.limit
+}
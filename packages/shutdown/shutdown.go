@@ -2,12 +2,15 @@ package shutdown
 
 const (
 	ShutdownPriorityFlushToDatabase = iota
+	ShutdownPriorityArchive
 	ShutdownPriorityPersisters
 	ShutdownPriorityRequestsProcessor
 	ShutdownPriorityMilestoneSolidifier
 	ShutdownPriorityMilestoneChecker
 	ShutdownPrioritySolidifierGossip
 	ShutdownPriorityReceiveTxWorker
+	ShutdownPriorityConflictDetector
+	ShutdownPriorityLedgerAudit
 	ShutdownPriorityReplyProcessor
 	ShutdownPriorityBroadcastQueue
 	ShutdownPriorityPacketProcessor
@@ -16,11 +19,14 @@ const (
 	ShutdownPriorityNeighborTCPServer
 	ShutdownPriorityNeighborReconnecter
 	ShutdownPriorityBadgerGarbageCollection
+	ShutdownPriorityDatabaseBackup
 	ShutdownPriorityLocalSnapshots
+	ShutdownPrioritySnapshotServer
 	ShutdownPriorityMetricsUpdater
 	ShutdownPrioritySPA
 	ShutdownPriorityAPI
 	ShutdownPriorityMetricsPublishers
 	ShutdownPrioritySpammer
+	ShutdownPriorityBundleTracker
 	ShutdownPriorityStatusReport
 )
@@ -0,0 +1,163 @@
+package toolset
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+
+	hornetDB "github.com/gohornet/hornet/packages/database"
+	"github.com/gohornet/hornet/packages/model/hornet"
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/packages/profile"
+)
+
+var (
+	ErrDatabaseCorrupted = errors.New("database is corrupted")
+	ErrDatabaseVersion   = errors.New("database version mismatch")
+	ErrDatabaseNotFound  = errors.New("database not found")
+	ErrDatabaseNotEmpty  = errors.New("database folder is not empty")
+)
+
+func configureDatabase(readOnly bool) error {
+	dbPath := parameter.NodeConfig.GetString("db.path")
+	if _, err := os.Stat(dbPath); err != nil {
+		if os.IsNotExist(err) {
+			return errors.Wrapf(ErrDatabaseNotFound, "%s", dbPath)
+		}
+		return err
+	}
+
+	tangle.InitTransactionCache(func(notifyStoredTx []*hornet.Transaction) {})
+	tangle.InitBundleCache()
+	tangle.InitApproversCache()
+	tangle.InitMilestoneCache()
+
+	hornetDB.SetReadOnly(readOnly)
+	tangle.ConfigureDatabases(dbPath, &profile.GetProfile().Badger)
+	return nil
+}
+
+// OpenDatabase configures the caches and the databases of the node, so that a tool can access them.
+// The node must not be running at the same time.
+func OpenDatabase() error {
+	if err := configureDatabase(false); err != nil {
+		return err
+	}
+
+	if tangle.IsDatabaseCorrupted() {
+		return ErrDatabaseCorrupted
+	}
+
+	if !tangle.IsCorrectDatabaseVersion() {
+		return ErrDatabaseVersion
+	}
+
+	// the database is marked as healthy again if the tool closes it
+	tangle.MarkDatabaseCorrupted()
+
+	tangle.LoadInitialValuesFromDatabase()
+	return nil
+}
+
+// OpenDatabaseReadOnly opens the database in read-only mode to inspect it.
+// A corrupted database can be opened as well, it is not marked or modified in any way.
+func OpenDatabaseReadOnly() error {
+	if err := configureDatabase(true); err != nil {
+		return err
+	}
+
+	if !tangle.IsCorrectDatabaseVersion() {
+		return ErrDatabaseVersion
+	}
+
+	tangle.LoadInitialValuesFromDatabase()
+	return nil
+}
+
+// OpenDatabaseForRepair opens a possibly corrupted database to repair it.
+// It returns whether the database was marked as corrupted before.
+func OpenDatabaseForRepair() (bool, error) {
+	if err := configureDatabase(false); err != nil {
+		return false, err
+	}
+
+	if !tangle.IsCorrectDatabaseVersion() {
+		return false, ErrDatabaseVersion
+	}
+
+	corrupted := tangle.IsDatabaseCorrupted()
+
+	// the database is only marked as healthy again if the repair succeeded
+	tangle.MarkDatabaseCorrupted()
+
+	tangle.LoadInitialValuesFromDatabase()
+	return corrupted, nil
+}
+
+// OpenDatabaseForMigration opens a database of an older version to migrate it, in read-only mode for dry runs.
+// The initial values are not loaded, because the migration may change their encoding.
+func OpenDatabaseForMigration(readOnly bool) error {
+	if err := configureDatabase(readOnly); err != nil {
+		return err
+	}
+
+	if tangle.IsDatabaseCorrupted() {
+		return ErrDatabaseCorrupted
+	}
+	return nil
+}
+
+// OpenEmptyDatabase creates a new database, e.g. to restore a backup into it.
+// The database is marked as corrupted until it is closed with CloseDatabaseAfterRepair.
+func OpenEmptyDatabase() error {
+	dbPath := parameter.NodeConfig.GetString("db.path")
+	files, err := ioutil.ReadDir(dbPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(files) > 0 {
+		return errors.Wrapf(ErrDatabaseNotEmpty, "%s", dbPath)
+	}
+
+	if err := os.MkdirAll(dbPath, 0700); err != nil {
+		return err
+	}
+
+	if err := configureDatabase(false); err != nil {
+		return err
+	}
+
+	tangle.MarkDatabaseCorrupted()
+	return nil
+}
+
+// CloseDatabase flushes the caches and syncs the database to disk.
+func CloseDatabase() {
+	closeDatabase(true)
+}
+
+// CloseDatabaseReadOnly closes a database which was opened with OpenDatabaseReadOnly without writing the caches.
+func CloseDatabaseReadOnly() {
+	hornetDB.GetBadgerInstance().Close()
+}
+
+// CloseDatabaseAfterRepair flushes the caches and clears the corrupted flag if the database is healthy
+// after a repair or a migration.
+func CloseDatabaseAfterRepair(healthy bool) {
+	closeDatabase(healthy)
+}
+
+func closeDatabase(healthy bool) {
+	tangle.FlushMilestoneCache()
+	tangle.FlushBundleCache()
+	tangle.FlushTransactionCache()
+	tangle.FlushApproversCache()
+
+	if healthy {
+		tangle.MarkDatabaseHealthy()
+	}
+
+	hornetDB.GetBadgerInstance().Close()
+}
@@ -0,0 +1,109 @@
+package toolset
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// Tool is a command which is executed instead of starting the node, e.g. to export or repair the database offline.
+type Tool struct {
+	// The name of the tool, subcommands are separated by spaces (e.g. "ledger export")
+	Name        string
+	Description string
+	Run         func(args []string) error
+}
+
+var (
+	tools = make(map[string]*Tool)
+
+	selectedTool *Tool
+	toolArgs     []string
+)
+
+// Register adds a tool which can be executed via "hornet [OPTIONS] <name> [ARGS]".
+func Register(name string, description string, run func(args []string) error) {
+	if _, exists := tools[name]; exists {
+		panic(fmt.Sprintf("tool %s registered twice", name))
+	}
+	tools[name] = &Tool{Name: name, Description: description, Run: run}
+}
+
+// ParseArgs removes the selected tool and its arguments from the command line,
+// so that the remaining flags (e.g. the config file) can be parsed as usual.
+// It has to be called before the command line flags are parsed.
+func ParseArgs() {
+	for i := 1; i < len(os.Args); i++ {
+		var longestMatch *Tool
+		for name, tool := range tools {
+			words := strings.Split(name, " ")
+			if len(os.Args)-i < len(words) || strings.Join(os.Args[i:i+len(words)], " ") != name {
+				continue
+			}
+			if longestMatch == nil || len(name) > len(longestMatch.Name) {
+				longestMatch = tool
+			}
+		}
+
+		if longestMatch == nil {
+			continue
+		}
+
+		selectedTool = longestMatch
+		toolArgs = os.Args[i+len(strings.Split(longestMatch.Name, " ")):]
+		os.Args = os.Args[:i]
+		return
+	}
+}
+
+// IsToolSelected returns whether a tool was given on the command line.
+func IsToolSelected() bool {
+	return selectedTool != nil
+}
+
+// HandleTools executes the selected tool and exits the program afterwards.
+// It does nothing if no tool was selected.
+func HandleTools() {
+	if selectedTool == nil {
+		return
+	}
+
+	if err := selectedTool.Run(toolArgs); err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", selectedTool.Name, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// NewFlagSet returns a flag set for the arguments of a tool, which prints the description of the tool as usage.
+func NewFlagSet(name string) *flag.FlagSet {
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.Usage = func() {
+		description := ""
+		if tool, exists := tools[name]; exists {
+			description = tool.Description
+		}
+		fmt.Fprintf(os.Stderr, "\nUsage of %s:\n  %s\n\n", name, description)
+		flagSet.PrintDefaults()
+	}
+	return flagSet
+}
+
+// PrintTools prints the names and descriptions of all registered tools.
+func PrintTools() {
+	var names []string
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-24s %s\n", name, tools[name].Description)
+	}
+}
@@ -0,0 +1,221 @@
+package archive
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/database"
+
+	"github.com/gohornet/hornet/packages/compressed"
+	"github.com/gohornet/hornet/packages/model/hornet"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+)
+
+const (
+	archivePrefixTransactions byte = 0
+	archivePrefixAddresses    byte = 1
+	archivePrefixBundles      byte = 2
+	archivePrefixMilestones   byte = 3
+	archivePrefixLedgerDiffs  byte = 4
+)
+
+var (
+	ErrArchiveDisabled = errors.New("archive plugin is disabled")
+
+	transactionsArchive database.Database
+	addressesArchive    database.Database
+	bundlesArchive      database.Database
+	milestonesArchive   database.Database
+	ledgerDiffsArchive  database.Database
+)
+
+func configureArchiveDatabases() {
+	for prefix, db := range map[byte]*database.Database{
+		archivePrefixTransactions: &transactionsArchive,
+		archivePrefixAddresses:    &addressesArchive,
+		archivePrefixBundles:      &bundlesArchive,
+		archivePrefixMilestones:   &milestonesArchive,
+		archivePrefixLedgerDiffs:  &ledgerDiffsArchive,
+	} {
+		archiveDB, err := database.Get(prefix, archiveInstance)
+		if err != nil {
+			log.Panic(err)
+		}
+		*db = archiveDB
+	}
+}
+
+// IsEnabled returns whether pruned data is moved to the archive.
+func IsEnabled() bool {
+	return enabled
+}
+
+// IsAPIFallbackEnabled returns whether the API should search the archive for pruned transactions.
+func IsAPIFallbackEnabled() bool {
+	return enabled && apiFallback
+}
+
+func hashBytes(hash trinary.Hash) []byte {
+	return trinary.MustTrytesToBytes(hash)[:49]
+}
+
+func milestoneIndexBytes(index milestone_index.MilestoneIndex) []byte {
+	bytes := make([]byte, 4)
+	// big endian to iterate the milestones in ascending order
+	binary.BigEndian.PutUint32(bytes, uint32(index))
+	return bytes
+}
+
+// ArchiveTransactions stores the transactions together with the address and bundle index entries in the archive.
+// It has to be called before the transactions are deleted from the database.
+func ArchiveTransactions(txs []*hornet.Transaction) error {
+
+	if !enabled {
+		return ErrArchiveDisabled
+	}
+
+	var entries []database.Entry
+	for _, tx := range txs {
+		txHashBytes := hashBytes(tx.GetHash())
+
+		// the value has the same layout as in the transactions database
+		value := make([]byte, 8, 8+len(tx.RawBytes))
+		if confirmed, confirmationIndex := tx.GetConfirmed(); confirmed {
+			binary.LittleEndian.PutUint32(value, uint32(confirmationIndex))
+		}
+		binary.LittleEndian.PutUint32(value[4:], uint32(tx.GetSolidificationTimestamp()))
+		value = append(value, tx.RawBytes...)
+
+		entries = append(entries, database.Entry{
+			Key:   txHashBytes,
+			Value: value,
+			Meta:  tx.GetMetadata(),
+		})
+	}
+	if err := transactionsArchive.Apply(entries, []database.Key{}); err != nil {
+		return errors.Wrap(err, "failed to archive transactions")
+	}
+
+	var addressEntries []database.Entry
+	var bundleEntries []database.Entry
+	for _, tx := range txs {
+		txHashBytes := hashBytes(tx.GetHash())
+		addressEntries = append(addressEntries, database.Entry{Key: append(hashBytes(tx.Tx.Address), txHashBytes...)})
+		bundleEntries = append(bundleEntries, database.Entry{Key: append(hashBytes(tx.Tx.Bundle), txHashBytes...)})
+	}
+	if err := addressesArchive.Apply(addressEntries, []database.Key{}); err != nil {
+		return errors.Wrap(err, "failed to archive addresses")
+	}
+	if err := bundlesArchive.Apply(bundleEntries, []database.Key{}); err != nil {
+		return errors.Wrap(err, "failed to archive bundles")
+	}
+
+	return nil
+}
+
+// ArchiveMilestone stores the tail hash and the ledger diff of the milestone in the archive.
+// It has to be called before the milestone is deleted from the database.
+func ArchiveMilestone(index milestone_index.MilestoneIndex, tailHash trinary.Hash, ledgerDiff map[trinary.Hash]int64) error {
+
+	if !enabled {
+		return ErrArchiveDisabled
+	}
+
+	if err := milestonesArchive.Set(database.Entry{
+		Key:   milestoneIndexBytes(index),
+		Value: hashBytes(tailHash),
+	}); err != nil {
+		return errors.Wrap(err, "failed to archive milestone")
+	}
+
+	var entries []database.Entry
+	for address, change := range ledgerDiff {
+		value := make([]byte, 8)
+		binary.LittleEndian.PutUint64(value, uint64(change))
+		entries = append(entries, database.Entry{
+			Key:   append(milestoneIndexBytes(index), hashBytes(address)...),
+			Value: value,
+		})
+	}
+	if err := ledgerDiffsArchive.Apply(entries, []database.Key{}); err != nil {
+		return errors.Wrap(err, "failed to archive ledger diff")
+	}
+
+	return nil
+}
+
+// GetTransaction returns the archived transaction, or nil if it is not in the archive.
+func GetTransaction(txHash trinary.Hash) (*hornet.Transaction, error) {
+
+	if !enabled {
+		return nil, ErrArchiveDisabled
+	}
+
+	entry, err := transactionsArchive.Get(hashBytes(txHash))
+	if err != nil {
+		if err == database.ErrKeyNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to retrieve archived transaction")
+	}
+
+	confirmationIndex := milestone_index.MilestoneIndex(binary.LittleEndian.Uint32(entry.Value[:4]))
+	solidificationTimestamp := int32(binary.LittleEndian.Uint32(entry.Value[4:8]))
+	rawBytes := entry.Value[8:]
+
+	tx, err := compressed.TransactionFromCompressedBytes(rawBytes, txHash)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress archived tx")
+	}
+
+	return hornet.NewTransactionFromDatabase(tx, rawBytes, solidificationTimestamp, confirmationIndex, entry.Meta), nil
+}
+
+func findTransactionHashes(db database.Database, hash trinary.Hash, maxNumber int) ([]trinary.Hash, error) {
+
+	if !enabled {
+		return nil, ErrArchiveDisabled
+	}
+
+	var txHashes []trinary.Hash
+	err := db.ForEachPrefixKeyOnly(hashBytes(hash), func(entry database.KeyOnlyEntry) (stop bool) {
+		txHashes = append(txHashes, trinary.MustBytesToTrytes(entry.Key, 81))
+		return len(txHashes) >= maxNumber
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read archived transaction hashes")
+	}
+
+	return txHashes, nil
+}
+
+// FindTransactionHashesForAddress returns the hashes of the archived transactions of the address.
+func FindTransactionHashesForAddress(address trinary.Hash, maxNumber int) ([]trinary.Hash, error) {
+	return findTransactionHashes(addressesArchive, address, maxNumber)
+}
+
+// FindTransactionHashesForBundle returns the hashes of the archived transactions of the bundle.
+func FindTransactionHashesForBundle(bundleHash trinary.Hash, maxNumber int) ([]trinary.Hash, error) {
+	return findTransactionHashes(bundlesArchive, bundleHash, maxNumber)
+}
+
+// GetMilestoneTailHash returns the tail hash of the archived milestone, or an empty hash if it is not in the archive.
+func GetMilestoneTailHash(index milestone_index.MilestoneIndex) (trinary.Hash, error) {
+
+	if !enabled {
+		return "", ErrArchiveDisabled
+	}
+
+	entry, err := milestonesArchive.Get(milestoneIndexBytes(index))
+	if err != nil {
+		if err == database.ErrKeyNotFound {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "failed to retrieve archived milestone")
+	}
+
+	return trinary.MustBytesToTrytes(entry.Value, 81), nil
+}
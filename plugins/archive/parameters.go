@@ -0,0 +1,13 @@
+package archive
+
+import (
+	"github.com/gohornet/hornet/packages/parameter"
+)
+
+func init() {
+	// "Path to the database which stores the pruned transactions and milestones"
+	parameter.NodeConfig.SetDefault("archive.path", "archivedb")
+
+	// "Whether getTrytes and findTransactions also search the archive for transactions which were pruned"
+	parameter.NodeConfig.SetDefault("archive.apiFallback", true)
+}
@@ -0,0 +1,56 @@
+package archive
+
+import (
+	"github.com/dgraph-io/badger/v2"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/database"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/node"
+
+	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/packages/shutdown"
+)
+
+var (
+	PLUGIN = node.NewPlugin("Archive", node.Disabled, configure, run)
+	log    *logger.Logger
+
+	enabled     bool
+	apiFallback bool
+
+	archiveInstance *badger.DB
+)
+
+func configure(plugin *node.Plugin) {
+	log = logger.NewLogger("Archive")
+
+	apiFallback = parameter.NodeConfig.GetBool("archive.apiFallback")
+
+	opts := badger.DefaultOptions(parameter.NodeConfig.GetString("archive.path"))
+	opts.Logger = nil
+
+	db, err := database.CreateDB(parameter.NodeConfig.GetString("archive.path"), opts)
+	if err != nil {
+		log.Panicf("Opening the archive failed: %v", err)
+	}
+	archiveInstance = db
+
+	configureArchiveDatabases()
+	enabled = true
+}
+
+func run(plugin *node.Plugin) {
+	daemon.BackgroundWorker("Archive", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting Archive ... done")
+		<-shutdownSignal
+		log.Info("Stopping Archive ...")
+
+		enabled = false
+		if err := archiveInstance.Close(); err != nil {
+			log.Errorf("Closing the archive failed: %v", err)
+		}
+
+		log.Info("Stopping Archive ... done")
+	}, shutdown.ShutdownPriorityArchive)
+}
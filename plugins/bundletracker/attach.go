@@ -0,0 +1,129 @@
+package bundletracker
+
+import (
+	"sort"
+	"time"
+
+	"github.com/iotaledger/iota.go/bundle"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/pow"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/batchhasher"
+
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/plugins/gossip"
+	"github.com/gohornet/hornet/plugins/tipselection"
+)
+
+var (
+	_, powFunc = pow.GetFastestProofOfWorkImpl()
+)
+
+// promote attaches a zero value transaction which references the given tail transaction.
+func promote(tailHash trinary.Hash) error {
+
+	// the second tip is selected by a walk starting at the reference
+	tips, _, err := tipselection.SelectTips(depth, &tailHash)
+	if err != nil {
+		return err
+	}
+
+	b := bundle.AddEntry(bundle.Bundle{}, bundle.BundleEntry{
+		Address:   consts.NullHashTrytes,
+		Tag:       promotionTag,
+		Timestamp: uint64(time.Now().Unix()),
+	})
+
+	b, err = bundle.Finalize(b)
+	if err != nil {
+		return err
+	}
+
+	if err := doPow(b, tips[0], tips[1]); err != nil {
+		return err
+	}
+
+	return broadcastBundle(b)
+}
+
+// reattach attaches the transactions of the given bundle on top of new tips and returns the new tail hash.
+func reattach(bndl *tangle.Bundle) (trinary.Hash, error) {
+
+	tips, _, err := tipselection.SelectTips(depth, nil)
+	if err != nil {
+		return "", err
+	}
+
+	txs := bndl.GetTransactions()
+	b := make(bundle.Bundle, len(txs))
+	for i, tx := range txs {
+		b[i] = *tx.Tx
+	}
+	sort.Slice(b, func(i, j int) bool { return b[i].CurrentIndex < b[j].CurrentIndex })
+
+	if err := doPow(b, tips[0], tips[1]); err != nil {
+		return "", err
+	}
+
+	if err := broadcastBundle(b); err != nil {
+		return "", err
+	}
+
+	return b[0].Hash, nil
+}
+
+func doPow(b bundle.Bundle, trunk trinary.Hash, branch trinary.Hash) error {
+	var prev trinary.Hash
+
+	for i := len(b) - 1; i >= 0; i-- {
+		switch {
+		case i == len(b)-1:
+			// Last tx in the bundle
+			b[i].TrunkTransaction = trunk
+			b[i].BranchTransaction = branch
+		default:
+			b[i].TrunkTransaction = prev
+			b[i].BranchTransaction = trunk
+		}
+
+		b[i].AttachmentTimestamp = time.Now().UnixNano() / int64(time.Millisecond)
+		b[i].AttachmentTimestampLowerBound = consts.LowerBoundAttachmentTimestamp
+		b[i].AttachmentTimestampUpperBound = consts.UpperBoundAttachmentTimestamp
+
+		trytes, err := transaction.TransactionToTrytes(&b[i])
+		if err != nil {
+			return err
+		}
+
+		nonce, err := powFunc(trytes, mwm)
+		if err != nil {
+			return err
+		}
+
+		b[i].Nonce = nonce
+
+		// set new transaction hash
+		trits, err := transaction.TransactionToTrits(&b[i])
+		if err != nil {
+			return err
+		}
+		b[i].Hash = trinary.MustTritsToTrytes(batchhasher.CURLP81.Hash(trits))
+		prev = b[i].Hash
+	}
+	return nil
+}
+
+func broadcastBundle(b bundle.Bundle) error {
+	txsTrytes := make([]trinary.Trytes, 0, len(b))
+	for i := range b {
+		trytes, err := transaction.TransactionToTrytes(&b[i])
+		if err != nil {
+			return err
+		}
+		txsTrytes = append(txsTrytes, trytes)
+	}
+
+	return gossip.BroadcastTransactionsFromAPI(txsTrytes)
+}
@@ -0,0 +1,19 @@
+package bundletracker
+
+import (
+	"github.com/iotaledger/hive.go/events"
+
+	"github.com/gohornet/hornet/packages/model/tangle"
+)
+
+var Events = pluginEvents{
+	TrackedBundleChanged: events.NewEvent(TrackedBundleCaller),
+}
+
+type pluginEvents struct {
+	TrackedBundleChanged *events.Event
+}
+
+func TrackedBundleCaller(handler interface{}, params ...interface{}) {
+	handler.(func(trackedBundle *tangle.TrackedBundle))(params[0].(*tangle.TrackedBundle))
+}
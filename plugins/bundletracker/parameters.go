@@ -0,0 +1,19 @@
+package bundletracker
+
+import (
+	"github.com/gohornet/hornet/packages/parameter"
+)
+
+func init() {
+	// "Interval in seconds to check the tracked bundles"
+	parameter.NodeConfig.SetDefault("bundleTracker.checkInterval", 60)
+
+	// "Depth of the random walker for promotions and reattachments"
+	parameter.NodeConfig.SetDefault("bundleTracker.depth", 3)
+
+	// "Maximum amount of reattachments of a tracked bundle"
+	parameter.NodeConfig.SetDefault("bundleTracker.maxReattachments", 10)
+
+	// "Tag of the promotion transactions"
+	parameter.NodeConfig.SetDefault("bundleTracker.promotionTag", "HORNET99PROMOTE")
+}
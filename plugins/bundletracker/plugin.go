@@ -0,0 +1,63 @@
+package bundletracker
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/hive.go/timeutil"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/packages/model/hornet"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/packages/shutdown"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+var (
+	PLUGIN = node.NewPlugin("BundleTracker", node.Disabled, configure, run)
+	log    *logger.Logger
+
+	enabled          bool
+	checkInterval    time.Duration
+	depth            uint
+	maxReattachments uint32
+	promotionTag     trinary.Trytes
+	mwm              int
+)
+
+func configure(plugin *node.Plugin) {
+	log = logger.NewLogger("BundleTracker")
+
+	checkInterval = time.Duration(parameter.NodeConfig.GetInt("bundleTracker.checkInterval")) * time.Second
+	depth = parameter.NodeConfig.GetUint("bundleTracker.depth")
+	maxReattachments = parameter.NodeConfig.GetUint32("bundleTracker.maxReattachments")
+	promotionTag = trinary.Pad(parameter.NodeConfig.GetString("bundleTracker.promotionTag"), consts.TagTrinarySize/3)[:consts.TagTrinarySize/3]
+	mwm = parameter.NodeConfig.GetInt("protocol.mwm")
+
+	if err := trinary.ValidTrytes(promotionTag); err != nil {
+		log.Panicf("Invalid promotion tag: %v", err)
+	}
+
+	loadTrackedBundles()
+	enabled = true
+}
+
+func run(plugin *node.Plugin) {
+
+	notifyConfirmedTx := events.NewClosure(func(tx *hornet.Transaction, msIndex milestone_index.MilestoneIndex, confTime int64) {
+		onTransactionConfirmed(tx, msIndex)
+	})
+
+	daemon.BackgroundWorker("BundleTracker", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting BundleTracker ... done")
+		tanglePlugin.Events.TransactionConfirmed.Attach(notifyConfirmedTx)
+		timeutil.Ticker(func() { checkTrackedBundles(shutdownSignal) }, checkInterval, shutdownSignal)
+		tanglePlugin.Events.TransactionConfirmed.Detach(notifyConfirmedTx)
+		log.Info("Stopping BundleTracker ... done")
+	}, shutdown.ShutdownPriorityBundleTracker)
+}
@@ -0,0 +1,301 @@
+package bundletracker
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/packages/model/hornet"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/parameter"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+var (
+	ErrBundleTrackerDisabled = errors.New("bundle tracker plugin is disabled")
+	ErrTransactionNotFound   = errors.New("transaction not found")
+	ErrTransactionNotTail    = errors.New("transaction is not a tail")
+	ErrBundleNotTracked      = errors.New("bundle is not tracked")
+
+	// tracked bundles by the tail hash they were registered with
+	trackedBundles = make(map[trinary.Hash]*tangle.TrackedBundle)
+	// registered tail hashes by bundle hash
+	trackedBundleHashes = make(map[trinary.Hash]trinary.Hash)
+	trackedBundlesLock  syncutils.RWMutex
+)
+
+func loadTrackedBundles() {
+	bundles, err := tangle.ReadTrackedBundles()
+	if err != nil {
+		log.Panic(err)
+	}
+
+	trackedBundlesLock.Lock()
+	defer trackedBundlesLock.Unlock()
+
+	for _, trackedBundle := range bundles {
+		trackedBundles[trackedBundle.TailHash] = trackedBundle
+		trackedBundleHashes[trackedBundle.BundleHash] = trackedBundle.TailHash
+	}
+
+	log.Infof("Loaded %d tracked bundles", len(bundles))
+}
+
+// TrackBundle registers the bundle of the given tail transaction.
+// The node promotes or reattaches the bundle until it is confirmed.
+func TrackBundle(tailHash trinary.Hash) (*tangle.TrackedBundle, error) {
+	if !enabled {
+		return nil, ErrBundleTrackerDisabled
+	}
+
+	tx, err := tangle.GetTransaction(tailHash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, errors.Wrapf(ErrTransactionNotFound, "%s", tailHash)
+	}
+	if !tx.IsTail() {
+		return nil, errors.Wrapf(ErrTransactionNotTail, "%s", tailHash)
+	}
+
+	trackedBundlesLock.Lock()
+
+	if registeredTail, exists := trackedBundleHashes[tx.Tx.Bundle]; exists {
+		trackedBundle := *trackedBundles[registeredTail]
+		trackedBundlesLock.Unlock()
+		return &trackedBundle, nil
+	}
+
+	trackedBundle := &tangle.TrackedBundle{
+		TailHash:       tailHash,
+		BundleHash:     tx.Tx.Bundle,
+		LatestTailHash: tailHash,
+		RegisteredAt:   time.Now().Unix(),
+	}
+	if confirmed, at := tx.GetConfirmed(); confirmed {
+		trackedBundle.ConfirmationIndex = at
+	}
+
+	if err := tangle.StoreTrackedBundle(trackedBundle); err != nil {
+		trackedBundlesLock.Unlock()
+		return nil, err
+	}
+	trackedBundles[tailHash] = trackedBundle
+	trackedBundleHashes[trackedBundle.BundleHash] = tailHash
+	result := *trackedBundle
+	trackedBundlesLock.Unlock()
+
+	Events.TrackedBundleChanged.Trigger(&result)
+	return &result, nil
+}
+
+// UntrackBundle removes the bundle registered with the given tail transaction from the tracker.
+func UntrackBundle(tailHash trinary.Hash) error {
+	if !enabled {
+		return ErrBundleTrackerDisabled
+	}
+
+	trackedBundlesLock.Lock()
+	defer trackedBundlesLock.Unlock()
+
+	trackedBundle, exists := trackedBundles[tailHash]
+	if !exists {
+		return errors.Wrapf(ErrBundleNotTracked, "%s", tailHash)
+	}
+
+	if err := tangle.DeleteTrackedBundle(tailHash); err != nil {
+		return err
+	}
+	delete(trackedBundles, tailHash)
+	delete(trackedBundleHashes, trackedBundle.BundleHash)
+
+	return nil
+}
+
+// GetTrackedBundle returns a copy of the bundle registered with the given tail transaction.
+func GetTrackedBundle(tailHash trinary.Hash) (*tangle.TrackedBundle, error) {
+	if !enabled {
+		return nil, ErrBundleTrackerDisabled
+	}
+
+	trackedBundlesLock.RLock()
+	defer trackedBundlesLock.RUnlock()
+
+	trackedBundle, exists := trackedBundles[tailHash]
+	if !exists {
+		return nil, errors.Wrapf(ErrBundleNotTracked, "%s", tailHash)
+	}
+
+	result := *trackedBundle
+	return &result, nil
+}
+
+// GetTrackedBundles returns copies of all tracked bundles.
+func GetTrackedBundles() ([]*tangle.TrackedBundle, error) {
+	if !enabled {
+		return nil, ErrBundleTrackerDisabled
+	}
+
+	trackedBundlesLock.RLock()
+	defer trackedBundlesLock.RUnlock()
+
+	result := make([]*tangle.TrackedBundle, 0, len(trackedBundles))
+	for _, trackedBundle := range trackedBundles {
+		trackedBundleCopy := *trackedBundle
+		result = append(result, &trackedBundleCopy)
+	}
+	return result, nil
+}
+
+// updateTrackedBundle applies the mutation to the tracked bundle, persists it and triggers the changed event.
+func updateTrackedBundle(tailHash trinary.Hash, mutate func(trackedBundle *tangle.TrackedBundle)) {
+	trackedBundlesLock.Lock()
+
+	trackedBundle, exists := trackedBundles[tailHash]
+	if !exists {
+		// the bundle was untracked in the meantime
+		trackedBundlesLock.Unlock()
+		return
+	}
+
+	mutate(trackedBundle)
+	if err := tangle.StoreTrackedBundle(trackedBundle); err != nil {
+		log.Panic(err)
+	}
+	result := *trackedBundle
+	trackedBundlesLock.Unlock()
+
+	Events.TrackedBundleChanged.Trigger(&result)
+}
+
+func onTransactionConfirmed(tx *hornet.Transaction, msIndex milestone_index.MilestoneIndex) {
+	if !tx.IsTail() {
+		return
+	}
+
+	trackedBundlesLock.RLock()
+	tailHash, exists := trackedBundleHashes[tx.Tx.Bundle]
+	trackedBundlesLock.RUnlock()
+	if !exists {
+		return
+	}
+
+	updateTrackedBundle(tailHash, func(trackedBundle *tangle.TrackedBundle) {
+		if trackedBundle.IsConfirmed() {
+			return
+		}
+		trackedBundle.ConfirmationIndex = msIndex
+		log.Infof("Tracked bundle %s was confirmed by milestone %d", trackedBundle.BundleHash, msIndex)
+	})
+}
+
+// checkTrackedBundles promotes or reattaches all unconfirmed tracked bundles.
+func checkTrackedBundles(shutdownSignal <-chan struct{}) {
+
+	if !tangle.IsNodeSynced() {
+		return
+	}
+
+	bundles, err := GetTrackedBundles()
+	if err != nil {
+		return
+	}
+
+	for _, trackedBundle := range bundles {
+		select {
+		case <-shutdownSignal:
+			return
+		default:
+		}
+
+		if trackedBundle.IsConfirmed() {
+			continue
+		}
+
+		checkTrackedBundle(trackedBundle)
+	}
+}
+
+func checkTrackedBundle(trackedBundle *tangle.TrackedBundle) {
+
+	bundleBucket, err := tangle.GetBundleBucket(trackedBundle.BundleHash)
+	if err != nil {
+		log.Panic(err)
+	}
+	if bundleBucket == nil {
+		log.Warnf("Tracked bundle %s not found", trackedBundle.BundleHash)
+		return
+	}
+
+	// the confirmation event may have been missed (e.g. during a restart)
+	if confirmedBundles := bundleBucket.GetConfirmed(); len(confirmedBundles) > 0 {
+		_, at := confirmedBundles[0].GetTail().GetConfirmed()
+		updateTrackedBundle(trackedBundle.TailHash, func(trackedBundle *tangle.TrackedBundle) {
+			trackedBundle.ConfirmationIndex = at
+		})
+		return
+	}
+
+	latestTail, err := tangle.GetTransaction(trackedBundle.LatestTailHash)
+	if err != nil {
+		log.Panic(err)
+	}
+	if latestTail == nil {
+		log.Warnf("Latest tail %s of tracked bundle %s not found", trackedBundle.LatestTailHash, trackedBundle.BundleHash)
+		return
+	}
+
+	// wait until the latest attachment is solid
+	if !latestTail.IsSolid() {
+		return
+	}
+
+	maxDepth := parameter.NodeConfig.GetInt("tipsel.maxDepth")
+	lowerAllowedSnapshotIndex := int(math.Max(float64(int(tangle.GetSolidMilestoneIndex())-maxDepth), float64(0)))
+
+	tangle.ReadLockLedger()
+	tanglePlugin.BelowDepthMemoizationCache.ResetIfNewerMilestone(tangle.GetSolidMilestoneIndex())
+	belowMaxDepth := tanglePlugin.IsBelowMaxDepth(latestTail, lowerAllowedSnapshotIndex)
+	tangle.ReadUnlockLedger()
+
+	if !belowMaxDepth {
+		if err := promote(trackedBundle.LatestTailHash); err != nil {
+			log.Warnf("Promotion of tracked bundle %s failed: %v", trackedBundle.BundleHash, err)
+			return
+		}
+
+		updateTrackedBundle(trackedBundle.TailHash, func(trackedBundle *tangle.TrackedBundle) {
+			trackedBundle.Promotions++
+		})
+		return
+	}
+
+	if trackedBundle.Reattachments >= maxReattachments {
+		return
+	}
+
+	bundle := bundleBucket.GetBundleOfTailTransaction(trackedBundle.LatestTailHash)
+	if bundle == nil || !bundle.IsValid() {
+		log.Warnf("Tracked bundle %s can't be reattached, bundle is invalid", trackedBundle.BundleHash)
+		return
+	}
+
+	newTailHash, err := reattach(bundle)
+	if err != nil {
+		log.Warnf("Reattachment of tracked bundle %s failed: %v", trackedBundle.BundleHash, err)
+		return
+	}
+
+	updateTrackedBundle(trackedBundle.TailHash, func(trackedBundle *tangle.TrackedBundle) {
+		trackedBundle.LatestTailHash = newTailHash
+		trackedBundle.Reattachments++
+	})
+	log.Infof("Reattached tracked bundle %s, new tail: %s", trackedBundle.BundleHash, newTailHash)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/iotaledger/hive.go/node"
 
 	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/packages/toolset"
 )
 
 var enabledPlugins []string
@@ -63,12 +64,17 @@ func printUsage() {
 			"HORNET\n\n"+
 			"  A lightweight modular IOTA node.\n\n"+
 			"Usage:\n\n"+
-			"  %s [OPTIONS]\n\n"+
+			"  %s [OPTIONS]\n"+
+			"  %s [OPTIONS] <tool> [TOOL OPTIONS]\n\n"+
 			"Options:\n",
 		filepath.Base(os.Args[0]),
+		filepath.Base(os.Args[0]),
 	)
 	flag.PrintDefaults()
 
+	fmt.Fprintf(os.Stderr, "\nTools:\n")
+	toolset.PrintTools()
+
 	fmt.Fprintf(os.Stderr, "\nThe following plugins are enabled: %s\n", getList(parameter.NodeConfig.GetStringSlice("node.enableplugins")))
 	fmt.Fprintf(os.Stderr, "\nThe following plugins are disabled: %s\n", getList(parameter.NodeConfig.GetStringSlice("node.disableplugins")))
 }
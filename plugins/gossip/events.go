@@ -27,6 +27,7 @@ var Events = pluginEvents{
 	SentTransaction:            events.NewEvent(hornet.TransactionCaller), // TODO
 	SentTransactionRequest:     events.NewEvent(hornet.TransactionCaller), // TODO
 	ReceivedTransaction:        events.NewEvent(hornet.TransactionCaller),
+	ReceivedUnrequestedTx:      events.NewEvent(unrequestedTransactionCaller),
 	ProtocolError:              events.NewEvent(hornet.TransactionCaller), // TODO
 
 	// generic events
@@ -49,6 +50,7 @@ type pluginEvents struct {
 	SentTransaction            *events.Event
 	SentTransactionRequest     *events.Event
 	ReceivedTransaction        *events.Event
+	ReceivedUnrequestedTx      *events.Event
 	ProtocolError              *events.Event
 
 	// generic events
@@ -93,6 +95,10 @@ func handshakeCaller(handler interface{}, params ...interface{}) {
 	handler.(func(*Neighbor, byte))(params[0].(*Neighbor), params[1].(byte))
 }
 
+func unrequestedTransactionCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*UnrequestedTransaction))(params[0].(*UnrequestedTransaction))
+}
+
 func dataCaller(handler interface{}, params ...interface{}) {
 	handler.(func(*protocol, []byte))(params[0].(*protocol), params[1].([]byte))
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/gohornet/hornet/packages/model/milestone_index"
 	"github.com/gohornet/hornet/packages/model/queue"
 	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/policy"
 	"github.com/gohornet/hornet/packages/profile"
 	"github.com/gohornet/hornet/packages/shutdown"
 	"github.com/gohornet/hornet/plugins/gossip/server"
@@ -30,6 +31,9 @@ import (
 
 const (
 	PACKET_PROCESSOR_WORKER_QUEUE_SIZE = 50000
+
+	// maximum time an API call waits for the transaction policy
+	apiTxPolicyTimeout = 10 * time.Second
 )
 
 var (
@@ -39,7 +43,9 @@ var (
 	RequestQueue  *queue.RequestQueue
 	IncomingCache *lru_cache.LRUCache
 
-	ErrTxExpired = errors.New("tx too old")
+	ErrTxExpired          = errors.New("tx too old")
+	ErrTxRejectedByPolicy = errors.New("tx rejected by the transaction policy")
+	ErrTxPolicyTimeout    = errors.New("tx was not checked by the transaction policy in time")
 )
 
 func configurePacketProcessor() {
@@ -242,10 +248,16 @@ func (p *PendingNeighborRequests) process() {
 
 	if !stale {
 		// Ignore stale transactions until they are requested
-		Events.ReceivedTransaction.Trigger(hornetTx)
-
-		if !requested && broadcast {
-			p.broadcast()
+		if requested {
+			// Requested transactions are needed for solidification and bypass the transaction policy
+			Events.ReceivedTransaction.Trigger(hornetTx)
+		} else {
+			// Unrequested transactions are only broadcasted if they pass the transaction policy of the tangle processor
+			unrequestedTx := &UnrequestedTransaction{Tx: hornetTx, Source: p.source()}
+			if broadcast {
+				unrequestedTx.broadcast = p.broadcast
+			}
+			Events.ReceivedUnrequestedTx.Trigger(unrequestedTx)
 		}
 	} else if len(p.requests) == 1 {
 		p.requests[0].p.Neighbor.Metrics.IncrInvalidTransactionsCount()
@@ -254,20 +266,58 @@ func (p *PendingNeighborRequests) process() {
 	p.notify()
 }
 
-func BroadcastTransactionFromAPI(txTrytes trinary.Trytes) error {
+// BroadcastTransactionsFromAPI checks all transactions of the batch before any of them is processed,
+// and waits for the transaction policy with a single timeout for the whole batch.
+// Deprioritized transactions are stored without being broadcasted and are not treated as an error.
+func BroadcastTransactionsFromAPI(txsTrytes []trinary.Trytes) error {
+
+	unrequestedTxs := make([]*UnrequestedTransaction, 0, len(txsTrytes))
+	for _, txTrytes := range txsTrytes {
+		unrequestedTx, err := newUnrequestedTransactionFromAPI(txTrytes)
+		if err != nil {
+			return err
+		}
+		unrequestedTxs = append(unrequestedTxs, unrequestedTx)
+	}
+
+	for _, unrequestedTx := range unrequestedTxs {
+		Events.ReceivedUnrequestedTx.Trigger(unrequestedTx)
+	}
+
+	var rejected bool
+	timeout := time.After(apiTxPolicyTimeout)
+	for _, unrequestedTx := range unrequestedTxs {
+		select {
+		case verdict := <-unrequestedTx.verdict:
+			if verdict == policy.VerdictReject {
+				rejected = true
+			}
+		case <-timeout:
+			return ErrTxPolicyTimeout
+		}
+	}
+
+	if rejected {
+		return ErrTxRejectedByPolicy
+	}
+
+	return nil
+}
+
+func newUnrequestedTransactionFromAPI(txTrytes trinary.Trytes) (*UnrequestedTransaction, error) {
 
 	if !guards.IsTransactionTrytes(txTrytes) {
-		return consts.ErrInvalidTransactionTrytes
+		return nil, consts.ErrInvalidTransactionTrytes
 	}
 
 	txTrits, err := trinary.TrytesToTrits(txTrytes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	tx, err := transaction.ParseTransaction(txTrits, true)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	hashTrits := batchhasher.CURLP81.Hash(txTrits)
@@ -277,29 +327,67 @@ func BroadcastTransactionFromAPI(txTrytes trinary.Trytes) error {
 		// Additional checks
 		if txTrits[consts.AddressTrinaryOffset+consts.AddressTrinarySize-1] != 0 {
 			// The last trit is always zero because of KERL/keccak
-			return consts.ErrInvalidAddress
+			return nil, consts.ErrInvalidAddress
 		}
 
 		if uint64(math.Abs(tx.Value)) > compressed.TOTAL_SUPPLY {
-			return consts.ErrInsufficientBalance
+			return nil, consts.ErrInsufficientBalance
 		}
 	}
 
 	if !transaction.HasValidNonce(tx, ownMWM) {
-		return consts.ErrInvalidTransactionHash
+		return nil, consts.ErrInvalidTransactionHash
 	}
 
 	txBytesTruncated := compressed.TruncateTx(trinary.TritsToBytes(txTrits))
 	hornetTx := hornet.NewTransactionFromAPI(tx, txBytesTruncated)
 
 	if timeValid, _ := checkTimestamp(hornetTx); !timeValid {
-		return ErrTxExpired
+		return nil, ErrTxExpired
 	}
 
-	Events.ReceivedTransaction.Trigger(hornetTx)
-	BroadcastTransaction(make(map[string]struct{}), txBytesTruncated, trinary.TritsToBytes(hashTrits))
+	return &UnrequestedTransaction{
+		Tx:     hornetTx,
+		Source: policy.SourceAPI,
+		broadcast: func() {
+			BroadcastTransaction(make(map[string]struct{}), txBytesTruncated, trinary.TritsToBytes(hashTrits))
+		},
+		verdict: make(chan policy.Verdict, 1),
+	}, nil
+}
 
-	return nil
+// UnrequestedTransaction is a new transaction which was not requested by the node.
+// It is checked by the transaction policy of the tangle processor and only broadcasted if it is accepted.
+type UnrequestedTransaction struct {
+	Tx *hornet.Transaction
+	// Source is the identity of the neighbor which sent the transaction first, or policy.SourceAPI
+	Source string
+	// broadcast sends the transaction to the neighbors, nil if the transaction should not be broadcasted
+	broadcast func()
+	// verdict receives the result of the transaction policy if the sender waits for it
+	verdict chan policy.Verdict
+}
+
+// SetVerdict reports the result of the transaction policy to the sender and broadcasts accepted transactions.
+func (t *UnrequestedTransaction) SetVerdict(verdict policy.Verdict) {
+	if verdict == policy.VerdictAccept && t.broadcast != nil {
+		t.broadcast()
+	}
+
+	if t.verdict != nil {
+		t.verdict <- verdict
+	}
+}
+
+// source returns the identity of the neighbor which sent the transaction first
+func (p *PendingNeighborRequests) source() string {
+	p.requestsLock.RLock()
+	defer p.requestsLock.RUnlock()
+
+	if len(p.requests) == 0 {
+		return ""
+	}
+	return p.requests[0].p.Neighbor.Identity
 }
 
 func (p *PendingNeighborRequests) notify() {
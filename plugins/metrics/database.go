@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"sync"
+
+	hornetDB "github.com/gohornet/hornet/packages/database"
+)
+
+var (
+	databaseMetricsLock sync.RWMutex
+	databaseMetrics     *DatabaseMetrics
+)
+
+// DatabaseMetrics contains the size of the database on the disk and the result of the last value log garbage collection.
+type DatabaseMetrics struct {
+	LSMSize  int64                             `json:"lsm_size"`
+	VlogSize int64                             `json:"vlog_size"`
+	LastGC   *hornetDB.GarbageCollectionResult `json:"last_gc"`
+}
+
+// measures the size of the database
+func measureDatabase() {
+	lsmSize, vlogSize, err := hornetDB.Size()
+	if err != nil {
+		return
+	}
+
+	dbMetrics := &DatabaseMetrics{
+		LSMSize:  lsmSize,
+		VlogSize: vlogSize,
+		LastGC:   hornetDB.GetLastGarbageCollectionResult(),
+	}
+
+	databaseMetricsLock.Lock()
+	databaseMetrics = dbMetrics
+	databaseMetricsLock.Unlock()
+
+	// trigger events for outside listeners
+	Events.DatabaseMetricsUpdated.Trigger(dbMetrics)
+}
+
+// GetDatabaseMetrics returns the last measured database metrics, or nil if they were not measured yet.
+func GetDatabaseMetrics() *DatabaseMetrics {
+	databaseMetricsLock.RLock()
+	defer databaseMetricsLock.RUnlock()
+
+	return databaseMetrics
+}
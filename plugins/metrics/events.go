@@ -11,13 +11,19 @@ type TPSMetrics struct {
 }
 
 var Events = pluginEvents{
-	TPSMetricsUpdated: events.NewEvent(TPSMetricsCaller),
+	TPSMetricsUpdated:      events.NewEvent(TPSMetricsCaller),
+	DatabaseMetricsUpdated: events.NewEvent(DatabaseMetricsCaller),
 }
 
 type pluginEvents struct {
-	TPSMetricsUpdated *events.Event
+	TPSMetricsUpdated      *events.Event
+	DatabaseMetricsUpdated *events.Event
 }
 
 func TPSMetricsCaller(handler interface{}, params ...interface{}) {
 	handler.(func(*TPSMetrics))(params[0].(*TPSMetrics))
 }
+
+func DatabaseMetricsCaller(handler interface{}, params ...interface{}) {
+	handler.(func(*DatabaseMetrics))(params[0].(*DatabaseMetrics))
+}
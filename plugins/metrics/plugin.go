@@ -21,4 +21,9 @@ func run(plugin *node.Plugin) {
 	daemon.BackgroundWorker("Metrics TPS Updater", func(shutdownSignal <-chan struct{}) {
 		timeutil.Ticker(measureTPS, 1*time.Second, shutdownSignal)
 	}, shutdown.ShutdownPriorityMetricsUpdater)
+
+	// create a background worker that measures the size of the database
+	daemon.BackgroundWorker("Metrics Database Updater", func(shutdownSignal <-chan struct{}) {
+		timeutil.Ticker(measureDatabase, 10*time.Second, shutdownSignal)
+	}, shutdown.ShutdownPriorityMetricsUpdater)
 }
@@ -2,6 +2,7 @@ package mqtt
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/iotaledger/iota.go/transaction"
@@ -10,6 +11,7 @@ import (
 	"github.com/gohornet/hornet/packages/model/hornet"
 	"github.com/gohornet/hornet/packages/model/milestone_index"
 	"github.com/gohornet/hornet/packages/model/tangle"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
 )
 
 var (
@@ -49,6 +51,27 @@ func onNewLatestMilestone(bundle *tangle.Bundle) {
 	}
 }
 
+func onTrackedBundleChanged(trackedBundle *tangle.TrackedBundle) {
+	err := publishTrackedBundle(trackedBundle)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+func onConflictDetected(conflict *tanglePlugin.Conflict) {
+	err := publishConflict(conflict)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+func onLedgerInvariantViolated(reason string) {
+	err := publishLedgerAlert(reason)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
 func onNewSolidMilestone(bundle *tangle.Bundle) {
 	err := publishLMSI(bundle.GetMilestoneIndex())
 	if err != nil {
@@ -137,3 +160,38 @@ func publishTx(tx *hornet.Transaction) error {
 		tx.Tx.Tag,               // Tag
 		time.Now().UTC().Format(time.RFC3339)))
 }
+
+// Publish the status of a bundle tracked by the node
+func publishTrackedBundle(trackedBundle *tangle.TrackedBundle) error {
+
+	return mqttBroker.Send(topicTrackedBundle, fmt.Sprintf(`{"tailHash":"%v","bundle":"%v","latestTailHash":"%v","promotions":%d,"reattachments":%d,"confirmed":%t,"msIndex":%d,"timestamp":"%s"}`,
+		trackedBundle.TailHash,          // Tail transaction hash the bundle was registered with
+		trackedBundle.BundleHash,        // Bundle hash
+		trackedBundle.LatestTailHash,    // Tail transaction hash of the latest reattachment
+		trackedBundle.Promotions,        // Amount of promotions
+		trackedBundle.Reattachments,     // Amount of reattachments
+		trackedBundle.IsConfirmed(),     // Whether the bundle is confirmed
+		trackedBundle.ConfirmationIndex, // Index of the milestone that confirmed the bundle
+		time.Now().UTC().Format(time.RFC3339)))
+}
+
+// Publish bundles with different bundle hashes which spend from the same address
+func publishConflict(conflict *tanglePlugin.Conflict) error {
+
+	var spends []string
+	for _, spend := range conflict.Spends {
+		spends = append(spends, fmt.Sprintf(`{"bundle":"%v","value":%d}`, spend.BundleHash, spend.Value))
+	}
+
+	return mqttBroker.Send(topicConflict, fmt.Sprintf(`{"address":"%v","spends":[%s],"timestamp":"%s"}`,
+		conflict.Address,          // Address which is spent by multiple bundles
+		strings.Join(spends, ","), // Conflicting bundles and the amount spent from the address
+		time.Now().UTC().Format(time.RFC3339)))
+}
+
+// Publish a violation of the ledger invariants, balances are not served anymore until an operator acts
+func publishLedgerAlert(reason string) error {
+	return mqttBroker.Send(topicLedgerAlert, fmt.Sprintf(`{"reason":%q,"timestamp":"%s"}`,
+		reason, // Description of the violated invariants
+		time.Now().UTC().Format(time.RFC3339)))
+}
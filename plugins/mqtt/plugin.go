@@ -11,6 +11,7 @@ import (
 	"github.com/gohornet/hornet/packages/model/milestone_index"
 	tanglePackage "github.com/gohornet/hornet/packages/model/tangle"
 	"github.com/gohornet/hornet/packages/shutdown"
+	"github.com/gohornet/hornet/plugins/bundletracker"
 	"github.com/gohornet/hornet/plugins/tangle"
 )
 
@@ -39,6 +40,18 @@ var (
 	newSolidMilestoneWorkerQueueSize = 100
 	newSolidMilestoneWorkerPool      *workerpool.WorkerPool
 
+	trackedBundleWorkerCount     = 1
+	trackedBundleWorkerQueueSize = 100
+	trackedBundleWorkerPool      *workerpool.WorkerPool
+
+	conflictWorkerCount     = 1
+	conflictWorkerQueueSize = 100
+	conflictWorkerPool      *workerpool.WorkerPool
+
+	ledgerAlertWorkerCount     = 1
+	ledgerAlertWorkerQueueSize = 100
+	ledgerAlertWorkerPool      *workerpool.WorkerPool
+
 	wasSyncBefore = false
 
 	mqttBroker *Broker
@@ -68,6 +81,21 @@ func configure(plugin *node.Plugin) {
 		task.Return(nil)
 	}, workerpool.WorkerCount(newSolidMilestoneWorkerCount), workerpool.QueueSize(newSolidMilestoneWorkerQueueSize))
 
+	trackedBundleWorkerPool = workerpool.New(func(task workerpool.Task) {
+		onTrackedBundleChanged(task.Param(0).(*tanglePackage.TrackedBundle))
+		task.Return(nil)
+	}, workerpool.WorkerCount(trackedBundleWorkerCount), workerpool.QueueSize(trackedBundleWorkerQueueSize))
+
+	conflictWorkerPool = workerpool.New(func(task workerpool.Task) {
+		onConflictDetected(task.Param(0).(*tangle.Conflict))
+		task.Return(nil)
+	}, workerpool.WorkerCount(conflictWorkerCount), workerpool.QueueSize(conflictWorkerQueueSize))
+
+	ledgerAlertWorkerPool = workerpool.New(func(task workerpool.Task) {
+		onLedgerInvariantViolated(task.Param(0).(string))
+		task.Return(nil)
+	}, workerpool.WorkerCount(ledgerAlertWorkerCount), workerpool.QueueSize(ledgerAlertWorkerQueueSize))
+
 	var err error
 	mqttBroker, err = NewBroker()
 	if err != nil {
@@ -118,6 +146,23 @@ func run(plugin *node.Plugin) {
 		newSolidMilestoneWorkerPool.TrySubmit(bundle)
 	})
 
+	notifyTrackedBundleChanged := events.NewClosure(func(trackedBundle *tanglePackage.TrackedBundle) {
+		trackedBundleWorkerPool.TrySubmit(trackedBundle)
+	})
+
+	notifyConflictDetected := events.NewClosure(func(conflict *tangle.Conflict) {
+		if !wasSyncBefore {
+			return
+		}
+
+		conflictWorkerPool.TrySubmit(conflict)
+	})
+
+	// the alert is triggered while the ledger is write locked, so it is published by the worker pool
+	notifyLedgerInvariantViolated := events.NewClosure(func(reason string) {
+		ledgerAlertWorkerPool.TrySubmit(reason)
+	})
+
 	daemon.BackgroundWorker("MQTT Broker", func(shutdownSignal <-chan struct{}) {
 		go func() {
 			if err := startBroker(plugin); err != nil {
@@ -191,6 +236,36 @@ func run(plugin *node.Plugin) {
 		newSolidMilestoneWorkerPool.StopAndWait()
 		log.Info("Stopping MQTT[NewSolidMilestoneWorker] ... done")
 	}, shutdown.ShutdownPriorityMetricsPublishers)
+
+	daemon.BackgroundWorker("MQTT[TrackedBundleWorker]", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting MQTT[TrackedBundleWorker] ... done")
+		bundletracker.Events.TrackedBundleChanged.Attach(notifyTrackedBundleChanged)
+		trackedBundleWorkerPool.Start()
+		<-shutdownSignal
+		bundletracker.Events.TrackedBundleChanged.Detach(notifyTrackedBundleChanged)
+		trackedBundleWorkerPool.StopAndWait()
+		log.Info("Stopping MQTT[TrackedBundleWorker] ... done")
+	}, shutdown.ShutdownPriorityMetricsPublishers)
+
+	daemon.BackgroundWorker("MQTT[ConflictWorker]", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting MQTT[ConflictWorker] ... done")
+		tangle.Events.ConflictDetected.Attach(notifyConflictDetected)
+		conflictWorkerPool.Start()
+		<-shutdownSignal
+		tangle.Events.ConflictDetected.Detach(notifyConflictDetected)
+		conflictWorkerPool.StopAndWait()
+		log.Info("Stopping MQTT[ConflictWorker] ... done")
+	}, shutdown.ShutdownPriorityMetricsPublishers)
+
+	daemon.BackgroundWorker("MQTT[LedgerAlertWorker]", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting MQTT[LedgerAlertWorker] ... done")
+		tangle.Events.LedgerInvariantViolated.Attach(notifyLedgerInvariantViolated)
+		ledgerAlertWorkerPool.Start()
+		<-shutdownSignal
+		tangle.Events.LedgerInvariantViolated.Detach(notifyLedgerInvariantViolated)
+		ledgerAlertWorkerPool.StopAndWait()
+		log.Info("Stopping MQTT[LedgerAlertWorker] ... done")
+	}, shutdown.ShutdownPriorityMetricsPublishers)
 }
 
 // Start the mqtt broker.
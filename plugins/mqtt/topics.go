@@ -2,12 +2,15 @@ package mqtt
 
 // Topic names
 const (
-	topicLMI      = "lmi"
-	topicLMSI     = "lmsi"
-	topicLMHS     = "lmhs"
-	topicSN       = "sn"
-	topicTxTrytes = "tx_trytes"
-	topicTX       = "tx"
+	topicLMI           = "lmi"
+	topicLMSI          = "lmsi"
+	topicLMHS          = "lmhs"
+	topicSN            = "sn"
+	topicTxTrytes      = "tx_trytes"
+	topicTX            = "tx"
+	topicTrackedBundle = "tracked_bundle"
+	topicConflict      = "conflict"
+	topicLedgerAlert   = "ledger_alert"
 	//topicPrefixAddress = "addr/"
 )
 
@@ -109,6 +109,11 @@ func loadSnapshotFromTextfiles(filePathLedger string, filePathSpent []string, sn
 		return errors.Wrapf(ErrSnapshotImportFailed, "ledgerEntries: %s", err)
 	}
 
+	err = tangle.StoreSnapshotBalancesInDatabase(ledgerState, snapshotIndex)
+	if err != nil {
+		return errors.Wrapf(ErrSnapshotImportFailed, "ledgerEntries: %s", err)
+	}
+
 	for _, spent := range filePathSpent {
 		if err := loadSpentAddresses(spent); err != nil {
 			return errors.Wrapf(ErrSnapshotImportFailed, "loadSpentAddresses: %v", err)
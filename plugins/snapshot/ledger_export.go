@@ -0,0 +1,221 @@
+package snapshot
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/packages/compressed"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/toolset"
+)
+
+const (
+	LedgerExportFormatCSV    = "csv"
+	LedgerExportFormatNDJSON = "ndjson"
+)
+
+var (
+	ErrUnknownLedgerExportFormat = errors.New("unknown ledger export format")
+	ErrLedgerSupplyMismatch      = errors.New("exported ledger does not match the total supply")
+)
+
+func init() {
+	toolset.Register("ledger export", "exports the ledger state at the solid milestone to a CSV or NDJSON file", runLedgerExportTool)
+}
+
+// LedgerExportResult contains the information of the header and the footer of a ledger export.
+type LedgerExportResult struct {
+	MilestoneIndex milestone_index.MilestoneIndex
+	MilestoneHash  trinary.Hash
+	AddressCount   int
+	Supply         uint64
+	// sha256 over all exported "address,balance\n" records in the order of the export
+	Checksum string
+}
+
+type ledgerExportHeader struct {
+	Type           string `json:"type"`
+	MilestoneIndex uint32 `json:"milestoneIndex"`
+	MilestoneHash  string `json:"milestoneHash"`
+}
+
+type ledgerExportBalance struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`
+	Balance uint64 `json:"balance"`
+}
+
+type ledgerExportFooter struct {
+	Type         string `json:"type"`
+	AddressCount int    `json:"addressCount"`
+	Supply       uint64 `json:"supply"`
+	Checksum     string `json:"checksum"`
+}
+
+// ExportLedger streams the ledger state at the solid milestone to the writer without loading it into memory.
+// The balances are read from a snapshot of the database, so the ledger is not locked while the export is written.
+func ExportLedger(writer io.Writer, format string, abortSignal <-chan struct{}) (*LedgerExportResult, error) {
+
+	if format != LedgerExportFormatCSV && format != LedgerExportFormatNDJSON {
+		return nil, errors.Wrapf(ErrUnknownLedgerExportFormat, "%s", format)
+	}
+
+	ledgerSnapshot := tangle.NewLedgerSnapshot()
+	defer ledgerSnapshot.Discard()
+
+	result := &LedgerExportResult{
+		MilestoneIndex: ledgerSnapshot.MilestoneIndex,
+		MilestoneHash:  consts.NullHashTrytes,
+	}
+
+	if ms, _ := tangle.GetMilestone(result.MilestoneIndex); ms != nil {
+		result.MilestoneHash = ms.GetMilestoneHash()
+	} else if snapshotInfo := tangle.GetSnapshotInfo(); snapshotInfo != nil && snapshotInfo.SnapshotIndex == result.MilestoneIndex {
+		result.MilestoneHash = snapshotInfo.Hash
+	}
+
+	// the supply is checked before anything is written, so that the caller can still report the error
+	var supply uint64
+	if err := ledgerSnapshot.ForEachBalance(func(address trinary.Hash, balance uint64) bool {
+		supply += balance
+		return false
+	}); err != nil {
+		return result, err
+	}
+	if supply != compressed.TOTAL_SUPPLY {
+		return result, errors.Wrapf(ErrLedgerSupplyMismatch, "%d != %d", supply, compressed.TOTAL_SUPPLY)
+	}
+
+	if err := writeLedgerExport(writer, format, result, ledgerSnapshot.ForEachBalance, abortSignal); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// writeLedgerExport writes the balances of the given iterator in the ledger export format and fills
+// the address count, the supply and the checksum of the result.
+func writeLedgerExport(writer io.Writer, format string, result *LedgerExportResult, forEachBalance func(consumer func(address trinary.Hash, balance uint64) bool) error, abortSignal <-chan struct{}) error {
+
+	buf := bufio.NewWriter(writer)
+	jsonEncoder := json.NewEncoder(buf)
+	checksum := sha256.New()
+
+	var err error
+	switch format {
+	case LedgerExportFormatCSV:
+		_, err = fmt.Fprintf(buf, "# milestoneIndex=%d milestoneHash=%s\naddress,balance\n", result.MilestoneIndex, result.MilestoneHash)
+	case LedgerExportFormatNDJSON:
+		err = jsonEncoder.Encode(&ledgerExportHeader{Type: "header", MilestoneIndex: uint32(result.MilestoneIndex), MilestoneHash: result.MilestoneHash})
+	}
+	if err != nil {
+		return err
+	}
+
+	var writeErr error
+	err = forEachBalance(func(address trinary.Hash, balance uint64) bool {
+		select {
+		case <-abortSignal:
+			writeErr = tangle.ErrOperationAborted
+			return true
+		default:
+		}
+
+		record := address + "," + strconv.FormatUint(balance, 10) + "\n"
+		checksum.Write([]byte(record))
+		result.AddressCount++
+		result.Supply += balance
+
+		switch format {
+		case LedgerExportFormatCSV:
+			_, writeErr = buf.WriteString(record)
+		case LedgerExportFormatNDJSON:
+			writeErr = jsonEncoder.Encode(&ledgerExportBalance{Type: "balance", Address: address, Balance: balance})
+		}
+		return writeErr != nil
+	})
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+
+	result.Checksum = hex.EncodeToString(checksum.Sum(nil))
+
+	switch format {
+	case LedgerExportFormatCSV:
+		_, err = fmt.Fprintf(buf, "# addressCount=%d supply=%d checksum=%s\n", result.AddressCount, result.Supply, result.Checksum)
+	case LedgerExportFormatNDJSON:
+		err = jsonEncoder.Encode(&ledgerExportFooter{Type: "footer", AddressCount: result.AddressCount, Supply: result.Supply, Checksum: result.Checksum})
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+
+	if result.Supply != compressed.TOTAL_SUPPLY {
+		return errors.Wrapf(ErrLedgerSupplyMismatch, "%d != %d", result.Supply, compressed.TOTAL_SUPPLY)
+	}
+
+	return nil
+}
+
+func runLedgerExportTool(args []string) error {
+
+	flagSet := toolset.NewFlagSet("ledger export")
+	format := flagSet.String("format", LedgerExportFormatCSV, "format of the export (csv or ndjson)")
+	outputPath := flagSet.String("output", "", "path of the export file (default \"ledger_export.<format>\")")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if *outputPath == "" {
+		*outputPath = "ledger_export." + *format
+	}
+
+	if err := toolset.OpenDatabaseReadOnly(); err != nil {
+		return err
+	}
+	defer toolset.CloseDatabaseReadOnly()
+
+	outputPathTmp := *outputPath + "_tmp"
+
+	// Remove old temp file
+	os.Remove(outputPathTmp)
+
+	exportFile, err := os.OpenFile(outputPathTmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	result, err := ExportLedger(exportFile, *format, nil)
+	if closeErr := exportFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(outputPathTmp, *outputPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d addresses at milestone %d (%s) to %s, supply: %d, checksum: %s\n", result.AddressCount, result.MilestoneIndex, result.MilestoneHash, *outputPath, result.Supply, result.Checksum)
+	return nil
+}
@@ -1,9 +1,6 @@
 package snapshot
 
 import (
-	"bytes"
-	"compress/gzip"
-	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -25,10 +22,12 @@ import (
 )
 
 const (
-	SpentAddressesImportBatchSize            = 100000
-	SolidEntryPointCheckThresholdPast        = 50
-	SolidEntryPointCheckThresholdFuture      = 50
-	SupportedLocalSnapshotFileVersion   byte = 3
+	SpentAddressesImportBatchSize       = 100000
+	SolidEntryPointCheckThresholdPast   = 50
+	SolidEntryPointCheckThresholdFuture = 50
+	// the local snapshot file version which is written, older versions down to MinSupportedLocalSnapshotFileVersion can still be loaded
+	SupportedLocalSnapshotFileVersion    byte = LocalSnapshotFileVersionV4
+	MinSupportedLocalSnapshotFileVersion byte = LocalSnapshotFileVersionV3
 )
 
 var ErrUnsupportedLSFileVersion = errors.New("unsupported local snapshot file version")
@@ -144,7 +143,12 @@ func shouldTakeSnapshot(solidMilestoneIndex milestone_index.MilestoneIndex) bool
 		return false
 	}
 
-	return solidMilestoneIndex-(snapshotDepth+snapshotInterval) >= snapshotInfo.SnapshotIndex
+	if solidMilestoneIndex-(snapshotDepth+snapshotInterval) >= snapshotInfo.SnapshotIndex {
+		return true
+	}
+
+	// the wall-clock interval only triggers a snapshot if the target index changed since the last one
+	return snapshotIntervalTime > 0 && time.Since(lastLocalSnapshotTime) >= snapshotIntervalTime && solidMilestoneIndex-snapshotDepth > snapshotInfo.SnapshotIndex
 }
 
 func getSolidEntryPoints(targetIndex milestone_index.MilestoneIndex, abortSignal <-chan struct{}) (map[string]milestone_index.MilestoneIndex, error) {
@@ -250,6 +254,36 @@ func getLedgerStateAtMilestone(balances map[trinary.Hash]uint64, targetIndex mil
 	return balances, nil
 }
 
+// rebuildSnapshotLedger calculates the balances at the snapshot index from the current ledger and stores them as the snapshot ledger.
+func rebuildSnapshotLedger() {
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+
+	log.Infof("Rebuilding the snapshot ledger at milestone %d ...", snapshotInfo.SnapshotIndex)
+
+	tangle.ReadLockLedger()
+	balances, ledgerMilestone, err := tangle.GetAllBalancesWithoutLocking(nil)
+	if err != nil {
+		log.Panicf("Rebuilding the snapshot ledger failed! %v", err)
+	}
+
+	if ledgerMilestone < snapshotInfo.SnapshotIndex {
+		log.Panicf("Rebuilding the snapshot ledger failed! Ledger milestone %d is below the snapshot index %d", ledgerMilestone, snapshotInfo.SnapshotIndex)
+	}
+
+	balances, err = getLedgerStateAtMilestone(balances, snapshotInfo.SnapshotIndex, ledgerMilestone, nil)
+	tangle.ReadUnlockLedger()
+	if err != nil {
+		log.Panicf("Rebuilding the snapshot ledger failed! %v", err)
+	}
+
+	if err := tangle.StoreSnapshotBalancesInDatabase(balances, snapshotInfo.SnapshotIndex); err != nil {
+		log.Panicf("Rebuilding the snapshot ledger failed! %v", err)
+	}
+
+	log.Infof("Rebuilding the snapshot ledger at milestone %d ... done", snapshotInfo.SnapshotIndex)
+}
+
 func checkSnapshotLimits(targetIndex milestone_index.MilestoneIndex, snapshotInfo *tangle.SnapshotInfo) error {
 
 	solidMilestoneIndex := tangle.GetSolidMilestoneIndex()
@@ -271,29 +305,49 @@ func checkSnapshotLimits(targetIndex milestone_index.MilestoneIndex, snapshotInf
 
 func createSnapshotFile(filePath string, lsh *localSnapshotHeader, abortSignal <-chan struct{}) error {
 
-	var buf bytes.Buffer
-	if err := lsh.WriteToBuffer(&buf, abortSignal); err != nil {
+	filePathTmp := filePath + "_tmp"
+
+	// Remove old temp file
+	os.Remove(filePathTmp)
+
+	if err := writeLocalSnapshotFile(filePathTmp, lsh, localSnapshotCodec, abortSignal); err != nil {
 		return err
 	}
 
-	// write sha256 hash
-	sha256Hash := sha256.Sum256(buf.Bytes())
-	if err := binary.Write(&buf, binary.LittleEndian, sha256Hash); err != nil {
-		return err
+	return os.Rename(filePathTmp, filePath)
+}
+
+// writeLocalSnapshot writes a delta file on top of the local snapshot file if deltas are enabled and
+// the maximum amount of delta files isn't reached yet. Otherwise the full local snapshot is written
+// and the delta files of the previous one are removed. If a retention policy is configured, a copy of
+// every full local snapshot is kept with the milestone index in its name.
+func writeLocalSnapshot(filePath string, lsh *localSnapshotHeader, snapshotInfo *tangle.SnapshotInfo, abortSignal <-chan struct{}) error {
+
+	if localSnapshotDeltaMaxCount > 0 {
+		written, err := writeLocalSnapshotDelta(filePath, lsh, snapshotInfo, abortSignal)
+		if err != nil {
+			return err
+		}
+		if written {
+			return nil
+		}
 	}
 
-	exportFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE, 0660)
-	if err != nil {
+	if err := createSnapshotFile(filePath, lsh, abortSignal); err != nil {
 		return err
 	}
-	defer exportFile.Close()
-
-	gzipWriter := gzip.NewWriter(exportFile)
-	defer gzipWriter.Close()
 
-	if _, err = io.Copy(gzipWriter, &buf); err != nil {
+	if err := removeLocalSnapshotDeltaFiles(filePath); err != nil {
 		return err
 	}
+
+	if isLocalSnapshotRetentionEnabled() {
+		// a failed copy doesn't affect the written local snapshot
+		if err := keepLocalSnapshotCopy(filePath, lsh.msIndex); err != nil {
+			log.Warnf("Keeping a copy of the local snapshot failed: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -364,17 +418,13 @@ func createLocalSnapshotWithoutLocking(targetIndex milestone_index.MilestoneInde
 		cuckooFilterBytes:   tangle.SerializedSpentAddressesCuckooFilter(),
 	}
 
-	filePathTmp := filePath + "_tmp"
-
-	// Remove old temp file
-	os.Remove(filePathTmp)
-
-	if err := createSnapshotFile(filePathTmp, lsh, abortSignal); err != nil {
+	if err := writeLocalSnapshot(filePath, lsh, snapshotInfo, abortSignal); err != nil {
 		return err
 	}
+	lastLocalSnapshotTime = time.Now()
 
-	if err := os.Rename(filePathTmp, filePath); err != nil {
-		return err
+	if err := tangle.StoreSnapshotBalancesInDatabase(newBalances, targetIndex); err != nil {
+		log.Panicf("CreateLocalSnapshot: StoreSnapshotBalancesInDatabase failed! %v", err)
 	}
 
 	tangle.WriteLockSolidEntryPoints()
@@ -415,90 +465,77 @@ type localSnapshotHeader struct {
 	cuckooFilterBytes   []byte
 }
 
-func (ls *localSnapshotHeader) WriteToBuffer(buf io.Writer, abortSignal <-chan struct{}) error {
-	var err error
-
-	if err = binary.Write(buf, binary.LittleEndian, SupportedLocalSnapshotFileVersion); err != nil {
-		return err
-	}
+func (ls *localSnapshotHeader) writeHeader(buf io.Writer, codec byte) error {
 
-	msHashBytes, err := trinary.TrytesToBytes(ls.msHash)
-	if err != nil {
+	if err := binary.Write(buf, binary.LittleEndian, SupportedLocalSnapshotFileVersion); err != nil {
 		return err
 	}
 
-	if err = binary.Write(buf, binary.LittleEndian, msHashBytes[:49]); err != nil {
+	if err := binary.Write(buf, binary.LittleEndian, codec); err != nil {
 		return err
 	}
 
-	if err = binary.Write(buf, binary.LittleEndian, ls.msIndex); err != nil {
+	if err := writeLocalSnapshotMilestoneHashAndIndex(buf, ls.msHash, ls.msIndex); err != nil {
 		return err
 	}
 
-	if err = binary.Write(buf, binary.LittleEndian, ls.msTimestamp); err != nil {
-		return err
-	}
+	return binary.Write(buf, binary.LittleEndian, ls.msTimestamp)
+}
 
-	if err = binary.Write(buf, binary.LittleEndian, int32(len(ls.solidEntryPoints))); err != nil {
-		return err
-	}
+func (ls *localSnapshotHeader) sectionSequence() []byte {
+	return localSnapshotSectionSequence
+}
 
-	if err = binary.Write(buf, binary.LittleEndian, int32(len(ls.seenMilestones))); err != nil {
-		return err
-	}
+// writeSectionEntries writes the uncompressed entries of a section and returns their amount.
+func (ls *localSnapshotHeader) writeSectionEntries(buf io.Writer, sectionType byte, abortSignal <-chan struct{}) (int32, error) {
 
-	if err = binary.Write(buf, binary.LittleEndian, int32(len(ls.balances))); err != nil {
-		return err
-	}
+	switch sectionType {
+	case localSnapshotSectionSolidEntryPoints:
+		return int32(len(ls.solidEntryPoints)), writeMilestoneIndexEntries(buf, ls.solidEntryPoints, abortSignal)
 
-	if err = binary.Write(buf, binary.LittleEndian, ls.spentAddressesCount); err != nil {
-		return err
-	}
+	case localSnapshotSectionSeenMilestones:
+		return int32(len(ls.seenMilestones)), writeMilestoneIndexEntries(buf, ls.seenMilestones, abortSignal)
 
-	for hash, val := range ls.solidEntryPoints {
-		select {
-		case <-abortSignal:
-			return ErrSnapshotCreationWasAborted
-		default:
-		}
-
-		addrBytes, err := trinary.TrytesToBytes(hash)
-		if err != nil {
-			return err
-		}
+	case localSnapshotSectionBalances:
+		// ToDo: Don't convert to trinary at all
+		for hash, val := range ls.balances {
+			select {
+			case <-abortSignal:
+				return 0, ErrSnapshotCreationWasAborted
+			default:
+			}
 
-		if err = binary.Write(buf, binary.LittleEndian, addrBytes[:49]); err != nil {
-			return err
-		}
+			addrBytes, err := trinary.TrytesToBytes(hash)
+			if err != nil {
+				return 0, err
+			}
 
-		if err = binary.Write(buf, binary.LittleEndian, val); err != nil {
-			return err
-		}
-	}
+			if err = binary.Write(buf, binary.LittleEndian, addrBytes[:49]); err != nil {
+				return 0, err
+			}
 
-	for hash, val := range ls.seenMilestones {
-		select {
-		case <-abortSignal:
-			return ErrSnapshotCreationWasAborted
-		default:
+			if err = binary.Write(buf, binary.LittleEndian, val); err != nil {
+				return 0, err
+			}
 		}
+		return int32(len(ls.balances)), nil
 
-		addrBytes, err := trinary.TrytesToBytes(hash)
-		if err != nil {
-			return err
+	case localSnapshotSectionSpentAddresses:
+		if err := binary.Write(buf, binary.LittleEndian, int32(len(ls.cuckooFilterBytes))); err != nil {
+			return 0, err
 		}
-
-		if err = binary.Write(buf, binary.LittleEndian, addrBytes[:49]); err != nil {
-			return err
+		if err := binary.Write(buf, binary.LittleEndian, ls.cuckooFilterBytes); err != nil {
+			return 0, err
 		}
+		return ls.spentAddressesCount, nil
 
-		if err = binary.Write(buf, binary.LittleEndian, val); err != nil {
-			return err
-		}
+	default:
+		return 0, errors.Wrapf(ErrUnexpectedLSSection, "%d", sectionType)
 	}
+}
 
-	// ToDo: Don't convert to trinary at all
-	for hash, val := range ls.balances {
+func writeMilestoneIndexEntries(buf io.Writer, entries map[string]milestone_index.MilestoneIndex, abortSignal <-chan struct{}) error {
+	for hash, val := range entries {
 		select {
 		case <-abortSignal:
 			return ErrSnapshotCreationWasAborted
@@ -518,45 +555,21 @@ func (ls *localSnapshotHeader) WriteToBuffer(buf io.Writer, abortSignal <-chan s
 			return err
 		}
 	}
-
-	if err := binary.Write(buf, binary.LittleEndian, int32(len(ls.cuckooFilterBytes))); err != nil {
-		return err
-	}
-	if err := binary.Write(buf, binary.LittleEndian, ls.cuckooFilterBytes); err != nil {
-		return err
-	}
-
 	return nil
 }
 
+// LoadSnapshotFromFile imports the local snapshot file and its delta files into the database.
 func LoadSnapshotFromFile(filePath string) error {
 	log.Info("Loading snapshot file...")
 
-	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	// the files are verified first, so that a corrupted file doesn't leave a partially imported snapshot behind
+	lsh, deltaPaths, err := readLocalSnapshotChain(filePath, false)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	gzipReader, err := gzip.NewReader(file)
-	if err != nil {
-		return err
-	}
-	defer gzipReader.Close()
-
-	// check file version
-	var fileVersion byte
-	if err := binary.Read(gzipReader, binary.LittleEndian, &fileVersion); err != nil {
-		return err
-	}
-
-	if fileVersion != SupportedLocalSnapshotFileVersion {
-		return errors.Wrapf(ErrUnsupportedLSFileVersion, "local snapshot file version is %d but this HORNET version only supports %d", fileVersion, SupportedLocalSnapshotFileVersion)
-	}
-
-	hashBuf := make([]byte, 49)
-	if _, err := gzipReader.Read(hashBuf); err != nil {
-		return err
+	if len(deltaPaths) > 0 {
+		log.Infof("Applied %d local snapshot delta files, snapshot milestone: %d", len(deltaPaths), lsh.msIndex)
 	}
 
 	tangle.WriteLockSolidEntryPoints()
@@ -565,66 +578,17 @@ func LoadSnapshotFromFile(filePath string) error {
 	// Genesis transaction
 	tangle.SolidEntryPointsAdd(consts.NullHashTrytes, 0)
 
-	var msIndex int32
-	var msTimestamp int64
-	var solidEntryPointsCount, seenMilestonesCount, ledgerEntriesCount, spentAddrsCount int32
-
-	msHash, err := trinary.BytesToTrytes(hashBuf)
-	if err != nil {
-		return err
-	}
-
-	if err := binary.Read(gzipReader, binary.LittleEndian, &msIndex); err != nil {
-		return err
-	}
-
-	if err := binary.Read(gzipReader, binary.LittleEndian, &msTimestamp); err != nil {
-		return err
-	}
-
-	tangle.SetSnapshotMilestone(msHash[:81], milestone_index.MilestoneIndex(msIndex), milestone_index.MilestoneIndex(msIndex), msTimestamp)
-	tangle.SolidEntryPointsAdd(msHash[:81], milestone_index.MilestoneIndex(msIndex))
-
-	if err := binary.Read(gzipReader, binary.LittleEndian, &solidEntryPointsCount); err != nil {
-		return err
-	}
-
-	if err := binary.Read(gzipReader, binary.LittleEndian, &seenMilestonesCount); err != nil {
-		return err
-	}
-
-	if err := binary.Read(gzipReader, binary.LittleEndian, &ledgerEntriesCount); err != nil {
-		return err
-	}
-
-	if err := binary.Read(gzipReader, binary.LittleEndian, &spentAddrsCount); err != nil {
-		return err
-	}
+	tangle.SetSnapshotMilestone(lsh.msHash, lsh.msIndex, lsh.msIndex, lsh.msTimestamp)
+	tangle.SolidEntryPointsAdd(lsh.msHash, lsh.msIndex)
 
 	log.Info("Importing solid entry points")
 
-	for i := 0; i < int(solidEntryPointsCount); i++ {
+	for hash, index := range lsh.solidEntryPoints {
 		if daemon.IsStopped() {
 			return ErrSnapshotImportWasAborted
 		}
 
-		var val int32
-
-		if err := binary.Read(gzipReader, binary.LittleEndian, hashBuf); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "solidEntryPoints: %v", err)
-		}
-
-		if err := binary.Read(gzipReader, binary.LittleEndian, &val); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "solidEntryPoints: %v", err)
-		}
-
-		hash, err := trinary.BytesToTrytes(hashBuf)
-		if err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "solidEntryPoints: %v", err)
-		}
-		//ls.solidEntryPoints[hash[:81]] = val
-
-		tangle.SolidEntryPointsAdd(hash[:81], milestone_index.MilestoneIndex(val))
+		tangle.SolidEntryPointsAdd(hash, index)
 	}
 
 	tangle.StoreSolidEntryPoints()
@@ -632,74 +596,30 @@ func LoadSnapshotFromFile(filePath string) error {
 
 	log.Info("Importing seen milestones")
 
-	for i := 0; i < int(seenMilestonesCount); i++ {
+	for hash, index := range lsh.seenMilestones {
 		if daemon.IsStopped() {
 			return ErrSnapshotImportWasAborted
 		}
 
-		var val int32
-
-		if err := binary.Read(gzipReader, binary.LittleEndian, hashBuf); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "seenMilestones: %v", err)
-		}
-
-		if err := binary.Read(gzipReader, binary.LittleEndian, &val); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "seenMilestones: %v", err)
-		}
-
-		hash, err := trinary.BytesToTrytes(hashBuf)
-		if err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "seenMilestones: %v", err)
-		}
-
-		tangle.SetLatestSeenMilestoneIndexFromSnapshot(milestone_index.MilestoneIndex(val))
-		gossip.Request([]trinary.Hash{hash[:81]}, milestone_index.MilestoneIndex(val))
+		tangle.SetLatestSeenMilestoneIndexFromSnapshot(index)
+		gossip.Request([]trinary.Hash{hash}, index)
 	}
 
 	log.Info("Importing ledger state")
 
-	ledgerState := make(map[trinary.Hash]uint64)
-	for i := 0; i < int(ledgerEntriesCount); i++ {
-		if daemon.IsStopped() {
-			return ErrSnapshotImportWasAborted
-		}
-
-		var val uint64
-
-		if err := binary.Read(gzipReader, binary.LittleEndian, hashBuf); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "ledgerEntries: %v", err)
-		}
-
-		if err := binary.Read(gzipReader, binary.LittleEndian, &val); err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "ledgerEntries: %v", err)
-		}
-
-		hash, err := trinary.BytesToTrytes(hashBuf)
-		if err != nil {
-			return errors.Wrapf(ErrSnapshotImportFailed, "ledgerEntries: %v", err)
-		}
-		ledgerState[hash[:81]] = val
-	}
-
-	err = tangle.StoreBalancesInDatabase(ledgerState, milestone_index.MilestoneIndex(msIndex))
+	err = tangle.StoreBalancesInDatabase(lsh.balances, lsh.msIndex)
 	if err != nil {
 		return errors.Wrapf(ErrSnapshotImportFailed, "ledgerEntries: %v", err)
 	}
 
-	log.Infof("Deserializing spent addresses cuckoo filter containing %d addresses.", spentAddrsCount)
-
-	// read serialized cuckoo filter byte size
-	var cuckooFilterSize int32
-	if err := binary.Read(gzipReader, binary.LittleEndian, &cuckooFilterSize); err != nil {
-		return errors.Wrapf(ErrSnapshotImportFailed, "couldn't deserialize cuckoo filter size: %v", err)
+	err = tangle.StoreSnapshotBalancesInDatabase(lsh.balances, lsh.msIndex)
+	if err != nil {
+		return errors.Wrapf(ErrSnapshotImportFailed, "ledgerEntries: %v", err)
 	}
 
-	cuckooFilterData := make([]byte, cuckooFilterSize)
-	if err := binary.Read(gzipReader, binary.LittleEndian, &cuckooFilterData); err != nil {
-		return errors.Wrapf(ErrSnapshotImportFailed, "couldn't read serialized cuckoo filter bytes: %v", err)
-	}
+	log.Infof("Deserializing spent addresses cuckoo filter containing %d addresses.", lsh.spentAddressesCount)
 
-	cuckooFilter, err := cuckoo.Decode(cuckooFilterData)
+	cuckooFilter, err := cuckoo.Decode(lsh.cuckooFilterBytes)
 	if err != nil {
 		return errors.Wrapf(ErrSnapshotImportFailed, "couldn't reconstruct the cuckoo filter from the data within the snapshot file: %v", err)
 	}
@@ -0,0 +1,527 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/model/tangle"
+)
+
+const (
+	// the size of the parts in which the serialized spent addresses filter is compared to find the changes
+	spentAddressesPatchChunkSize = 4096
+	localSnapshotDeltaFileSuffix = ".delta_"
+)
+
+var (
+	ErrLSDeltaChainBroken     = errors.New("local snapshot delta doesn't build on the previous snapshot")
+	ErrLSDeltaNegativeBalance = errors.New("local snapshot delta creates a negative balance")
+)
+
+// localSnapshotDelta contains the changes between a local snapshot and a later one.
+type localSnapshotDelta struct {
+	baseMsHash  string
+	baseMsIndex milestone_index.MilestoneIndex
+	msHash      string
+	msIndex     milestone_index.MilestoneIndex
+	msTimestamp int64
+	// new solid entry points and the ones with a changed index
+	solidEntryPoints        map[string]milestone_index.MilestoneIndex
+	removedSolidEntryPoints map[string]milestone_index.MilestoneIndex
+	seenMilestones          map[string]milestone_index.MilestoneIndex
+	ledgerDiff              map[string]int64
+	spentAddressesCount     int32
+	cuckooFilterLength      int32
+	// the changed parts of the serialized spent addresses filter by their offset
+	cuckooFilterPatches map[int32][]byte
+}
+
+func localSnapshotDeltaFilePath(filePath string, msIndex milestone_index.MilestoneIndex) string {
+	return fmt.Sprintf("%s%s%d", filePath, localSnapshotDeltaFileSuffix, msIndex)
+}
+
+func getLocalSnapshotDeltaFilePaths(filePath string) ([]string, error) {
+	matches, err := filepath.Glob(filePath + localSnapshotDeltaFileSuffix + "*")
+	if err != nil {
+		return nil, err
+	}
+
+	var deltaPaths []string
+	for _, match := range matches {
+		// ignore unfinished delta files
+		if strings.HasSuffix(match, "_tmp") {
+			continue
+		}
+		deltaPaths = append(deltaPaths, match)
+	}
+	return deltaPaths, nil
+}
+
+// removeLocalSnapshotDeltaFiles removes all delta files of the local snapshot file.
+func removeLocalSnapshotDeltaFiles(filePath string) error {
+	deltaPaths, err := getLocalSnapshotDeltaFilePaths(filePath)
+	if err != nil {
+		return err
+	}
+
+	for _, deltaPath := range deltaPaths {
+		if err := os.Remove(deltaPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getLocalSnapshotDeltaChain returns the delta files which build on the given local snapshot and on each other,
+// in the order they have to be applied. Delta files of older local snapshots are ignored.
+func getLocalSnapshotDeltaChain(filePath string, header *localSnapshotFileHeader) ([]string, error) {
+
+	deltaPaths, err := getLocalSnapshotDeltaFilePaths(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	deltaPathsByBase := make(map[milestone_index.MilestoneIndex]string)
+	deltaHeaders := make(map[string]*localSnapshotFileHeader)
+	for _, deltaPath := range deltaPaths {
+		deltaHeader, err := readLocalSnapshotFileHeader(deltaPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid delta file %s", deltaPath)
+		}
+
+		if !deltaHeader.delta {
+			return nil, errors.Wrapf(ErrUnexpectedLSFileType, "%s is not a delta file", deltaPath)
+		}
+
+		deltaPathsByBase[deltaHeader.baseMsIndex] = deltaPath
+		deltaHeaders[deltaPath] = deltaHeader
+	}
+
+	var chain []string
+	msHash, msIndex := header.msHash, header.msIndex
+	for {
+		deltaPath, exists := deltaPathsByBase[msIndex]
+		if !exists || deltaHeaders[deltaPath].baseMsHash != msHash {
+			return chain, nil
+		}
+
+		chain = append(chain, deltaPath)
+		msHash, msIndex = deltaHeaders[deltaPath].msHash, deltaHeaders[deltaPath].msIndex
+	}
+}
+
+// readLocalSnapshotChain verifies and reads the local snapshot file and applies its delta files.
+// It returns the resulting local snapshot and the applied delta files.
+func readLocalSnapshotChain(filePath string, skipBalances bool) (*localSnapshotHeader, []string, error) {
+
+	if err := verifyLocalSnapshotFile(filePath); err != nil {
+		return nil, nil, err
+	}
+
+	header, lsh, err := readLocalSnapshot(filePath, skipBalances)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chain, err := getLocalSnapshotDeltaChain(filePath, header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, deltaPath := range chain {
+		if err := verifyLocalSnapshotFile(deltaPath); err != nil {
+			return nil, nil, errors.Wrapf(err, "delta file %s", deltaPath)
+		}
+
+		delta, err := readLocalSnapshotDelta(deltaPath)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "delta file %s", deltaPath)
+		}
+
+		if err := lsh.applyDelta(delta); err != nil {
+			return nil, nil, errors.Wrapf(err, "delta file %s", deltaPath)
+		}
+	}
+
+	return lsh, chain, nil
+}
+
+// newLocalSnapshotDelta calculates the changes from the base to the target local snapshot.
+// The ledger diff between both milestones has to be given, since the balances of the snapshots are not compared.
+func newLocalSnapshotDelta(base *localSnapshotHeader, target *localSnapshotHeader, ledgerDiff map[string]int64) *localSnapshotDelta {
+
+	delta := &localSnapshotDelta{
+		baseMsHash:              base.msHash,
+		baseMsIndex:             base.msIndex,
+		msHash:                  target.msHash,
+		msIndex:                 target.msIndex,
+		msTimestamp:             target.msTimestamp,
+		solidEntryPoints:        make(map[string]milestone_index.MilestoneIndex),
+		removedSolidEntryPoints: make(map[string]milestone_index.MilestoneIndex),
+		seenMilestones:          target.seenMilestones,
+		ledgerDiff:              ledgerDiff,
+		spentAddressesCount:     target.spentAddressesCount,
+		cuckooFilterLength:      int32(len(target.cuckooFilterBytes)),
+		cuckooFilterPatches:     make(map[int32][]byte),
+	}
+
+	for hash, index := range target.solidEntryPoints {
+		if baseIndex, exists := base.solidEntryPoints[hash]; !exists || baseIndex != index {
+			delta.solidEntryPoints[hash] = index
+		}
+	}
+
+	for hash, index := range base.solidEntryPoints {
+		if _, exists := target.solidEntryPoints[hash]; !exists {
+			delta.removedSolidEntryPoints[hash] = index
+		}
+	}
+
+	for offset := 0; offset < len(target.cuckooFilterBytes); offset += spentAddressesPatchChunkSize {
+		end := offset + spentAddressesPatchChunkSize
+		if end > len(target.cuckooFilterBytes) {
+			end = len(target.cuckooFilterBytes)
+		}
+
+		if end <= len(base.cuckooFilterBytes) && bytes.Equal(base.cuckooFilterBytes[offset:end], target.cuckooFilterBytes[offset:end]) {
+			continue
+		}
+
+		delta.cuckooFilterPatches[int32(offset)] = target.cuckooFilterBytes[offset:end]
+	}
+
+	return delta
+}
+
+// applyDelta changes the local snapshot to the milestone of the delta.
+// The ledger diff is only applied if the balances were read.
+func (ls *localSnapshotHeader) applyDelta(delta *localSnapshotDelta) error {
+
+	if delta.baseMsIndex != ls.msIndex || delta.baseMsHash != ls.msHash {
+		return errors.Wrapf(ErrLSDeltaChainBroken, "delta base: %d, snapshot: %d", delta.baseMsIndex, ls.msIndex)
+	}
+
+	ls.msHash = delta.msHash
+	ls.msIndex = delta.msIndex
+	ls.msTimestamp = delta.msTimestamp
+
+	for hash := range delta.removedSolidEntryPoints {
+		delete(ls.solidEntryPoints, hash)
+	}
+	for hash, index := range delta.solidEntryPoints {
+		ls.solidEntryPoints[hash] = index
+	}
+
+	ls.seenMilestones = delta.seenMilestones
+
+	if ls.balances != nil {
+		for address, change := range delta.ledgerDiff {
+			newBalance := int64(ls.balances[address]) + change
+
+			if newBalance < 0 {
+				return errors.Wrapf(ErrLSDeltaNegativeBalance, "address %s: current %d, diff %d", address, ls.balances[address], change)
+			} else if newBalance == 0 {
+				delete(ls.balances, address)
+			} else {
+				ls.balances[address] = uint64(newBalance)
+			}
+		}
+	}
+
+	cuckooFilterBytes := make([]byte, delta.cuckooFilterLength)
+	copy(cuckooFilterBytes, ls.cuckooFilterBytes)
+	for offset, patch := range delta.cuckooFilterPatches {
+		if int(offset)+len(patch) > len(cuckooFilterBytes) {
+			return errors.Wrapf(ErrLSFileCorrupted, "spent addresses patch at %d exceeds the filter size %d", offset, len(cuckooFilterBytes))
+		}
+		copy(cuckooFilterBytes[offset:], patch)
+	}
+	ls.cuckooFilterBytes = cuckooFilterBytes
+	ls.spentAddressesCount = delta.spentAddressesCount
+
+	return nil
+}
+
+func (d *localSnapshotDelta) writeHeader(buf io.Writer, codec byte) error {
+
+	if err := binary.Write(buf, binary.LittleEndian, localSnapshotDeltaFileMarker); err != nil {
+		return err
+	}
+
+	if err := binary.Write(buf, binary.LittleEndian, SupportedLocalSnapshotFileVersion); err != nil {
+		return err
+	}
+
+	if err := binary.Write(buf, binary.LittleEndian, codec); err != nil {
+		return err
+	}
+
+	if err := writeLocalSnapshotMilestoneHashAndIndex(buf, d.baseMsHash, d.baseMsIndex); err != nil {
+		return err
+	}
+
+	if err := writeLocalSnapshotMilestoneHashAndIndex(buf, d.msHash, d.msIndex); err != nil {
+		return err
+	}
+
+	return binary.Write(buf, binary.LittleEndian, d.msTimestamp)
+}
+
+func (d *localSnapshotDelta) sectionSequence() []byte {
+	return localSnapshotDeltaSectionSequence
+}
+
+func (d *localSnapshotDelta) writeSectionEntries(buf io.Writer, sectionType byte, abortSignal <-chan struct{}) (int32, error) {
+
+	switch sectionType {
+	case localSnapshotSectionSolidEntryPoints:
+		return int32(len(d.solidEntryPoints)), writeMilestoneIndexEntries(buf, d.solidEntryPoints, abortSignal)
+
+	case localSnapshotSectionRemovedSolidEntryPoints:
+		return int32(len(d.removedSolidEntryPoints)), writeMilestoneIndexEntries(buf, d.removedSolidEntryPoints, abortSignal)
+
+	case localSnapshotSectionSeenMilestones:
+		return int32(len(d.seenMilestones)), writeMilestoneIndexEntries(buf, d.seenMilestones, abortSignal)
+
+	case localSnapshotSectionLedgerDiff:
+		for address, change := range d.ledgerDiff {
+			select {
+			case <-abortSignal:
+				return 0, ErrSnapshotCreationWasAborted
+			default:
+			}
+
+			addrBytes, err := trinary.TrytesToBytes(address)
+			if err != nil {
+				return 0, err
+			}
+
+			if err = binary.Write(buf, binary.LittleEndian, addrBytes[:49]); err != nil {
+				return 0, err
+			}
+
+			if err = binary.Write(buf, binary.LittleEndian, change); err != nil {
+				return 0, err
+			}
+		}
+		return int32(len(d.ledgerDiff)), nil
+
+	case localSnapshotSectionSpentAddressesPatches:
+		if err := binary.Write(buf, binary.LittleEndian, d.spentAddressesCount); err != nil {
+			return 0, err
+		}
+		if err := binary.Write(buf, binary.LittleEndian, d.cuckooFilterLength); err != nil {
+			return 0, err
+		}
+
+		offsets := make([]int32, 0, len(d.cuckooFilterPatches))
+		for offset := range d.cuckooFilterPatches {
+			offsets = append(offsets, offset)
+		}
+		sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+		for _, offset := range offsets {
+			patch := d.cuckooFilterPatches[offset]
+			if err := binary.Write(buf, binary.LittleEndian, offset); err != nil {
+				return 0, err
+			}
+			if err := binary.Write(buf, binary.LittleEndian, int32(len(patch))); err != nil {
+				return 0, err
+			}
+			if err := binary.Write(buf, binary.LittleEndian, patch); err != nil {
+				return 0, err
+			}
+		}
+		return int32(len(offsets)), nil
+
+	default:
+		return 0, errors.Wrapf(ErrUnexpectedLSSection, "%d", sectionType)
+	}
+}
+
+// readLocalSnapshotDelta reads all sections of a delta file into memory.
+// The checksums are not verified, use verifyLocalSnapshotFile for that.
+func readLocalSnapshotDelta(filePath string) (*localSnapshotDelta, error) {
+
+	lsReader, err := openLocalSnapshotFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer lsReader.Close()
+
+	header := lsReader.header
+	if !header.delta {
+		return nil, errors.Wrapf(ErrUnexpectedLSFileType, "%s is not a delta file", filePath)
+	}
+
+	delta := &localSnapshotDelta{
+		baseMsHash:          header.baseMsHash,
+		baseMsIndex:         header.baseMsIndex,
+		msHash:              header.msHash,
+		msIndex:             header.msIndex,
+		msTimestamp:         header.msTimestamp,
+		ledgerDiff:          make(map[string]int64),
+		cuckooFilterPatches: make(map[int32][]byte),
+	}
+
+	for _, entries := range []struct {
+		sectionType byte
+		target      *map[string]milestone_index.MilestoneIndex
+	}{
+		{localSnapshotSectionSolidEntryPoints, &delta.solidEntryPoints},
+		{localSnapshotSectionRemovedSolidEntryPoints, &delta.removedSolidEntryPoints},
+		{localSnapshotSectionSeenMilestones, &delta.seenMilestones},
+	} {
+		sectionReader, count, err := lsReader.nextSection(entries.sectionType)
+		if err != nil {
+			return nil, errors.Wrapf(ErrLSFileCorrupted, "%s: %v", localSnapshotSectionName(entries.sectionType), err)
+		}
+		if *entries.target, err = readMilestoneIndexEntries(sectionReader, count); err != nil {
+			return nil, errors.Wrapf(ErrLSFileCorrupted, "%s: %v", localSnapshotSectionName(entries.sectionType), err)
+		}
+	}
+
+	sectionReader, count, err := lsReader.nextSection(localSnapshotSectionLedgerDiff)
+	if err != nil {
+		return nil, errors.Wrapf(ErrLSFileCorrupted, "ledgerDiff: %v", err)
+	}
+
+	hashBuf := make([]byte, 49)
+	for i := 0; i < int(count); i++ {
+		var change int64
+
+		if err := binary.Read(sectionReader, binary.LittleEndian, hashBuf); err != nil {
+			return nil, errors.Wrapf(ErrLSFileCorrupted, "ledgerDiff: %v", err)
+		}
+
+		if err := binary.Read(sectionReader, binary.LittleEndian, &change); err != nil {
+			return nil, errors.Wrapf(ErrLSFileCorrupted, "ledgerDiff: %v", err)
+		}
+
+		hash, err := trinary.BytesToTrytes(hashBuf)
+		if err != nil {
+			return nil, errors.Wrapf(ErrLSFileCorrupted, "ledgerDiff: %v", err)
+		}
+		delta.ledgerDiff[hash[:81]] = change
+	}
+
+	sectionReader, count, err = lsReader.nextSection(localSnapshotSectionSpentAddressesPatches)
+	if err != nil {
+		return nil, errors.Wrapf(ErrLSFileCorrupted, "spentAddressesPatches: %v", err)
+	}
+
+	if err := binary.Read(sectionReader, binary.LittleEndian, &delta.spentAddressesCount); err != nil {
+		return nil, errors.Wrapf(ErrLSFileCorrupted, "spentAddressesPatches: %v", err)
+	}
+
+	if err := binary.Read(sectionReader, binary.LittleEndian, &delta.cuckooFilterLength); err != nil {
+		return nil, errors.Wrapf(ErrLSFileCorrupted, "spentAddressesPatches: %v", err)
+	}
+
+	for i := 0; i < int(count); i++ {
+		var offset, length int32
+
+		if err := binary.Read(sectionReader, binary.LittleEndian, &offset); err != nil {
+			return nil, errors.Wrapf(ErrLSFileCorrupted, "spentAddressesPatches: %v", err)
+		}
+
+		if err := binary.Read(sectionReader, binary.LittleEndian, &length); err != nil {
+			return nil, errors.Wrapf(ErrLSFileCorrupted, "spentAddressesPatches: %v", err)
+		}
+
+		patch := make([]byte, length)
+		if _, err := io.ReadFull(sectionReader, patch); err != nil {
+			return nil, errors.Wrapf(ErrLSFileCorrupted, "spentAddressesPatches: %v", err)
+		}
+		delta.cuckooFilterPatches[offset] = patch
+	}
+
+	return delta, nil
+}
+
+// getLedgerDiffBetweenMilestones sums up the ledger diffs of the milestones after the base index up to the target index.
+func getLedgerDiffBetweenMilestones(baseIndex milestone_index.MilestoneIndex, targetIndex milestone_index.MilestoneIndex, abortSignal <-chan struct{}) (map[string]int64, error) {
+
+	ledgerDiff := make(map[string]int64)
+	for milestoneIndex := baseIndex + 1; milestoneIndex <= targetIndex; milestoneIndex++ {
+		diff, err := tangle.GetLedgerDiffForMilestone(milestoneIndex, abortSignal)
+		if err != nil {
+			return nil, err
+		}
+
+		for address, change := range diff {
+			ledgerDiff[address] += change
+		}
+	}
+
+	for address, change := range ledgerDiff {
+		if change == 0 {
+			delete(ledgerDiff, address)
+		}
+	}
+
+	return ledgerDiff, nil
+}
+
+// writeLocalSnapshotDelta writes the changes since the latest local snapshot to a delta file.
+// It returns false if no delta can be written, because there is no local snapshot file which belongs to the
+// snapshot of the database, or if the maximum amount of delta files is reached, so a full local snapshot is due.
+func writeLocalSnapshotDelta(filePath string, lsh *localSnapshotHeader, snapshotInfo *tangle.SnapshotInfo, abortSignal <-chan struct{}) (bool, error) {
+
+	if _, err := os.Stat(filePath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	base, chain, err := readLocalSnapshotChain(filePath, true)
+	if err != nil {
+		log.Warnf("Reading the local snapshot for the delta failed, a full local snapshot is written: %v", err)
+		return false, nil
+	}
+
+	if len(chain) >= localSnapshotDeltaMaxCount {
+		return false, nil
+	}
+
+	// the ledger diffs of the database can only be used if the files end at the snapshot of the database
+	if base.msIndex != snapshotInfo.SnapshotIndex || base.msHash != snapshotInfo.Hash || base.msIndex >= lsh.msIndex {
+		log.Warnf("Local snapshot file (%d) doesn't match the snapshot of the database (%d), a full local snapshot is written", base.msIndex, snapshotInfo.SnapshotIndex)
+		return false, nil
+	}
+
+	ledgerDiff, err := getLedgerDiffBetweenMilestones(base.msIndex, lsh.msIndex, abortSignal)
+	if err != nil {
+		return false, err
+	}
+
+	deltaPath := localSnapshotDeltaFilePath(filePath, lsh.msIndex)
+	deltaPathTmp := deltaPath + "_tmp"
+
+	// Remove old temp file
+	os.Remove(deltaPathTmp)
+
+	if err := writeLocalSnapshotFile(deltaPathTmp, newLocalSnapshotDelta(base, lsh, ledgerDiff), localSnapshotCodec, abortSignal); err != nil {
+		os.Remove(deltaPathTmp)
+		return false, err
+	}
+
+	if err := os.Rename(deltaPathTmp, deltaPath); err != nil {
+		return false, err
+	}
+
+	log.Infof("Wrote local snapshot delta %s (%d of %d)", deltaPath, len(chain)+1, localSnapshotDeltaMaxCount)
+	return true, nil
+}
@@ -0,0 +1,750 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+)
+
+// Local snapshot file format v4:
+//
+//	version      byte
+//	codec        byte
+//	msHash       [49]byte
+//	msIndex      uint32
+//	msTimestamp  int64
+//	sections     solid entry points, seen milestones, balances, spent addresses
+//	fileHash     [32]byte, sha256 over all bytes in front of it
+//
+// Every section consists of:
+//
+//	type         byte
+//	count        int32, the number of entries
+//	length       uint64, the length of the (compressed) payload
+//	checksum     [32]byte, sha256 of the (compressed) payload
+//	payload      the entries, compressed with the codec of the file
+//
+// Delta files start with an additional marker byte and contain the base milestone (hash and index)
+// in front of the milestone of the snapshot. They use the same section layout with their own sections.
+//
+// v3 files are gzip compressed as a whole, so their first byte is the gzip magic number.
+// This is used to tell them apart from v4 files, which start with the uncompressed version byte.
+
+const (
+	// LocalSnapshotFileVersionV3 is the legacy format which is only read.
+	LocalSnapshotFileVersionV3 byte = 3
+	// LocalSnapshotFileVersionV4 splits the file into sections with their own length and checksum.
+	LocalSnapshotFileVersionV4 byte = 4
+
+	// the first byte of a gzip stream
+	gzipMagicByte byte = 0x1f
+	// the first byte of a delta file
+	localSnapshotDeltaFileMarker byte = 'D'
+)
+
+const (
+	LocalSnapshotCodecNone byte = iota
+	LocalSnapshotCodecGzip
+	LocalSnapshotCodecZstd
+)
+
+const (
+	localSnapshotSectionSolidEntryPoints byte = iota + 1
+	localSnapshotSectionSeenMilestones
+	localSnapshotSectionBalances
+	localSnapshotSectionSpentAddresses
+	localSnapshotSectionRemovedSolidEntryPoints
+	localSnapshotSectionLedgerDiff
+	localSnapshotSectionSpentAddressesPatches
+)
+
+var (
+	ErrUnknownLSCodec         = errors.New("unknown local snapshot codec")
+	ErrLSFileCorrupted        = errors.New("local snapshot file is corrupted")
+	ErrLSFileChecksumMismatch = errors.New("local snapshot checksum mismatch")
+	ErrUnexpectedLSSection    = errors.New("unexpected local snapshot section")
+	ErrUnexpectedLSFileType   = errors.New("unexpected local snapshot file type")
+
+	// the sections of a local snapshot file and a delta file in the order they are written
+	localSnapshotSectionSequence      = []byte{localSnapshotSectionSolidEntryPoints, localSnapshotSectionSeenMilestones, localSnapshotSectionBalances, localSnapshotSectionSpentAddresses}
+	localSnapshotDeltaSectionSequence = []byte{localSnapshotSectionSolidEntryPoints, localSnapshotSectionRemovedSolidEntryPoints, localSnapshotSectionSeenMilestones, localSnapshotSectionLedgerDiff, localSnapshotSectionSpentAddressesPatches}
+
+	localSnapshotSectionNames = map[byte]string{
+		localSnapshotSectionSolidEntryPoints:        "solidEntryPoints",
+		localSnapshotSectionSeenMilestones:          "seenMilestones",
+		localSnapshotSectionBalances:                "balances",
+		localSnapshotSectionSpentAddresses:          "spentAddresses",
+		localSnapshotSectionRemovedSolidEntryPoints: "removedSolidEntryPoints",
+		localSnapshotSectionLedgerDiff:              "ledgerDiff",
+		localSnapshotSectionSpentAddressesPatches:   "spentAddressesPatches",
+	}
+	localSnapshotCodecNames = map[byte]string{LocalSnapshotCodecNone: "none", LocalSnapshotCodecGzip: "gzip", LocalSnapshotCodecZstd: "zstd"}
+)
+
+// localSnapshotFileContent is written by writeLocalSnapshotFile, it is either a full local snapshot or a delta.
+type localSnapshotFileContent interface {
+	writeHeader(buf io.Writer, codec byte) error
+	sectionSequence() []byte
+	// writeSectionEntries writes the uncompressed entries of a section and returns their amount.
+	writeSectionEntries(buf io.Writer, sectionType byte, abortSignal <-chan struct{}) (int32, error)
+}
+
+// LocalSnapshotCodecFromString returns the codec with the given name.
+func LocalSnapshotCodecFromString(name string) (byte, error) {
+	for codec, codecName := range localSnapshotCodecNames {
+		if codecName == name {
+			return codec, nil
+		}
+	}
+	return 0, errors.Wrapf(ErrUnknownLSCodec, "%s", name)
+}
+
+func localSnapshotCodecName(codec byte) string {
+	if name, exists := localSnapshotCodecNames[codec]; exists {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", codec)
+}
+
+func localSnapshotSectionName(sectionType byte) string {
+	if name, exists := localSnapshotSectionNames[sectionType]; exists {
+		return name
+	}
+	return fmt.Sprintf("unknown (%d)", sectionType)
+}
+
+func isLocalSnapshotFileVersionSupported(version byte) bool {
+	return version >= MinSupportedLocalSnapshotFileVersion && version <= SupportedLocalSnapshotFileVersion
+}
+
+func unsupportedLocalSnapshotFileVersionError(version byte) error {
+	return errors.Wrapf(ErrUnsupportedLSFileVersion, "local snapshot file version is %d but this HORNET version only supports %d to %d", version, MinSupportedLocalSnapshotFileVersion, SupportedLocalSnapshotFileVersion)
+}
+
+// localSnapshotFileHeader contains the information in front of the sections of a local snapshot file.
+type localSnapshotFileHeader struct {
+	version     byte
+	codec       byte
+	msHash      trinary.Hash
+	msIndex     milestone_index.MilestoneIndex
+	msTimestamp int64
+
+	// the snapshot a delta file has to be applied on
+	delta       bool
+	baseMsHash  trinary.Hash
+	baseMsIndex milestone_index.MilestoneIndex
+}
+
+// length returns the size of the header in a v4 file.
+func (h *localSnapshotFileHeader) length() int64 {
+	// version, codec, msHash, msIndex, msTimestamp
+	length := int64(1 + 1 + 49 + 4 + 8)
+	if h.delta {
+		// marker, baseMsHash, baseMsIndex
+		length += 1 + 49 + 4
+	}
+	return length
+}
+
+type localSnapshotSectionHeader struct {
+	sectionType byte
+	count       int32
+	length      uint64
+	checksum    [sha256.Size]byte
+}
+
+func readLocalSnapshotSectionHeader(reader io.Reader) (*localSnapshotSectionHeader, error) {
+	sectionHeader := &localSnapshotSectionHeader{}
+
+	if err := binary.Read(reader, binary.LittleEndian, &sectionHeader.sectionType); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &sectionHeader.count); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &sectionHeader.length); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(reader, binary.LittleEndian, sectionHeader.checksum[:]); err != nil {
+		return nil, err
+	}
+
+	return sectionHeader, nil
+}
+
+func readLocalSnapshotMilestoneHashAndIndex(reader io.Reader) (trinary.Hash, milestone_index.MilestoneIndex, error) {
+
+	hashBuf := make([]byte, 49)
+	if err := binary.Read(reader, binary.LittleEndian, hashBuf); err != nil {
+		return "", 0, err
+	}
+
+	msHash, err := trinary.BytesToTrytes(hashBuf)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var msIndex milestone_index.MilestoneIndex
+	if err := binary.Read(reader, binary.LittleEndian, &msIndex); err != nil {
+		return "", 0, err
+	}
+
+	return msHash[:81], msIndex, nil
+}
+
+func readLocalSnapshotMilestone(reader io.Reader, header *localSnapshotFileHeader) error {
+
+	var err error
+	if header.msHash, header.msIndex, err = readLocalSnapshotMilestoneHashAndIndex(reader); err != nil {
+		return err
+	}
+
+	return binary.Read(reader, binary.LittleEndian, &header.msTimestamp)
+}
+
+func writeLocalSnapshotMilestoneHashAndIndex(buf io.Writer, msHash trinary.Hash, msIndex milestone_index.MilestoneIndex) error {
+
+	msHashBytes, err := trinary.TrytesToBytes(msHash)
+	if err != nil {
+		return err
+	}
+
+	if err := binary.Write(buf, binary.LittleEndian, msHashBytes[:49]); err != nil {
+		return err
+	}
+
+	return binary.Write(buf, binary.LittleEndian, msIndex)
+}
+
+// localSnapshotFileReader reads the sections of a local snapshot file in the order they were written.
+type localSnapshotFileReader struct {
+	header *localSnapshotFileHeader
+	file   *os.File
+	reader *bufio.Reader
+
+	// v3 files contain all counts in front of the entries and are read from a single gzip stream
+	gzipReader *gzip.Reader
+	v3Counts   map[byte]int32
+
+	// the raw and the decompressed payload of the current v4 section
+	sectionSequence []byte
+	sectionPayload  io.Reader
+	sectionReader   io.ReadCloser
+	sectionIndex    int
+}
+
+// openLocalSnapshotFile opens a local snapshot file of any supported version and reads its header.
+func openLocalSnapshotFile(filePath string) (*localSnapshotFileReader, error) {
+
+	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	if err != nil {
+		return nil, err
+	}
+
+	lsReader := &localSnapshotFileReader{
+		header: &localSnapshotFileHeader{},
+		file:   file,
+		reader: bufio.NewReader(file),
+	}
+
+	if err := lsReader.readHeader(); err != nil {
+		lsReader.Close()
+		return nil, err
+	}
+
+	return lsReader, nil
+}
+
+func (r *localSnapshotFileReader) readHeader() error {
+
+	firstByte, err := r.reader.Peek(1)
+	if err != nil {
+		return err
+	}
+
+	if firstByte[0] == gzipMagicByte {
+		return r.readHeaderV3()
+	}
+
+	r.sectionSequence = localSnapshotSectionSequence
+	if firstByte[0] == localSnapshotDeltaFileMarker {
+		r.reader.ReadByte()
+		r.header.delta = true
+		r.sectionSequence = localSnapshotDeltaSectionSequence
+	}
+
+	if err := binary.Read(r.reader, binary.LittleEndian, &r.header.version); err != nil {
+		return err
+	}
+
+	// v3 files are always gzip compressed
+	if r.header.version == LocalSnapshotFileVersionV3 || !isLocalSnapshotFileVersionSupported(r.header.version) {
+		return unsupportedLocalSnapshotFileVersionError(r.header.version)
+	}
+
+	if err := binary.Read(r.reader, binary.LittleEndian, &r.header.codec); err != nil {
+		return err
+	}
+
+	if _, exists := localSnapshotCodecNames[r.header.codec]; !exists {
+		return errors.Wrapf(ErrUnknownLSCodec, "%d", r.header.codec)
+	}
+
+	if r.header.delta {
+		if r.header.baseMsHash, r.header.baseMsIndex, err = readLocalSnapshotMilestoneHashAndIndex(r.reader); err != nil {
+			return err
+		}
+	}
+
+	return readLocalSnapshotMilestone(r.reader, r.header)
+}
+
+func (r *localSnapshotFileReader) readHeaderV3() error {
+
+	gzipReader, err := gzip.NewReader(r.reader)
+	if err != nil {
+		return err
+	}
+	r.gzipReader = gzipReader
+	r.header.codec = LocalSnapshotCodecGzip
+
+	if err := binary.Read(gzipReader, binary.LittleEndian, &r.header.version); err != nil {
+		return err
+	}
+
+	if r.header.version != LocalSnapshotFileVersionV3 {
+		return unsupportedLocalSnapshotFileVersionError(r.header.version)
+	}
+
+	if err := readLocalSnapshotMilestone(gzipReader, r.header); err != nil {
+		return err
+	}
+
+	r.v3Counts = make(map[byte]int32)
+	for _, sectionType := range localSnapshotSectionSequence {
+		var count int32
+		if err := binary.Read(gzipReader, binary.LittleEndian, &count); err != nil {
+			return err
+		}
+		r.v3Counts[sectionType] = count
+	}
+
+	return nil
+}
+
+// nextSection returns a reader for the decompressed entries of the next section and the amount of entries in it.
+// The sections have to be requested in the order they were written.
+func (r *localSnapshotFileReader) nextSection(sectionType byte) (io.Reader, int32, error) {
+
+	if r.gzipReader != nil {
+		// the entries of v3 files directly follow each other
+		return r.gzipReader, r.v3Counts[sectionType], nil
+	}
+
+	if err := r.closeSection(); err != nil {
+		return nil, 0, err
+	}
+
+	if r.sectionIndex >= len(r.sectionSequence) || r.sectionSequence[r.sectionIndex] != sectionType {
+		return nil, 0, errors.Wrapf(ErrUnexpectedLSSection, "%s requested out of order", localSnapshotSectionName(sectionType))
+	}
+	r.sectionIndex++
+
+	sectionHeader, err := readLocalSnapshotSectionHeader(r.reader)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if sectionHeader.sectionType != sectionType {
+		return nil, 0, errors.Wrapf(ErrUnexpectedLSSection, "expected %s, found %s", localSnapshotSectionName(sectionType), localSnapshotSectionName(sectionHeader.sectionType))
+	}
+
+	r.sectionPayload = io.LimitReader(r.reader, int64(sectionHeader.length))
+	r.sectionReader, err = newLocalSnapshotDecompressor(r.header.codec, r.sectionPayload)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return r.sectionReader, sectionHeader.count, nil
+}
+
+// closeSection skips the unread rest of the current section, so the next section header can be read.
+func (r *localSnapshotFileReader) closeSection() error {
+	if r.sectionReader == nil {
+		return nil
+	}
+
+	r.sectionReader.Close()
+	r.sectionReader = nil
+
+	_, err := io.Copy(ioutil.Discard, r.sectionPayload)
+	return err
+}
+
+func (r *localSnapshotFileReader) Close() error {
+	if r.sectionReader != nil {
+		r.sectionReader.Close()
+	}
+	if r.gzipReader != nil {
+		r.gzipReader.Close()
+	}
+	return r.file.Close()
+}
+
+// readLocalSnapshotFileHeader reads the header of a local snapshot file of any supported version.
+func readLocalSnapshotFileHeader(filePath string) (*localSnapshotFileHeader, error) {
+	lsReader, err := openLocalSnapshotFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer lsReader.Close()
+
+	return lsReader.header, nil
+}
+
+func newLocalSnapshotDecompressor(codec byte, reader io.Reader) (io.ReadCloser, error) {
+	switch codec {
+	case LocalSnapshotCodecNone:
+		return ioutil.NopCloser(reader), nil
+	case LocalSnapshotCodecGzip:
+		return gzip.NewReader(reader)
+	case LocalSnapshotCodecZstd:
+		decoder, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, errors.Wrapf(ErrUnknownLSCodec, "%d", codec)
+	}
+}
+
+func compressLocalSnapshotSection(codec byte, payload []byte) ([]byte, error) {
+	switch codec {
+	case LocalSnapshotCodecNone:
+		return payload, nil
+	case LocalSnapshotCodecGzip:
+		var buf bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buf)
+		if _, err := gzipWriter.Write(payload); err != nil {
+			return nil, err
+		}
+		if err := gzipWriter.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case LocalSnapshotCodecZstd:
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer encoder.Close()
+		return encoder.EncodeAll(payload, nil), nil
+	default:
+		return nil, errors.Wrapf(ErrUnknownLSCodec, "%d", codec)
+	}
+}
+
+// verifyLocalSnapshotFile checks the integrity of a local snapshot file before anything of it is applied.
+// The section checksums and the file hash of v4 files are checked without decompressing the payloads,
+// v3 files are decompressed to check the sha256 at their end.
+func verifyLocalSnapshotFile(filePath string) error {
+
+	lsReader, err := openLocalSnapshotFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer lsReader.Close()
+
+	// read the file again from the start, since the hash also covers the header
+	if _, err := lsReader.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	reader := bufio.NewReader(lsReader.file)
+
+	if lsReader.gzipReader != nil {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return errors.Wrapf(ErrLSFileCorrupted, "%v", err)
+		}
+		defer gzipReader.Close()
+
+		return verifyLocalSnapshotFileV3(gzipReader)
+	}
+
+	fileHash := sha256.New()
+	hashedReader := io.TeeReader(reader, fileHash)
+
+	if _, err := io.CopyN(ioutil.Discard, hashedReader, lsReader.header.length()); err != nil {
+		return errors.Wrapf(ErrLSFileCorrupted, "header: %v", err)
+	}
+
+	for _, sectionType := range lsReader.sectionSequence {
+		sectionHeader, err := readLocalSnapshotSectionHeader(hashedReader)
+		if err != nil {
+			return errors.Wrapf(ErrLSFileCorrupted, "%s: %v", localSnapshotSectionName(sectionType), err)
+		}
+
+		if sectionHeader.sectionType != sectionType {
+			return errors.Wrapf(ErrUnexpectedLSSection, "expected %s, found %s", localSnapshotSectionName(sectionType), localSnapshotSectionName(sectionHeader.sectionType))
+		}
+
+		sectionHash := sha256.New()
+		if _, err := io.CopyN(sectionHash, hashedReader, int64(sectionHeader.length)); err != nil {
+			return errors.Wrapf(ErrLSFileCorrupted, "%s: %v", localSnapshotSectionName(sectionType), err)
+		}
+
+		if !bytes.Equal(sectionHash.Sum(nil), sectionHeader.checksum[:]) {
+			return errors.Wrapf(ErrLSFileChecksumMismatch, "section %s", localSnapshotSectionName(sectionType))
+		}
+	}
+
+	expectedFileHash := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(reader, expectedFileHash); err != nil {
+		return errors.Wrapf(ErrLSFileCorrupted, "file hash: %v", err)
+	}
+
+	if !bytes.Equal(fileHash.Sum(nil), expectedFileHash) {
+		return errors.Wrap(ErrLSFileChecksumMismatch, "file hash")
+	}
+
+	if _, err := reader.ReadByte(); err != io.EOF {
+		return errors.Wrap(ErrLSFileCorrupted, "unexpected data after the file hash")
+	}
+
+	return nil
+}
+
+// verifyLocalSnapshotFileV3 checks the sha256 at the end of the decompressed data of a v3 file,
+// which covers everything in front of it.
+func verifyLocalSnapshotFileV3(reader io.Reader) error {
+
+	contentHash := sha256.New()
+
+	// the last bytes read so far are only hashed if more data follows
+	var tail []byte
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			data := append(tail, buf[:n]...)
+			if len(data) > sha256.Size {
+				contentHash.Write(data[:len(data)-sha256.Size])
+				data = data[len(data)-sha256.Size:]
+			}
+			tail = append([]byte{}, data...)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(ErrLSFileCorrupted, "%v", err)
+		}
+	}
+
+	if len(tail) != sha256.Size {
+		return errors.Wrap(ErrLSFileCorrupted, "file hash missing")
+	}
+
+	if !bytes.Equal(contentHash.Sum(nil), tail) {
+		return errors.Wrap(ErrLSFileChecksumMismatch, "file hash")
+	}
+
+	return nil
+}
+
+// writeLocalSnapshotFile writes a local snapshot or a delta in the newest file format.
+func writeLocalSnapshotFile(filePath string, content localSnapshotFileContent, codec byte, abortSignal <-chan struct{}) error {
+
+	exportFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0660)
+	if err != nil {
+		return err
+	}
+	defer exportFile.Close()
+
+	bufferedWriter := bufio.NewWriter(exportFile)
+	fileHash := sha256.New()
+	writer := io.MultiWriter(bufferedWriter, fileHash)
+
+	if err := content.writeHeader(writer, codec); err != nil {
+		return err
+	}
+
+	for _, sectionType := range content.sectionSequence() {
+		var payload bytes.Buffer
+		count, err := content.writeSectionEntries(&payload, sectionType, abortSignal)
+		if err != nil {
+			return err
+		}
+
+		compressedPayload, err := compressLocalSnapshotSection(codec, payload.Bytes())
+		if err != nil {
+			return err
+		}
+
+		sectionHeader := &localSnapshotSectionHeader{
+			sectionType: sectionType,
+			count:       count,
+			length:      uint64(len(compressedPayload)),
+			checksum:    sha256.Sum256(compressedPayload),
+		}
+
+		if err := writeLocalSnapshotSectionHeader(writer, sectionHeader); err != nil {
+			return err
+		}
+
+		if _, err := writer.Write(compressedPayload); err != nil {
+			return err
+		}
+	}
+
+	if _, err := bufferedWriter.Write(fileHash.Sum(nil)); err != nil {
+		return err
+	}
+
+	if err := bufferedWriter.Flush(); err != nil {
+		return err
+	}
+
+	return exportFile.Sync()
+}
+
+func writeLocalSnapshotSectionHeader(writer io.Writer, sectionHeader *localSnapshotSectionHeader) error {
+	if err := binary.Write(writer, binary.LittleEndian, sectionHeader.sectionType); err != nil {
+		return err
+	}
+	if err := binary.Write(writer, binary.LittleEndian, sectionHeader.count); err != nil {
+		return err
+	}
+	if err := binary.Write(writer, binary.LittleEndian, sectionHeader.length); err != nil {
+		return err
+	}
+	return binary.Write(writer, binary.LittleEndian, sectionHeader.checksum[:])
+}
+
+// readLocalSnapshot reads all sections of a local snapshot file of any supported version into memory.
+// The balances are only read if skipBalances is false, they are the biggest part of the file.
+// The checksums are not verified, use verifyLocalSnapshotFile for that.
+func readLocalSnapshot(filePath string, skipBalances bool) (*localSnapshotFileHeader, *localSnapshotHeader, error) {
+
+	lsReader, err := openLocalSnapshotFile(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer lsReader.Close()
+
+	header := lsReader.header
+	if header.delta {
+		return nil, nil, errors.Wrapf(ErrUnexpectedLSFileType, "%s is a delta file", filePath)
+	}
+	lsh := &localSnapshotHeader{
+		msHash:      header.msHash,
+		msIndex:     header.msIndex,
+		msTimestamp: header.msTimestamp,
+	}
+
+	sectionReader, count, err := lsReader.nextSection(localSnapshotSectionSolidEntryPoints)
+	if err != nil {
+		return nil, nil, errors.Wrapf(ErrLSFileCorrupted, "solidEntryPoints: %v", err)
+	}
+	if lsh.solidEntryPoints, err = readMilestoneIndexEntries(sectionReader, count); err != nil {
+		return nil, nil, errors.Wrapf(ErrLSFileCorrupted, "solidEntryPoints: %v", err)
+	}
+
+	sectionReader, count, err = lsReader.nextSection(localSnapshotSectionSeenMilestones)
+	if err != nil {
+		return nil, nil, errors.Wrapf(ErrLSFileCorrupted, "seenMilestones: %v", err)
+	}
+	if lsh.seenMilestones, err = readMilestoneIndexEntries(sectionReader, count); err != nil {
+		return nil, nil, errors.Wrapf(ErrLSFileCorrupted, "seenMilestones: %v", err)
+	}
+
+	sectionReader, count, err = lsReader.nextSection(localSnapshotSectionBalances)
+	if err != nil {
+		return nil, nil, errors.Wrapf(ErrLSFileCorrupted, "balances: %v", err)
+	}
+
+	if !skipBalances {
+		lsh.balances = make(map[string]uint64)
+	} else if lsReader.gzipReader == nil {
+		// the sections of v4 files can be skipped without decompressing them
+		count = 0
+	}
+
+	hashBuf := make([]byte, 49)
+	for i := 0; i < int(count); i++ {
+		var val uint64
+
+		if err := binary.Read(sectionReader, binary.LittleEndian, hashBuf); err != nil {
+			return nil, nil, errors.Wrapf(ErrLSFileCorrupted, "balances: %v", err)
+		}
+
+		if err := binary.Read(sectionReader, binary.LittleEndian, &val); err != nil {
+			return nil, nil, errors.Wrapf(ErrLSFileCorrupted, "balances: %v", err)
+		}
+
+		hash, err := trinary.BytesToTrytes(hashBuf)
+		if err != nil {
+			return nil, nil, errors.Wrapf(ErrLSFileCorrupted, "balances: %v", err)
+		}
+
+		if !skipBalances {
+			lsh.balances[hash[:81]] = val
+		}
+	}
+
+	sectionReader, lsh.spentAddressesCount, err = lsReader.nextSection(localSnapshotSectionSpentAddresses)
+	if err != nil {
+		return nil, nil, errors.Wrapf(ErrLSFileCorrupted, "spentAddresses: %v", err)
+	}
+
+	var cuckooFilterSize int32
+	if err := binary.Read(sectionReader, binary.LittleEndian, &cuckooFilterSize); err != nil {
+		return nil, nil, errors.Wrapf(ErrLSFileCorrupted, "spentAddresses: %v", err)
+	}
+
+	lsh.cuckooFilterBytes = make([]byte, cuckooFilterSize)
+	if _, err := io.ReadFull(sectionReader, lsh.cuckooFilterBytes); err != nil {
+		return nil, nil, errors.Wrapf(ErrLSFileCorrupted, "spentAddresses: %v", err)
+	}
+
+	return header, lsh, nil
+}
+
+func readMilestoneIndexEntries(reader io.Reader, count int32) (map[string]milestone_index.MilestoneIndex, error) {
+
+	entries := make(map[string]milestone_index.MilestoneIndex)
+	hashBuf := make([]byte, 49)
+	for i := 0; i < int(count); i++ {
+		var val uint32
+
+		if err := binary.Read(reader, binary.LittleEndian, hashBuf); err != nil {
+			return nil, err
+		}
+
+		if err := binary.Read(reader, binary.LittleEndian, &val); err != nil {
+			return nil, err
+		}
+
+		hash, err := trinary.BytesToTrytes(hashBuf)
+		if err != nil {
+			return nil, err
+		}
+		entries[hash[:81]] = milestone_index.MilestoneIndex(val)
+	}
+
+	return entries, nil
+}
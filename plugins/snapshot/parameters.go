@@ -17,9 +17,33 @@ func init() {
 	// "Interval, in milestone transactions, at which snapshot files are created if the ledger is not fully synchronized"
 	parameter.NodeConfig.SetDefault("localSnapshots.intervalUnsynced", 1000)
 
+	// "Interval, in minutes, after which a snapshot file is created regardless of the milestone intervals, 0 disables it"
+	parameter.NodeConfig.SetDefault("localSnapshots.intervalMinutes", 0)
+
 	// "Path to the local snapshot file"
 	parameter.NodeConfig.SetDefault("localSnapshots.path", "latest-export.gz.bin")
 
+	// "Compression of the sections of new local snapshot files (none, gzip, zstd)"
+	parameter.NodeConfig.SetDefault("localSnapshots.codec", "gzip")
+
+	// "Amount of delta files which only contain the changes since the previous local snapshot, before a full local snapshot is written again (0 = disabled)"
+	parameter.NodeConfig.SetDefault("localSnapshots.deltaMaxCount", 0)
+
+	// "Amount of full snapshot files to keep as copies with the milestone index in their name (0 = disabled)"
+	parameter.NodeConfig.SetDefault("localSnapshots.keepCount", 0)
+
+	// "Maximum age, in hours, of the kept snapshot file copies (0 = disabled)"
+	parameter.NodeConfig.SetDefault("localSnapshots.keepMaxAgeHours", 0)
+
+	// "URLs of trusted snapshot servers to download the local snapshot from, if the file doesn't exist at startup"
+	parameter.NodeConfig.SetDefault("localSnapshots.downloadURLs", []string{})
+
+	// "Serve the latest local snapshot file, merged with its delta files, and its manifest to other nodes"
+	parameter.NodeConfig.SetDefault("snapshotServer.enabled", false)
+
+	// "The bind address of the snapshot server"
+	parameter.NodeConfig.SetDefault("snapshotServer.bindAddress", "0.0.0.0:14266")
+
 	// "Whether to load a global snapshot from provided text files."
 	parameter.NodeConfig.SetDefault("globalSnapshot.load", false)
 
@@ -38,6 +62,15 @@ func init() {
 	// "Amount of milestone transactions to keep in the database"
 	parameter.NodeConfig.SetDefault("pruning.delay", 40000)
 
+	// "Maximum size of the database in MB, older milestones are pruned beyond the delay until the database is below it (0 = disabled)"
+	parameter.NodeConfig.SetDefault("pruning.targetDatabaseSizeMB", 0)
+
+	// "Addresses whose transactions and bundles are never pruned, together with the bundles which directly approve them"
+	parameter.NodeConfig.SetDefault("pruning.watchlist.addresses", []string{})
+
+	// "Tags whose transactions and bundles are never pruned, together with the bundles which directly approve them"
+	parameter.NodeConfig.SetDefault("pruning.watchlist.tags", []string{})
+
 	// "Path to the ledger state file for your private tangle"
 	parameter.NodeConfig.SetDefault("privateTangle.ledgerStatePath", "balances.txt")
 }
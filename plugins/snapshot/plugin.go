@@ -2,6 +2,8 @@ package snapshot
 
 import (
 	"errors"
+	"os"
+	"time"
 
 	"github.com/iotaledger/iota.go/consts"
 	"github.com/iotaledger/iota.go/transaction"
@@ -41,9 +43,21 @@ var (
 	snapshotDepth            milestone_index.MilestoneIndex
 	snapshotIntervalSynced   milestone_index.MilestoneIndex
 	snapshotIntervalUnsynced milestone_index.MilestoneIndex
+	localSnapshotCodec       byte
+	// the amount of delta files which are written on top of a full local snapshot file
+	localSnapshotDeltaMaxCount int
+	// wall-clock interval at which local snapshots are created regardless of the milestone intervals
+	snapshotIntervalTime time.Duration
+	// the time the last local snapshot file was written
+	lastLocalSnapshotTime time.Time
+	// the retention policy of the copies of the local snapshot files
+	snapshotKeepCount  int
+	snapshotKeepMaxAge time.Duration
 
 	pruningEnabled bool
 	pruningDelay   milestone_index.MilestoneIndex
+	// the database is pruned beyond the delay until it is below this size in bytes
+	pruningTargetDatabaseSize int64
 )
 
 func configure(plugin *node.Plugin) {
@@ -59,6 +73,22 @@ func configure(plugin *node.Plugin) {
 	snapshotIntervalSynced = milestone_index.MilestoneIndex(parameter.NodeConfig.GetInt("localSnapshots.intervalSynced"))
 	snapshotIntervalUnsynced = milestone_index.MilestoneIndex(parameter.NodeConfig.GetInt("localSnapshots.intervalUnsynced"))
 
+	codec, err := LocalSnapshotCodecFromString(parameter.NodeConfig.GetString("localSnapshots.codec"))
+	if err != nil {
+		log.Warnf("Parameter \"localSnapshots.codec\" is invalid (%v). Value was changed to %s", err, localSnapshotCodecName(LocalSnapshotCodecGzip))
+		codec = LocalSnapshotCodecGzip
+	}
+	localSnapshotCodec = codec
+	localSnapshotDeltaMaxCount = parameter.NodeConfig.GetInt("localSnapshots.deltaMaxCount")
+	snapshotIntervalTime = time.Duration(parameter.NodeConfig.GetInt("localSnapshots.intervalMinutes")) * time.Minute
+	snapshotKeepCount = parameter.NodeConfig.GetInt("localSnapshots.keepCount")
+	snapshotKeepMaxAge = time.Duration(parameter.NodeConfig.GetInt("localSnapshots.keepMaxAgeHours")) * time.Hour
+
+	lastLocalSnapshotTime = time.Now()
+	if fileInfo, err := os.Stat(parameter.NodeConfig.GetString("localSnapshots.path")); err == nil {
+		lastLocalSnapshotTime = fileInfo.ModTime()
+	}
+
 	pruningEnabled = parameter.NodeConfig.GetBool("pruning.enabled")
 	pruningDelay = milestone_index.MilestoneIndex(parameter.NodeConfig.GetInt("pruning.delay"))
 	pruningDelayMin := snapshotDepth + SolidEntryPointCheckThresholdPast + AdditionalPruningThreshold + 1
@@ -66,6 +96,8 @@ func configure(plugin *node.Plugin) {
 		log.Warnf("Parameter \"pruning.delay\" is too small (%d). Value was changed to %d", pruningDelay, pruningDelayMin)
 		pruningDelay = pruningDelayMin
 	}
+	pruningTargetDatabaseSize = int64(parameter.NodeConfig.GetInt("pruning.targetDatabaseSizeMB")) * 1024 * 1024
+	addConfiguredWatchlistEntries()
 }
 
 func run(plugin *node.Plugin) {
@@ -110,9 +142,18 @@ func run(plugin *node.Plugin) {
 		}
 	}, shutdown.ShutdownPriorityLocalSnapshots)
 
+	runSnapshotServer()
+
 	if tangle.GetSnapshotInfo() != nil {
 		// Check the ledger state
 		tangle.GetAllBalances(nil)
+
+		// the snapshot ledger is incomplete if the node was stopped while it was rewritten
+		if hasSnapshotLedger, err := tangle.HasSnapshotLedger(); err != nil {
+			log.Panic(err)
+		} else if !hasSnapshotLedger {
+			rebuildSnapshotLedger()
+		}
 		return
 	}
 
@@ -125,7 +166,7 @@ func run(plugin *node.Plugin) {
 		)
 
 	} else if parameter.NodeConfig.GetString("localSnapshots.path") != "" {
-		err = LoadSnapshotFromFile(parameter.NodeConfig.GetString("localSnapshots.path"))
+		err = loadLocalSnapshot(parameter.NodeConfig.GetString("localSnapshots.path"), parameter.NodeConfig.GetStringSlice("localSnapshots.downloadURLs"))
 
 	} else if parameter.NodeConfig.GetString("privateTangle.ledgerStatePath") != "" {
 		err = LoadEmptySnapshot(parameter.NodeConfig.GetString("privateTangle.ledgerStatePath"))
@@ -140,6 +181,18 @@ func run(plugin *node.Plugin) {
 	}
 }
 
+// loadLocalSnapshot loads the local snapshot file. If the file doesn't exist and trusted peers are configured,
+// the snapshot is downloaded from them first.
+func loadLocalSnapshot(filePath string, downloadURLs []string) error {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) && len(downloadURLs) > 0 {
+		if _, err := DownloadSnapshotFromPeers(filePath, downloadURLs); err != nil {
+			return err
+		}
+	}
+
+	return LoadSnapshotFromFile(filePath)
+}
+
 func installGenesisTransaction() {
 	// ensure genesis transaction exists
 	genesisTxTrits := make(trinary.Trits, consts.TransactionTrinarySize)
@@ -3,18 +3,55 @@ package snapshot
 import (
 	"time"
 
+	"github.com/iotaledger/iota.go/address"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/guards"
 	"github.com/iotaledger/iota.go/trinary"
 
+	hornetDB "github.com/gohornet/hornet/packages/database"
+	"github.com/gohornet/hornet/packages/model/hornet"
 	"github.com/gohornet/hornet/packages/model/milestone_index"
 	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/plugins/archive"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
 )
 
 const (
 	// AdditionalPruningThreshold is needed, because the transactions in the getMilestoneApprovees call in getSolidEntryPoints
 	// can reference older transactions as well
 	AdditionalPruningThreshold = 50
+
+	// the size based pruning measures the database size after every batch of this many pruned milestones,
+	// because the garbage collection only reclaims the space of a larger amount of deleted data
+	pruningSizeCheckInterval = 50
 )
 
+// addConfiguredWatchlistEntries adds the addresses and tags of the config to the watchlist.
+// Entries which are removed from the config stay on the watchlist until they are removed via API.
+func addConfiguredWatchlistEntries() {
+
+	for _, addr := range parameter.NodeConfig.GetStringSlice("pruning.watchlist.addresses") {
+		if err := address.ValidAddress(addr); err != nil {
+			log.Warnf("Invalid watchlist address %s: %v", addr, err)
+			continue
+		}
+		if err := tangle.AddWatchlistAddress(addr[:81]); err != nil {
+			log.Panic(err)
+		}
+	}
+
+	for _, tag := range parameter.NodeConfig.GetStringSlice("pruning.watchlist.tags") {
+		if tag == "" || !guards.IsTrytesOfMaxLength(tag, consts.TagTrinarySize/3) {
+			log.Warnf("Invalid watchlist tag %s", tag)
+			continue
+		}
+		if err := tangle.AddWatchlistTag(tag); err != nil {
+			log.Panic(err)
+		}
+	}
+}
+
 // pruneUnconfirmedTransactions prunes all unconfirmed tx from the database for the given milestone
 func pruneUnconfirmedTransactions(targetIndex milestone_index.MilestoneIndex) int {
 
@@ -59,6 +96,10 @@ func pruneUnconfirmedTransactions(targetIndex milestone_index.MilestoneIndex) in
 // pruneMilestone prunes the milestone metadata and the ledger diffs from the database for the given milestone
 func pruneMilestone(milestoneIndex milestone_index.MilestoneIndex) {
 
+	if archive.IsEnabled() {
+		archiveMilestone(milestoneIndex)
+	}
+
 	// state diffs
 	if err := tangle.DeleteLedgerDiffForMilestone(milestoneIndex); err != nil {
 		log.Error(err)
@@ -72,14 +113,39 @@ func pruneMilestone(milestoneIndex milestone_index.MilestoneIndex) {
 	}
 }
 
+// archiveMilestone moves the milestone and its ledger diff to the archive.
+// The node stops if archiving fails, because the data would be lost otherwise.
+func archiveMilestone(milestoneIndex milestone_index.MilestoneIndex) {
+
+	ms, _ := tangle.GetMilestone(milestoneIndex)
+	if ms == nil {
+		log.Panicf("archiveMilestone: Milestone (%d) not found!", milestoneIndex)
+	}
+
+	ledgerDiff, err := tangle.GetLedgerDiffForMilestone(milestoneIndex, nil)
+	if err != nil {
+		log.Panicf("archiveMilestone: Ledger diff of milestone (%d) not found: %v", milestoneIndex, err)
+	}
+
+	if err := archive.ArchiveMilestone(milestoneIndex, ms.GetTailHash(), ledgerDiff); err != nil {
+		log.Panicf("archiveMilestone: %v", err)
+	}
+}
+
 // pruneMilestone prunes the approvers, bundles, addresses and transaction metadata from the database
 // if the given txHashes are removed from their corresponding bundle buckets
 func pruneTransactions(txHashes []trinary.Hash) int {
 
 	txsToRemove := make(map[trinary.Hash]struct{})
 	bundlesTxsToRemove := make(map[trinary.Hash]trinary.Hash)
+	bundleTailsToRemove := make(map[trinary.Hash]trinary.Hash)
 	var approvers []*tangle.Approvers
 	var addresses []*tangle.TxHashForAddress
+	var txsToArchive []*hornet.Transaction
+
+	checkWatchlist := !tangle.IsWatchlistEmpty()
+	// whether the bundle of a transaction touches the watchlist
+	watchedTxs := make(map[trinary.Hash]bool)
 
 	for _, txHash := range txHashes {
 		tx, _ := tangle.GetTransaction(txHash)
@@ -92,6 +158,12 @@ func pruneTransactions(txHashes []trinary.Hash) int {
 			log.Panicf("pruneTransactions: Bundle bucket not found: %v", tx.Tx.Bundle)
 		}
 
+		if checkWatchlist && isKeptForWatchlist(bundleBucket, watchedTxs) {
+			// the bundles which touch the watchlist are kept together with the bundles which directly approve them,
+			// so that the approvers of the watched transactions can still be read
+			continue
+		}
+
 		for txToRemove := range bundleBucket.RemoveTransactionFromBundle(txHash) {
 			txsToRemove[txToRemove] = struct{}{}
 			bundlesTxsToRemove[tx.Tx.Bundle] = txToRemove
@@ -103,6 +175,16 @@ func pruneTransactions(txHashes []trinary.Hash) int {
 		if tx == nil {
 			log.Panicf("pruneTransactions: Transaction not found: %v", txHash)
 		}
+		txsToArchive = append(txsToArchive, tx)
+
+		if tx.IsTail() {
+			bundleTailsToRemove[txHash] = tx.Tx.Bundle
+		}
+
+		if checkWatchlist {
+			// the kept transactions must not reference their pruned approvers
+			approvers = append(approvers, removeApproverFromKeptApprovees(tx, txsToRemove)...)
+		}
 
 		approver, _ := tangle.GetApprovers(txHash)
 		if approver == nil {
@@ -116,6 +198,13 @@ func pruneTransactions(txHashes []trinary.Hash) int {
 		tangle.DiscardTransactionFromCache(txHash)
 	}
 
+	if archive.IsEnabled() && len(txsToArchive) > 0 {
+		// the node stops if archiving fails, because the transactions would be lost otherwise
+		if err := archive.ArchiveTransactions(txsToArchive); err != nil {
+			log.Panicf("pruneTransactions: %v", err)
+		}
+	}
+
 	// approvers
 	if err := tangle.DeleteApproversInDatabase(approvers); err != nil {
 		log.Error(err)
@@ -126,6 +215,11 @@ func pruneTransactions(txHashes []trinary.Hash) int {
 		log.Error(err)
 	}
 
+	// bundle tails
+	if err := tangle.DeleteBundleTailsInDatabase(bundleTailsToRemove); err != nil {
+		log.Error(err)
+	}
+
 	// tx
 	if err := tangle.DeleteTransactionsInDatabase(txsToRemove); err != nil {
 		log.Error(err)
@@ -139,6 +233,77 @@ func pruneTransactions(txHashes []trinary.Hash) int {
 	return len(txsToRemove)
 }
 
+// isKeptForWatchlist returns whether the bundle touches the watchlist or directly approves a bundle which touches it.
+func isKeptForWatchlist(bundleBucket *tangle.BundleBucket, watchedTxs map[trinary.Hash]bool) bool {
+
+	if tangle.IsWatchedBundleBucket(bundleBucket) {
+		return true
+	}
+
+	for _, tx := range bundleBucket.Transactions() {
+		if isInWatchedBundle(tx.GetTrunk(), watchedTxs) || isInWatchedBundle(tx.GetBranch(), watchedTxs) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isInWatchedBundle returns whether the transaction exists and belongs to a bundle which touches the watchlist.
+func isInWatchedBundle(txHash trinary.Hash, watchedTxs map[trinary.Hash]bool) bool {
+
+	if watched, exists := watchedTxs[txHash]; exists {
+		return watched
+	}
+
+	watched := false
+	if tx, _ := tangle.GetTransaction(txHash); tx != nil {
+		if bundleBucket, _ := tangle.GetBundleBucket(tx.Tx.Bundle); bundleBucket != nil {
+			watched = tangle.IsWatchedBundleBucket(bundleBucket)
+		}
+	}
+
+	watchedTxs[txHash] = watched
+	return watched
+}
+
+// removeApproverFromKeptApprovees removes the pruned transaction from the approvers of its trunk and branch,
+// if they are kept in the database. It returns the approver entries which have to be deleted in the database.
+func removeApproverFromKeptApprovees(tx *hornet.Transaction, txsToRemove map[trinary.Hash]struct{}) []*tangle.Approvers {
+
+	var approvers []*tangle.Approvers
+	for _, approveeHash := range []trinary.Hash{tx.GetTrunk(), tx.GetBranch()} {
+		if _, removed := txsToRemove[approveeHash]; removed {
+			continue
+		}
+
+		if contains, _ := tangle.ContainsTransaction(approveeHash); !contains {
+			continue
+		}
+
+		// the cached approvers are stored again if they are evicted
+		if cachedApprovers, _ := tangle.GetApprovers(approveeHash); cachedApprovers != nil {
+			cachedApprovers.Remove(tx.GetHash())
+		}
+
+		approver := tangle.NewApprovers(approveeHash)
+		approver.Add(tx.GetHash())
+		approvers = append(approvers, approver)
+	}
+
+	return approvers
+}
+
+// getPruningTargetIndexMax returns the newest milestone which can be pruned without
+// losing the history which is needed to calculate the solid entry points.
+// It returns 0 if the snapshot index is too young to prune anything.
+func getPruningTargetIndexMax(snapshotInfo *tangle.SnapshotInfo) milestone_index.MilestoneIndex {
+	if snapshotInfo.SnapshotIndex <= SolidEntryPointCheckThresholdPast+AdditionalPruningThreshold+1 {
+		return 0
+	}
+	return snapshotInfo.SnapshotIndex - SolidEntryPointCheckThresholdPast - AdditionalPruningThreshold - 1
+}
+
 // ToDo: Global pruning Lock needed?
 func pruneDatabase(solidMilestoneIndex milestone_index.MilestoneIndex, abortSignal <-chan struct{}) {
 
@@ -147,50 +312,176 @@ func pruneDatabase(solidMilestoneIndex milestone_index.MilestoneIndex, abortSign
 		log.Panic("No snapshotInfo found!")
 	}
 
-	targetIndex := solidMilestoneIndex - pruningDelay
-	targetIndexMax := (snapshotInfo.SnapshotIndex - SolidEntryPointCheckThresholdPast - AdditionalPruningThreshold - 1)
+	targetIndexMax := getPruningTargetIndexMax(snapshotInfo)
+	if targetIndexMax == 0 {
+		// the history of all milestones is still needed
+		return
+	}
+
+	var targetIndex milestone_index.MilestoneIndex
+	if solidMilestoneIndex > pruningDelay {
+		targetIndex = solidMilestoneIndex - pruningDelay
+	}
 	if targetIndex > targetIndexMax {
 		targetIndex = targetIndexMax
 	}
 
-	if snapshotInfo.PruningIndex >= targetIndex {
+	// the milestones after the target index are only pruned as long as the database exceeds the target size
+	sizeTargetIndex := targetIndex
+	if pruningTargetDatabaseSize > 0 && snapshotInfo.PruningIndex < targetIndexMax && databaseExceedsPruningTargetSize(true) {
+		sizeTargetIndex = targetIndexMax
+	}
+
+	if snapshotInfo.PruningIndex >= sizeTargetIndex {
 		// No pruning needed
 		return
 	}
 
+	if !startPruningRun(snapshotInfo.PruningIndex, sizeTargetIndex, false) {
+		// a manual pruning is running and prunes the database anyway
+		return
+	}
+
+	pruneMilestones(snapshotInfo, targetIndex, sizeTargetIndex, false, abortSignal)
+}
+
+// pruneMilestones prunes the milestones up to the target index. The milestones after it up to the size target index
+// are only pruned while the database exceeds the target size, which is measured again after every batch of milestones.
+// The pruning index is updated after every milestone, so that an aborted run can be continued later.
+// If lockPerMilestone is set, localSnapshotLock is only held while a single milestone is pruned,
+// so that local snapshots are still taken during a long run. Otherwise it must be held while entering this function.
+func pruneMilestones(snapshotInfo *tangle.SnapshotInfo, targetIndex milestone_index.MilestoneIndex, sizeTargetIndex milestone_index.MilestoneIndex, lockPerMilestone bool, abortSignal <-chan struct{}) {
+
+	defer finishPruningRun()
+
+	lsmSize, vlogSize, err := hornetDB.Size()
+	if err != nil {
+		log.Warnf("Reading the database size failed: %v", err)
+	}
+	sizeBefore := lsmSize + vlogSize
+	pruningIndexBefore := snapshotInfo.PruningIndex
+
+	// the deleted data is only removed from the disk by the value log garbage collection,
+	// the size based pruning relies on it to reach the target size
+	defer func() {
+		if snapshotInfo.PruningIndex == pruningIndexBefore {
+			return
+		}
+		if tanglePlugin.IsDatabaseGarbageCollectionAfterPruningEnabled() || pruningTargetDatabaseSize > 0 {
+			reclaimDatabaseSpace(sizeBefore)
+		}
+	}()
+
+	// the size was measured before the run was started
+	milestonesSinceSizeCheck := 0
+
 	// Iterate through all milestones that have to be pruned
-	for milestoneIndex := snapshotInfo.PruningIndex + 1; milestoneIndex <= targetIndex; milestoneIndex++ {
+	for milestoneIndex := snapshotInfo.PruningIndex + 1; milestoneIndex <= sizeTargetIndex; milestoneIndex++ {
 		select {
 		case <-abortSignal:
 			// Stop pruning the next milestone
+			log.Infof("Pruning aborted at milestone (%d)", snapshotInfo.PruningIndex)
 			return
 		default:
 		}
 
-		log.Infof("Pruning milestone (%d)...", milestoneIndex)
+		if milestoneIndex > targetIndex && milestonesSinceSizeCheck >= pruningSizeCheckInterval {
+			milestonesSinceSizeCheck = 0
 
-		ts := time.Now()
-		txCount := pruneUnconfirmedTransactions(milestoneIndex)
+			// the deleted data of the pruned milestones only leaves the disk after the garbage collection,
+			// a running compaction is not waited for, it runs the garbage collection itself afterwards
+			if !tanglePlugin.GetCompactionStatus().Running {
+				tanglePlugin.RunDatabaseGarbageCollection()
+			}
 
-		ms, _ := tangle.GetMilestone(milestoneIndex)
-		if ms == nil {
-			log.Panicf("Milestone (%d) not found!", milestoneIndex)
+			if !databaseExceedsPruningTargetSize(false) {
+				log.Infof("Database size is below the target size %s after pruning milestone (%d)", hornetDB.FormatSize(pruningTargetDatabaseSize), snapshotInfo.PruningIndex)
+				return
+			}
 		}
 
-		// Get all approvees of that milestone
-		approvees, err := getMilestoneApprovees(milestoneIndex, ms.GetTail(), false, nil)
-		if err != nil {
-			log.Errorf("Pruning milestone (%d) failed! %v", milestoneIndex, err)
-			continue
+		if lockPerMilestone {
+			localSnapshotLock.Lock()
+			// a local snapshot may have been taken in the meantime
+			snapshotInfo = tangle.GetSnapshotInfo()
+		}
+
+		txCount := pruneMilestoneIndex(milestoneIndex)
+
+		snapshotInfo.PruningIndex = milestoneIndex
+		tangle.SetSnapshotInfo(snapshotInfo)
+
+		if lockPerMilestone {
+			localSnapshotLock.Unlock()
 		}
 
-		txCount += pruneTransactions(approvees)
+		updatePruningRun(milestoneIndex, txCount)
+		milestonesSinceSizeCheck++
+	}
+}
+
+// pruneMilestoneIndex prunes the transactions and the milestone of the given index and returns the count of pruned transactions.
+func pruneMilestoneIndex(milestoneIndex milestone_index.MilestoneIndex) int {
+
+	log.Infof("Pruning milestone (%d)...", milestoneIndex)
+
+	ts := time.Now()
+	txCount := pruneUnconfirmedTransactions(milestoneIndex)
+
+	ms, _ := tangle.GetMilestone(milestoneIndex)
+	if ms == nil {
+		log.Panicf("Milestone (%d) not found!", milestoneIndex)
+	}
+
+	// Get all approvees of that milestone
+	approvees, err := getMilestoneApprovees(milestoneIndex, ms.GetTail(), false, nil)
+	if err != nil {
+		log.Errorf("Pruning milestone (%d) failed! %v", milestoneIndex, err)
+		return txCount
+	}
+
+	txCount += pruneTransactions(approvees)
+
+	pruneMilestone(milestoneIndex)
+
+	log.Infof("Pruning milestone (%d) took %v. Pruned %d transactions. ", milestoneIndex, time.Since(ts), txCount)
 
-		pruneMilestone(milestoneIndex)
+	return txCount
+}
+
+// databaseExceedsPruningTargetSize measures the size of the database on the disk and compares it with the target size.
+func databaseExceedsPruningTargetSize(logSize bool) bool {
+
+	lsmSize, vlogSize, err := hornetDB.Size()
+	if err != nil {
+		log.Warnf("Reading the database size failed: %v", err)
+		return false
+	}
+
+	dbSize := lsmSize + vlogSize
+	if dbSize <= pruningTargetDatabaseSize {
+		return false
+	}
 
-		log.Infof("Pruning milestone (%d) took %v. Pruned %d transactions. ", milestoneIndex, time.Since(ts), txCount)
+	if logSize {
+		log.Infof("Database size %s exceeds the target size %s (LSM: %s, vlog: %s)", hornetDB.FormatSize(dbSize), hornetDB.FormatSize(pruningTargetDatabaseSize), hornetDB.FormatSize(lsmSize), hornetDB.FormatSize(vlogSize))
+	}
+	return true
+}
+
+// reclaimDatabaseSpace runs the value log garbage collection after pruning and reports the reclaimed space.
+func reclaimDatabaseSpace(sizeBefore int64) {
+
+	ts := time.Now()
+
+	gcResult := tanglePlugin.RunDatabaseGarbageCollection()
+
+	lsmSize, vlogSize, err := hornetDB.Size()
+	if err != nil {
+		log.Warnf("Reading the database size failed: %v", err)
+		return
 	}
 
-	snapshotInfo.PruningIndex = targetIndex
-	tangle.SetSnapshotInfo(snapshotInfo)
+	sizeAfter := lsmSize + vlogSize
+	log.Infof("Pruning reclaimed %s (database size: %s => %s, rewritten value log files: %d), garbage collection took %v", hornetDB.FormatSize(sizeBefore-sizeAfter), hornetDB.FormatSize(sizeBefore), hornetDB.FormatSize(sizeAfter), gcResult.RewrittenFiles, time.Since(ts))
 }
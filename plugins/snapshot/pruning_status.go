@@ -0,0 +1,228 @@
+package snapshot
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/shutdown"
+)
+
+var (
+	ErrPruningAlreadyRunning  = errors.New("pruning is already running")
+	ErrNoManualPruningRunning = errors.New("no manual pruning is running")
+	ErrPruningTargetTooOld    = errors.New("pruning target is already pruned")
+	ErrPruningTargetTooNew    = errors.New("pruning target is too new")
+
+	pruningStatusLock syncutils.Mutex
+	pruningRun        = &pruningRunStatus{}
+	// closed to abort the running manual pruning
+	manualPruningAbortSignal chan struct{}
+)
+
+type pruningRunStatus struct {
+	running             bool
+	manual              bool
+	startIndex          milestone_index.MilestoneIndex
+	currentIndex        milestone_index.MilestoneIndex
+	targetIndex         milestone_index.MilestoneIndex
+	milestonesPruned    int
+	transactionsDeleted int
+	startTime           time.Time
+	endTime             time.Time
+}
+
+// PruningStatus contains the pruning index of the database and the progress of the current or the last pruning run.
+type PruningStatus struct {
+	PruningIndex milestone_index.MilestoneIndex `json:"pruningIndex"`
+	// the newest milestone which can be pruned at the moment
+	PruningIndexMax     milestone_index.MilestoneIndex `json:"pruningIndexMax"`
+	Running             bool                           `json:"running"`
+	Manual              bool                           `json:"manual"`
+	StartIndex          milestone_index.MilestoneIndex `json:"startIndex"`
+	TargetIndex         milestone_index.MilestoneIndex `json:"targetIndex"`
+	MilestonesRemaining uint32                         `json:"milestonesRemaining"`
+	MilestonesPruned    int                            `json:"milestonesPruned"`
+	TransactionsDeleted int                            `json:"transactionsDeleted"`
+	StartTime           int64                          `json:"startTime"`
+	// the duration of the run in seconds
+	Duration              float64 `json:"duration"`
+	MilestonesPerSecond   float64 `json:"milestonesPerSecond"`
+	TransactionsPerSecond float64 `json:"transactionsPerSecond"`
+}
+
+// startPruningRun resets the status for a new pruning run. An automatic run is not started while a manual run is pending.
+func startPruningRun(startIndex milestone_index.MilestoneIndex, targetIndex milestone_index.MilestoneIndex, manual bool) bool {
+	pruningStatusLock.Lock()
+	defer pruningStatusLock.Unlock()
+
+	if pruningRun.running && !manual {
+		return false
+	}
+
+	pruningRun = newPruningRunStatus(startIndex, targetIndex, manual)
+	return true
+}
+
+func newPruningRunStatus(startIndex milestone_index.MilestoneIndex, targetIndex milestone_index.MilestoneIndex, manual bool) *pruningRunStatus {
+	return &pruningRunStatus{
+		running:      true,
+		manual:       manual,
+		startIndex:   startIndex,
+		currentIndex: startIndex,
+		targetIndex:  targetIndex,
+		startTime:    time.Now(),
+	}
+}
+
+func updatePruningRun(milestoneIndex milestone_index.MilestoneIndex, txCount int) {
+	pruningStatusLock.Lock()
+	defer pruningStatusLock.Unlock()
+
+	pruningRun.currentIndex = milestoneIndex
+	pruningRun.milestonesPruned++
+	pruningRun.transactionsDeleted += txCount
+}
+
+func finishPruningRun() {
+	pruningStatusLock.Lock()
+	defer pruningStatusLock.Unlock()
+
+	pruningRun.running = false
+	pruningRun.endTime = time.Now()
+}
+
+// GetPruningStatus returns the pruning index and the progress of the current or the last pruning run.
+func GetPruningStatus() *PruningStatus {
+
+	status := &PruningStatus{}
+	if snapshotInfo := tangle.GetSnapshotInfo(); snapshotInfo != nil {
+		status.PruningIndex = snapshotInfo.PruningIndex
+		status.PruningIndexMax = getPruningTargetIndexMax(snapshotInfo)
+	}
+
+	pruningStatusLock.Lock()
+	defer pruningStatusLock.Unlock()
+
+	if pruningRun.startTime.IsZero() {
+		// no pruning since the start of the node
+		return status
+	}
+
+	status.Running = pruningRun.running
+	status.Manual = pruningRun.manual
+	status.StartIndex = pruningRun.startIndex
+	status.TargetIndex = pruningRun.targetIndex
+	if pruningRun.targetIndex > pruningRun.currentIndex {
+		status.MilestonesRemaining = uint32(pruningRun.targetIndex - pruningRun.currentIndex)
+	}
+	status.MilestonesPruned = pruningRun.milestonesPruned
+	status.TransactionsDeleted = pruningRun.transactionsDeleted
+	status.StartTime = pruningRun.startTime.Unix()
+
+	duration := time.Since(pruningRun.startTime)
+	if !pruningRun.running {
+		duration = pruningRun.endTime.Sub(pruningRun.startTime)
+	}
+	status.Duration = duration.Seconds()
+
+	if status.Duration > 0 {
+		status.MilestonesPerSecond = float64(status.MilestonesPruned) / status.Duration
+		status.TransactionsPerSecond = float64(status.TransactionsDeleted) / status.Duration
+	}
+
+	return status
+}
+
+// PruneDatabase prunes the database up to the target index in the background.
+// The target has to be above the current pruning index and must keep the history which is needed for the next local snapshot.
+func PruneDatabase(targetIndex milestone_index.MilestoneIndex) (*PruningStatus, error) {
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		log.Panic("No snapshotInfo found!")
+	}
+
+	if targetIndex <= snapshotInfo.PruningIndex {
+		return nil, errors.Wrapf(ErrPruningTargetTooOld, "pruning index: %d, actual: %d", snapshotInfo.PruningIndex, targetIndex)
+	}
+
+	if targetIndex >= snapshotInfo.SnapshotIndex {
+		return nil, errors.Wrapf(ErrPruningTargetTooNew, "snapshot index: %d, actual: %d", snapshotInfo.SnapshotIndex, targetIndex)
+	}
+
+	// the maximum is 0 if the snapshot index is too young to prune anything
+	if targetIndexMax := getPruningTargetIndexMax(snapshotInfo); targetIndexMax == 0 || targetIndex > targetIndexMax {
+		return nil, errors.Wrapf(ErrPruningTargetTooNew, "maximum: %d, actual: %d", targetIndexMax, targetIndex)
+	}
+
+	pruningStatusLock.Lock()
+	if pruningRun.running {
+		pruningStatusLock.Unlock()
+		return nil, ErrPruningAlreadyRunning
+	}
+	// the run is marked as running, so that the automatic pruning doesn't start in the meantime
+	pruningRun = newPruningRunStatus(snapshotInfo.PruningIndex, targetIndex, true)
+	abortSignal := make(chan struct{})
+	manualPruningAbortSignal = abortSignal
+	pruningStatusLock.Unlock()
+
+	if err := daemon.BackgroundWorker("Manual pruning", func(shutdownSignal <-chan struct{}) {
+		stopSignal := make(chan struct{})
+		defer close(stopSignal)
+
+		// the pruning is aborted by the API or by the shutdown of the node
+		pruningAbortSignal := make(chan struct{})
+		go func() {
+			select {
+			case <-shutdownSignal:
+			case <-abortSignal:
+			case <-stopSignal:
+			}
+			close(pruningAbortSignal)
+		}()
+
+		// the snapshot info may have changed since the request
+		snapshotInfo := tangle.GetSnapshotInfo()
+		startPruningRun(snapshotInfo.PruningIndex, targetIndex, true)
+
+		// the lock is only held per milestone, so that the local snapshots are not blocked by a long manual run
+		log.Infof("Manual pruning from milestone %d to %d started", snapshotInfo.PruningIndex+1, targetIndex)
+		pruneMilestones(snapshotInfo, targetIndex, targetIndex, true, pruningAbortSignal)
+		log.Infof("Manual pruning finished at milestone %d", tangle.GetSnapshotInfo().PruningIndex)
+
+		pruningStatusLock.Lock()
+		if manualPruningAbortSignal == abortSignal {
+			manualPruningAbortSignal = nil
+		}
+		pruningStatusLock.Unlock()
+	}, shutdown.ShutdownPriorityLocalSnapshots); err != nil {
+		pruningStatusLock.Lock()
+		manualPruningAbortSignal = nil
+		pruningStatusLock.Unlock()
+		finishPruningRun()
+		return nil, err
+	}
+
+	return GetPruningStatus(), nil
+}
+
+// AbortPruning stops the running manual pruning after the current milestone.
+func AbortPruning() error {
+	pruningStatusLock.Lock()
+	defer pruningStatusLock.Unlock()
+
+	if manualPruningAbortSignal == nil {
+		return ErrNoManualPruningRunning
+	}
+
+	close(manualPruningAbortSignal)
+	manualPruningAbortSignal = nil
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	snapshotManifestRequestTimeout = 10 * time.Second
+	snapshotDownloadTimeout        = 30 * time.Minute
+)
+
+var (
+	ErrNoSnapshotPeerAvailable = errors.New("no trusted peer could provide a local snapshot")
+	ErrSnapshotHashMismatch    = errors.New("downloaded local snapshot doesn't match the manifest")
+)
+
+type peerSnapshotManifest struct {
+	url      string
+	manifest *SnapshotManifest
+}
+
+func fetchSnapshotManifest(client *http.Client, baseURL string) (*SnapshotManifest, error) {
+	resp, err := client.Get(baseURL + SnapshotManifestRoute)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	manifest := &SnapshotManifest{}
+	if err := json.NewDecoder(resp.Body).Decode(manifest); err != nil {
+		return nil, errors.Wrap(err, "invalid manifest")
+	}
+	return manifest, nil
+}
+
+// downloadSnapshotFile downloads the local snapshot of the peer and checks its size and hash against the manifest.
+// The file is only moved to the target path if it is valid.
+func downloadSnapshotFile(client *http.Client, baseURL string, manifest *SnapshotManifest, filePath string) error {
+
+	filePathTmp := filePath + "_download"
+
+	// Remove old temp file
+	os.Remove(filePathTmp)
+
+	resp, err := client.Get(baseURL + SnapshotFileRoute)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	downloadFile, err := os.OpenFile(filePathTmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0660)
+	if err != nil {
+		return err
+	}
+
+	fileHash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(downloadFile, fileHash), resp.Body)
+	if closeErr := downloadFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filePathTmp)
+		return err
+	}
+
+	if checksum := hex.EncodeToString(fileHash.Sum(nil)); size != manifest.Size || checksum != manifest.SHA256 {
+		os.Remove(filePathTmp)
+		return errors.Wrapf(ErrSnapshotHashMismatch, "size: %d/%d, sha256: %s/%s", size, manifest.Size, checksum, manifest.SHA256)
+	}
+
+	return os.Rename(filePathTmp, filePath)
+}
+
+// DownloadSnapshotFromPeers downloads the newest local snapshot which is provided by the trusted peers.
+// The peers are queried for their manifests first, then the snapshots are tried in descending milestone order
+// until one download matches its manifest.
+func DownloadSnapshotFromPeers(filePath string, peerURLs []string) (*SnapshotManifest, error) {
+
+	manifestClient := &http.Client{Timeout: snapshotManifestRequestTimeout}
+	downloadClient := &http.Client{Timeout: snapshotDownloadTimeout}
+
+	var candidates []*peerSnapshotManifest
+	for _, peerURL := range peerURLs {
+		baseURL := strings.TrimSuffix(peerURL, "/")
+
+		manifest, err := fetchSnapshotManifest(manifestClient, baseURL)
+		if err != nil {
+			log.Warnf("Fetching the local snapshot manifest from %s failed: %v", baseURL, err)
+			continue
+		}
+
+		log.Infof("Peer %s provides a local snapshot at milestone %d", baseURL, manifest.MilestoneIndex)
+		candidates = append(candidates, &peerSnapshotManifest{url: baseURL, manifest: manifest})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].manifest.MilestoneIndex > candidates[j].manifest.MilestoneIndex
+	})
+
+	for _, candidate := range candidates {
+		log.Infof("Downloading local snapshot at milestone %d from %s ...", candidate.manifest.MilestoneIndex, candidate.url)
+		ts := time.Now()
+
+		if err := downloadSnapshotFile(downloadClient, candidate.url, candidate.manifest, filePath); err != nil {
+			log.Warnf("Downloading local snapshot from %s failed: %v", candidate.url, err)
+			continue
+		}
+
+		log.Infof("Downloading local snapshot at milestone %d from %s ... done, took %v", candidate.manifest.MilestoneIndex, candidate.url, time.Since(ts))
+		return candidate.manifest, nil
+	}
+
+	return nil, ErrNoSnapshotPeerAvailable
+}
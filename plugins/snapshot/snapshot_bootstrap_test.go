@@ -0,0 +1,153 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iotaledger/hive.go/logger"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+)
+
+func init() {
+	testLogger, err := logger.NewRootLogger(logger.Config{Level: "info", Encoding: "console", OutputPaths: []string{"stdout"}})
+	if err != nil {
+		panic(err)
+	}
+	log = testLogger.Named("Snapshot")
+}
+
+// newTestSnapshotPeer starts a peer which serves the given content with the snapshot file handler.
+// The manifest is derived from the content and can be modified before it is served.
+// The content is stored in the given directory.
+func newTestSnapshotPeer(t *testing.T, dir string, msIndex milestone_index.MilestoneIndex, content []byte, modifyManifest func(manifest *SnapshotManifest)) *httptest.Server {
+
+	filePath := filepath.Join(dir, fmt.Sprintf("snapshot_%d.bin", msIndex))
+	if err := ioutil.WriteFile(filePath, content, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	fileHash := sha256.Sum256(content)
+	manifest := &SnapshotManifest{
+		MilestoneIndex: msIndex,
+		SHA256:         hex.EncodeToString(fileHash[:]),
+		Size:           int64(len(content)),
+	}
+	if modifyManifest != nil {
+		modifyManifest(manifest)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(SnapshotManifestRoute, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(manifest)
+	})
+	mux.HandleFunc(SnapshotFileRoute, serveSnapshotFile(filePath))
+
+	return httptest.NewServer(mux)
+}
+
+func testTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "hornet-snapshot-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDownloadSnapshotFromPeersPicksHighestMilestone(t *testing.T) {
+
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+
+	low := newTestSnapshotPeer(t, dir, 100, []byte("snapshot at 100"), nil)
+	high := newTestSnapshotPeer(t, dir, 300, []byte("snapshot at 300"), nil)
+	middle := newTestSnapshotPeer(t, dir, 200, []byte("snapshot at 200"), nil)
+	defer low.Close()
+	defer high.Close()
+	defer middle.Close()
+
+	filePath := filepath.Join(dir, "export.bin")
+	manifest, err := DownloadSnapshotFromPeers(filePath, []string{low.URL, high.URL + "/", middle.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.MilestoneIndex != 300 {
+		t.Fatalf("expected the snapshot at milestone 300, got %d", manifest.MilestoneIndex)
+	}
+	assertFileContent(t, filePath, []byte("snapshot at 300"))
+}
+
+func TestDownloadSnapshotFromPeersFallsBackOnMismatch(t *testing.T) {
+
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+
+	wrongSize := newTestSnapshotPeer(t, dir, 300, []byte("snapshot at 300"), func(manifest *SnapshotManifest) {
+		manifest.Size++
+	})
+	wrongHash := newTestSnapshotPeer(t, dir, 200, []byte("snapshot at 200"), func(manifest *SnapshotManifest) {
+		manifest.SHA256 = hex.EncodeToString(make([]byte, sha256.Size))
+	})
+	valid := newTestSnapshotPeer(t, dir, 100, []byte("snapshot at 100"), nil)
+	defer wrongSize.Close()
+	defer wrongHash.Close()
+	defer valid.Close()
+
+	filePath := filepath.Join(dir, "export.bin")
+	manifest, err := DownloadSnapshotFromPeers(filePath, []string{wrongSize.URL, wrongHash.URL, valid.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.MilestoneIndex != 100 {
+		t.Fatalf("expected the snapshot at milestone 100, got %d", manifest.MilestoneIndex)
+	}
+	assertFileContent(t, filePath, []byte("snapshot at 100"))
+
+	if _, err := os.Stat(filePath + "_download"); !os.IsNotExist(err) {
+		t.Fatalf("the temporary download file was not removed: %v", err)
+	}
+}
+
+func TestDownloadSnapshotFromPeersNoPeerAvailable(t *testing.T) {
+
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+
+	wrongHash := newTestSnapshotPeer(t, dir, 200, []byte("snapshot at 200"), func(manifest *SnapshotManifest) {
+		manifest.SHA256 = hex.EncodeToString(make([]byte, sha256.Size))
+	})
+	defer wrongHash.Close()
+	noSnapshot := httptest.NewServer(http.NotFoundHandler())
+	defer noSnapshot.Close()
+
+	filePath := filepath.Join(dir, "export.bin")
+	if _, err := DownloadSnapshotFromPeers(filePath, []string{wrongHash.URL, noSnapshot.URL}); err != ErrNoSnapshotPeerAvailable {
+		t.Fatalf("expected %v, got %v", ErrNoSnapshotPeerAvailable, err)
+	}
+
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Fatalf("no local snapshot should have been written: %v", err)
+	}
+}
+
+func assertFileContent(t *testing.T, filePath string, expected []byte) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, expected) {
+		t.Fatalf("expected file content %q, got %q", expected, content)
+	}
+}
@@ -0,0 +1,348 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// the size of a hash and a milestone index, which are stored for solid entry points and seen milestones
+	milestoneIndexEntrySize = 49 + 4
+	// the size of an address and a balance or a balance change
+	balanceEntrySize = 49 + 8
+)
+
+// snapshotFileHeaderDump contains the raw header and the section headers of a local snapshot file.
+// They are read as far as possible, even if the file version is not supported or the file is corrupted.
+type snapshotFileHeaderDump struct {
+	FilePath           string                 `json:"filePath"`
+	Delta              bool                   `json:"delta"`
+	Version            byte                   `json:"version"`
+	Codec              string                 `json:"codec"`
+	BaseMilestoneHash  string                 `json:"baseMilestoneHash,omitempty"`
+	BaseMilestoneIndex uint32                 `json:"baseMilestoneIndex,omitempty"`
+	MilestoneHash      string                 `json:"milestoneHash"`
+	MilestoneIndex     uint32                 `json:"milestoneIndex"`
+	Timestamp          int64                  `json:"timestamp"`
+	Sections           []*snapshotSectionDump `json:"sections"`
+	// the problems found in the file, empty if there are none
+	VersionError  string `json:"versionError,omitempty"`
+	CodecError    string `json:"codecError,omitempty"`
+	HeaderError   string `json:"headerError,omitempty"`
+	FileHashError string `json:"fileHashError,omitempty"`
+}
+
+type snapshotSectionDump struct {
+	Type  string `json:"type"`
+	Count int32  `json:"count"`
+	// the length and the checksum of the compressed payload, v3 files don't contain them
+	Length   uint64 `json:"length,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+	// the problems found in the section, empty if there are none
+	ChecksumError string `json:"checksumError,omitempty"`
+	ParseError    string `json:"parseError,omitempty"`
+}
+
+// localSnapshotFileInspector collects the first problem found in a file, the others are only reported in the dump.
+type localSnapshotFileInspector struct {
+	dump     *snapshotFileHeaderDump
+	firstErr error
+}
+
+func (i *localSnapshotFileInspector) report(target *string, err error) {
+	*target = err.Error()
+	if i.firstErr == nil {
+		i.firstErr = err
+	}
+}
+
+// inspectLocalSnapshotFile reads the header and the section headers of a local snapshot file and checks every section
+// on its own, without stopping at the first problem. The returned error is the first problem found in the file.
+// The dump is nil if the file could not be opened.
+func inspectLocalSnapshotFile(filePath string) (*snapshotFileHeaderDump, error) {
+
+	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	inspector := &localSnapshotFileInspector{
+		dump: &snapshotFileHeaderDump{
+			FilePath: filePath,
+			Sections: []*snapshotSectionDump{},
+		},
+	}
+
+	reader := bufio.NewReader(file)
+
+	firstByte, err := reader.Peek(1)
+	if err != nil {
+		inspector.report(&inspector.dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "header: %v", err))
+		return inspector.dump, inspector.firstErr
+	}
+
+	if firstByte[0] == gzipMagicByte {
+		inspector.inspectV3(reader)
+	} else {
+		inspector.inspectV4(reader, fileInfo.Size())
+	}
+
+	return inspector.dump, inspector.firstErr
+}
+
+func (i *localSnapshotFileInspector) inspectV3(reader io.Reader) {
+
+	dump := i.dump
+	dump.Codec = localSnapshotCodecName(LocalSnapshotCodecGzip)
+
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		i.report(&dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "header: %v", err))
+		return
+	}
+	defer gzipReader.Close()
+
+	// the file hash covers the header as well, so the header bytes are kept to verify it at the end
+	var headerBytes bytes.Buffer
+	contentReader := io.TeeReader(gzipReader, &headerBytes)
+
+	if err := binary.Read(contentReader, binary.LittleEndian, &dump.Version); err != nil {
+		i.report(&dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "header: %v", err))
+		return
+	}
+
+	if dump.Version != LocalSnapshotFileVersionV3 {
+		i.report(&dump.VersionError, unsupportedLocalSnapshotFileVersionError(dump.Version))
+	}
+
+	header := &localSnapshotFileHeader{}
+	if err := readLocalSnapshotMilestone(contentReader, header); err != nil {
+		i.report(&dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "milestone: %v", err))
+		return
+	}
+	dump.MilestoneHash = header.msHash
+	dump.MilestoneIndex = uint32(header.msIndex)
+	dump.Timestamp = header.msTimestamp
+
+	// v3 files only contain the counts of the sections in front of the entries
+	for _, sectionType := range localSnapshotSectionSequence {
+		sectionDump := &snapshotSectionDump{Type: localSnapshotSectionName(sectionType)}
+		if err := binary.Read(contentReader, binary.LittleEndian, &sectionDump.Count); err != nil {
+			i.report(&dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "%s count: %v", sectionDump.Type, err))
+			return
+		}
+		dump.Sections = append(dump.Sections, sectionDump)
+	}
+
+	if err := verifyLocalSnapshotFileV3(io.MultiReader(&headerBytes, gzipReader)); err != nil {
+		i.report(&dump.FileHashError, err)
+	}
+}
+
+func (i *localSnapshotFileInspector) inspectV4(reader *bufio.Reader, fileSize int64) {
+
+	dump := i.dump
+
+	fileHash := sha256.New()
+	hashedReader := io.TeeReader(reader, fileHash)
+
+	sectionSequence := localSnapshotSectionSequence
+	if firstByte, _ := reader.Peek(1); firstByte[0] == localSnapshotDeltaFileMarker {
+		io.CopyN(ioutil.Discard, hashedReader, 1)
+		dump.Delta = true
+		sectionSequence = localSnapshotDeltaSectionSequence
+	}
+
+	if err := binary.Read(hashedReader, binary.LittleEndian, &dump.Version); err != nil {
+		i.report(&dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "version: %v", err))
+		return
+	}
+
+	// the rest of the file is read with the v4 layout, even if the version is not supported
+	if dump.Version == LocalSnapshotFileVersionV3 || !isLocalSnapshotFileVersionSupported(dump.Version) {
+		i.report(&dump.VersionError, unsupportedLocalSnapshotFileVersionError(dump.Version))
+	}
+
+	var codec byte
+	if err := binary.Read(hashedReader, binary.LittleEndian, &codec); err != nil {
+		i.report(&dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "codec: %v", err))
+		return
+	}
+	dump.Codec = localSnapshotCodecName(codec)
+
+	_, codecKnown := localSnapshotCodecNames[codec]
+	if !codecKnown {
+		i.report(&dump.CodecError, errors.Wrapf(ErrUnknownLSCodec, "%d", codec))
+	}
+
+	if dump.Delta {
+		baseMsHash, baseMsIndex, err := readLocalSnapshotMilestoneHashAndIndex(hashedReader)
+		if err != nil {
+			i.report(&dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "base milestone: %v", err))
+			return
+		}
+		dump.BaseMilestoneHash = baseMsHash
+		dump.BaseMilestoneIndex = uint32(baseMsIndex)
+	}
+
+	header := &localSnapshotFileHeader{}
+	if err := readLocalSnapshotMilestone(hashedReader, header); err != nil {
+		i.report(&dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "milestone: %v", err))
+		return
+	}
+	dump.MilestoneHash = header.msHash
+	dump.MilestoneIndex = uint32(header.msIndex)
+	dump.Timestamp = header.msTimestamp
+
+	for _, sectionType := range sectionSequence {
+		sectionHeader, err := readLocalSnapshotSectionHeader(hashedReader)
+		if err != nil {
+			i.report(&dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "%s section header: %v", localSnapshotSectionName(sectionType), err))
+			return
+		}
+
+		sectionDump := &snapshotSectionDump{
+			Type:     localSnapshotSectionName(sectionHeader.sectionType),
+			Count:    sectionHeader.count,
+			Length:   sectionHeader.length,
+			Checksum: hex.EncodeToString(sectionHeader.checksum[:]),
+		}
+		dump.Sections = append(dump.Sections, sectionDump)
+
+		if sectionHeader.sectionType != sectionType {
+			i.report(&sectionDump.ParseError, errors.Wrapf(ErrUnexpectedLSSection, "expected %s, found %s", localSnapshotSectionName(sectionType), sectionDump.Type))
+		}
+
+		// a corrupted length must not be allocated
+		if sectionHeader.length > uint64(fileSize) {
+			i.report(&dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "%s: length %d exceeds the file size %d", sectionDump.Type, sectionHeader.length, fileSize))
+			return
+		}
+
+		payload := make([]byte, sectionHeader.length)
+		if _, err := io.ReadFull(hashedReader, payload); err != nil {
+			i.report(&dump.HeaderError, errors.Wrapf(ErrLSFileCorrupted, "%s: %v", sectionDump.Type, err))
+			return
+		}
+
+		if checksum := sha256.Sum256(payload); !bytes.Equal(checksum[:], sectionHeader.checksum[:]) {
+			i.report(&sectionDump.ChecksumError, errors.Wrapf(ErrLSFileChecksumMismatch, "section %s", sectionDump.Type))
+		}
+
+		if !codecKnown {
+			continue
+		}
+
+		if err := parseLocalSnapshotSectionPayload(codec, sectionHeader, payload); err != nil {
+			i.report(&sectionDump.ParseError, errors.Wrapf(ErrLSFileCorrupted, "%s: %v", sectionDump.Type, err))
+		}
+	}
+
+	expectedFileHash := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(reader, expectedFileHash); err != nil {
+		i.report(&dump.FileHashError, errors.Wrapf(ErrLSFileCorrupted, "file hash: %v", err))
+		return
+	}
+
+	if !bytes.Equal(fileHash.Sum(nil), expectedFileHash) {
+		i.report(&dump.FileHashError, errors.Wrap(ErrLSFileChecksumMismatch, "file hash"))
+		return
+	}
+
+	if _, err := reader.ReadByte(); err != io.EOF {
+		i.report(&dump.FileHashError, errors.Wrap(ErrLSFileCorrupted, "unexpected data after the file hash"))
+	}
+}
+
+// parseLocalSnapshotSectionPayload decompresses the payload of a section and checks that it contains the amount of entries in the section header.
+func parseLocalSnapshotSectionPayload(codec byte, sectionHeader *localSnapshotSectionHeader, payload []byte) error {
+
+	sectionReader, err := newLocalSnapshotDecompressor(codec, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer sectionReader.Close()
+
+	entries, err := ioutil.ReadAll(sectionReader)
+	if err != nil {
+		return err
+	}
+
+	count := int(sectionHeader.count)
+	if count < 0 {
+		return fmt.Errorf("invalid count %d", count)
+	}
+
+	switch sectionHeader.sectionType {
+	case localSnapshotSectionSolidEntryPoints, localSnapshotSectionSeenMilestones, localSnapshotSectionRemovedSolidEntryPoints:
+		return checkLocalSnapshotSectionEntriesLength(len(entries), count*milestoneIndexEntrySize)
+
+	case localSnapshotSectionBalances, localSnapshotSectionLedgerDiff:
+		return checkLocalSnapshotSectionEntriesLength(len(entries), count*balanceEntrySize)
+
+	case localSnapshotSectionSpentAddresses:
+		// the count is the amount of spent addresses in the cuckoo filter, which is stored with its length
+		var filterLength int32
+		if err := binary.Read(bytes.NewReader(entries), binary.LittleEndian, &filterLength); err != nil {
+			return err
+		}
+		return checkLocalSnapshotSectionEntriesLength(len(entries), 4+int(filterLength))
+
+	case localSnapshotSectionSpentAddressesPatches:
+		entriesReader := bytes.NewReader(entries)
+
+		// the amount of spent addresses and the length of the cuckoo filter in front of the patches
+		var spentAddressesCount, filterLength int32
+		if err := binary.Read(entriesReader, binary.LittleEndian, &spentAddressesCount); err != nil {
+			return err
+		}
+		if err := binary.Read(entriesReader, binary.LittleEndian, &filterLength); err != nil {
+			return err
+		}
+
+		for i := 0; i < count; i++ {
+			var offset, length int32
+			if err := binary.Read(entriesReader, binary.LittleEndian, &offset); err != nil {
+				return err
+			}
+			if err := binary.Read(entriesReader, binary.LittleEndian, &length); err != nil {
+				return err
+			}
+			if length < 0 || int64(length) > int64(entriesReader.Len()) {
+				return fmt.Errorf("patch at offset %d: invalid length %d", offset, length)
+			}
+			entriesReader.Seek(int64(length), io.SeekCurrent)
+		}
+
+		if entriesReader.Len() != 0 {
+			return fmt.Errorf("%d bytes after the last patch", entriesReader.Len())
+		}
+		return nil
+
+	default:
+		return errors.Wrapf(ErrUnexpectedLSSection, "%d", sectionHeader.sectionType)
+	}
+}
+
+func checkLocalSnapshotSectionEntriesLength(length int, expectedLength int) error {
+	if length != expectedLength {
+		return fmt.Errorf("decompressed length %d doesn't match the expected length %d", length, expectedLength)
+	}
+	return nil
+}
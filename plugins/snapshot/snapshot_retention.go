@@ -0,0 +1,222 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/parameter"
+)
+
+// LocalSnapshotFileInfo describes a local snapshot file on the disk.
+type LocalSnapshotFileInfo struct {
+	FilePath       string                         `json:"filePath"`
+	MilestoneIndex milestone_index.MilestoneIndex `json:"milestoneIndex"`
+	MilestoneHash  trinary.Hash                   `json:"milestoneHash"`
+	Timestamp      int64                          `json:"timestamp"`
+	Size           int64                          `json:"size"`
+	ModTime        int64                          `json:"modTime"`
+	// the file which is loaded by the node, the others are older copies which are kept by the retention policy
+	Latest bool `json:"latest"`
+	Delta  bool `json:"delta"`
+	// the snapshot a delta file has to be applied on
+	BaseMilestoneIndex milestone_index.MilestoneIndex `json:"baseMilestoneIndex,omitempty"`
+	// the header of the file couldn't be read
+	Error string `json:"error,omitempty"`
+}
+
+func isLocalSnapshotRetentionEnabled() bool {
+	return snapshotKeepCount > 0 || snapshotKeepMaxAge > 0
+}
+
+// splitLocalSnapshotFileName splits the file name at the first dot, so that multiple extensions like ".gz.bin" are kept together.
+func splitLocalSnapshotFileName(filePath string) (string, string) {
+	dir, name := filepath.Split(filePath)
+	if i := strings.Index(name, "."); i > 0 {
+		return filepath.Join(dir, name[:i]), name[i:]
+	}
+	return filepath.Join(dir, name), ""
+}
+
+// localSnapshotHistoryFilePath returns the path of the copy of a local snapshot with the milestone index in its name,
+// e.g. "latest-export.gz.bin" => "latest-export_1234.gz.bin".
+func localSnapshotHistoryFilePath(filePath string, msIndex milestone_index.MilestoneIndex) string {
+	stem, ext := splitLocalSnapshotFileName(filePath)
+	return fmt.Sprintf("%s_%d%s", stem, msIndex, ext)
+}
+
+// getLocalSnapshotHistoryFilePaths returns the copies of the local snapshot file, the newest first.
+func getLocalSnapshotHistoryFilePaths(filePath string) ([]string, error) {
+	stem, ext := splitLocalSnapshotFileName(filePath)
+
+	matches, err := filepath.Glob(stem + "_*" + ext)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make(map[string]uint64)
+	var historyPaths []string
+	for _, match := range matches {
+		index, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(match, stem+"_"), ext), 10, 32)
+		if err != nil {
+			// not a copy of the local snapshot file
+			continue
+		}
+		indexes[match] = index
+		historyPaths = append(historyPaths, match)
+	}
+
+	sort.Slice(historyPaths, func(i, j int) bool {
+		return indexes[historyPaths[i]] > indexes[historyPaths[j]]
+	})
+
+	return historyPaths, nil
+}
+
+// keepLocalSnapshotCopy stores a copy of the written local snapshot file with the milestone index in its name
+// and removes the copies which are not covered by the retention policy anymore.
+func keepLocalSnapshotCopy(filePath string, msIndex milestone_index.MilestoneIndex) error {
+
+	historyPath := localSnapshotHistoryFilePath(filePath, msIndex)
+	historyPathTmp := historyPath + "_tmp"
+
+	// Remove old temp file
+	os.Remove(historyPathTmp)
+
+	// a hard link doesn't need additional space, the file is copied if links are not supported
+	if err := os.Link(filePath, historyPathTmp); err != nil {
+		if err := copyFile(filePath, historyPathTmp); err != nil {
+			os.Remove(historyPathTmp)
+			return err
+		}
+	}
+
+	if err := os.Rename(historyPathTmp, historyPath); err != nil {
+		return err
+	}
+
+	return cleanupLocalSnapshotHistory(filePath)
+}
+
+// cleanupLocalSnapshotHistory removes the copies of the local snapshot file which are neither one of the
+// newest snapshotKeepCount copies nor younger than snapshotKeepMaxAge. The newest copy is always kept.
+func cleanupLocalSnapshotHistory(filePath string) error {
+
+	historyPaths, err := getLocalSnapshotHistoryFilePaths(filePath)
+	if err != nil {
+		return err
+	}
+
+	for i, historyPath := range historyPaths {
+		if i == 0 {
+			continue
+		}
+
+		if snapshotKeepCount > 0 && i < snapshotKeepCount {
+			continue
+		}
+
+		if snapshotKeepMaxAge > 0 {
+			fileInfo, err := os.Stat(historyPath)
+			if err != nil {
+				return err
+			}
+			if time.Since(fileInfo.ModTime()) < snapshotKeepMaxAge {
+				continue
+			}
+		}
+
+		if err := os.Remove(historyPath); err != nil {
+			return err
+		}
+		log.Infof("Removed old local snapshot %s", historyPath)
+	}
+
+	return nil
+}
+
+func copyFile(sourcePath string, targetPath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0660)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(target, source)
+	if closeErr := target.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func getLocalSnapshotFileInfo(filePath string) (*LocalSnapshotFileInfo, error) {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &LocalSnapshotFileInfo{
+		FilePath: filePath,
+		Size:     fileInfo.Size(),
+		ModTime:  fileInfo.ModTime().Unix(),
+	}
+
+	header, err := readLocalSnapshotFileHeader(filePath)
+	if err != nil {
+		info.Error = err.Error()
+		return info, nil
+	}
+
+	info.MilestoneIndex = header.msIndex
+	info.MilestoneHash = header.msHash
+	info.Timestamp = header.msTimestamp
+	info.Delta = header.delta
+	info.BaseMilestoneIndex = header.baseMsIndex
+
+	return info, nil
+}
+
+// ListLocalSnapshots returns the local snapshot file of the node, its delta files and the older copies.
+func ListLocalSnapshots() ([]*LocalSnapshotFileInfo, error) {
+
+	filePath := parameter.NodeConfig.GetString("localSnapshots.path")
+
+	deltaPaths, err := getLocalSnapshotDeltaFilePaths(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	historyPaths, err := getLocalSnapshotHistoryFilePaths(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []*LocalSnapshotFileInfo{}
+	for _, path := range append(append([]string{filePath}, deltaPaths...), historyPaths...) {
+		info, err := getLocalSnapshotFileInfo(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+
+		info.Latest = path == filePath
+		snapshots = append(snapshots, info)
+	}
+
+	return snapshots, nil
+}
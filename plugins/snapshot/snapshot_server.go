@@ -0,0 +1,270 @@
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/syncutils"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/packages/shutdown"
+)
+
+const (
+	SnapshotManifestRoute = "/manifest.json"
+	SnapshotFileRoute     = "/snapshot.bin"
+
+	// the local snapshot file and its delta files are merged into this file to serve them
+	servedSnapshotFileSuffix = "_served"
+)
+
+var (
+	ErrNoLocalSnapshotFile = errors.New("no local snapshot file found")
+
+	snapshotManifestLock syncutils.Mutex
+	// the manifest of the served local snapshot file, it is recalculated if the file changed
+	cachedSnapshotManifest         *SnapshotManifest
+	cachedSnapshotManifestFilePath string
+	cachedSnapshotManifestModTime  time.Time
+)
+
+// SnapshotManifest describes a local snapshot file which is served to other nodes.
+type SnapshotManifest struct {
+	MilestoneIndex milestone_index.MilestoneIndex `json:"milestoneIndex"`
+	MilestoneHash  trinary.Hash                   `json:"milestoneHash"`
+	Timestamp      int64                          `json:"timestamp"`
+	// sha256 over the whole (compressed) file
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// getServedSnapshotFilePathWithoutLocking returns the file which is served for the given local snapshot file.
+// If delta files were written on top of the local snapshot file, the chain is merged into a separate file,
+// so that the served snapshot is at the end of the chain. The merged file is only written again if the chain changed.
+// snapshotManifestLock must be held while entering this function.
+func getServedSnapshotFilePathWithoutLocking(filePath string) (string, error) {
+
+	servedFilePath := filePath + servedSnapshotFileSuffix
+
+	deltaPaths, err := getLocalSnapshotDeltaFilePaths(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	var chain []string
+	if len(deltaPaths) > 0 {
+		header, err := readLocalSnapshotFileHeader(filePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", ErrNoLocalSnapshotFile
+			}
+			return "", err
+		}
+
+		if chain, err = getLocalSnapshotDeltaChain(filePath, header); err != nil {
+			return "", err
+		}
+	}
+
+	if len(chain) == 0 {
+		// the merged file of an older chain is not needed anymore
+		os.Remove(servedFilePath)
+		return filePath, nil
+	}
+
+	chainEndHeader, err := readLocalSnapshotFileHeader(chain[len(chain)-1])
+	if err != nil {
+		return "", err
+	}
+
+	if servedHeader, err := readLocalSnapshotFileHeader(servedFilePath); err == nil && servedHeader.msIndex == chainEndHeader.msIndex && servedHeader.msHash == chainEndHeader.msHash {
+		return servedFilePath, nil
+	}
+
+	log.Infof("Merging the local snapshot with %d delta files to serve it ...", len(chain))
+	ts := time.Now()
+
+	lsh, _, err := readLocalSnapshotChain(filePath, false)
+	if err != nil {
+		return "", err
+	}
+
+	servedFilePathTmp := servedFilePath + "_tmp"
+
+	// Remove old temp file
+	os.Remove(servedFilePathTmp)
+
+	if err := writeLocalSnapshotFile(servedFilePathTmp, lsh, localSnapshotCodec, nil); err != nil {
+		os.Remove(servedFilePathTmp)
+		return "", err
+	}
+
+	if err := os.Rename(servedFilePathTmp, servedFilePath); err != nil {
+		return "", err
+	}
+
+	log.Infof("Merging the local snapshot with %d delta files to serve it ... done, milestone: %d, took %v", len(chain), lsh.msIndex, time.Since(ts))
+	return servedFilePath, nil
+}
+
+// GetSnapshotManifest returns the manifest of the snapshot which is served for the given local snapshot file.
+// The manifest describes the end of the delta chain of the local snapshot file.
+func GetSnapshotManifest(filePath string) (*SnapshotManifest, error) {
+	snapshotManifestLock.Lock()
+	defer snapshotManifestLock.Unlock()
+
+	filePath, err := getServedSnapshotFilePathWithoutLocking(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoLocalSnapshotFile
+		}
+		return nil, err
+	}
+
+	if cachedSnapshotManifest != nil && cachedSnapshotManifestFilePath == filePath && cachedSnapshotManifest.Size == fileInfo.Size() && cachedSnapshotManifestModTime.Equal(fileInfo.ModTime()) {
+		return cachedSnapshotManifest, nil
+	}
+
+	header, err := readLocalSnapshotFileHeader(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filePath, os.O_RDONLY, 0666)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fileHash := sha256.New()
+	size, err := io.Copy(fileHash, file)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedSnapshotManifest = &SnapshotManifest{
+		MilestoneIndex: header.msIndex,
+		MilestoneHash:  header.msHash,
+		Timestamp:      header.msTimestamp,
+		SHA256:         hex.EncodeToString(fileHash.Sum(nil)),
+		Size:           size,
+	}
+	cachedSnapshotManifestFilePath = filePath
+	cachedSnapshotManifestModTime = fileInfo.ModTime()
+
+	return cachedSnapshotManifest, nil
+}
+
+// serveSnapshotManifest returns a handler which serves the manifest of the given local snapshot file.
+func serveSnapshotManifest(filePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		manifest, err := GetSnapshotManifest(filePath)
+		if err != nil {
+			if err == ErrNoLocalSnapshotFile {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			log.Warnf("Serving the local snapshot manifest failed: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(manifest)
+	}
+}
+
+// serveSnapshotFile returns a handler which serves the given local snapshot file, merged with its delta files.
+func serveSnapshotFile(filePath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshotManifestLock.Lock()
+		servedFilePath, err := getServedSnapshotFilePathWithoutLocking(filePath)
+		if err != nil {
+			snapshotManifestLock.Unlock()
+			if err == ErrNoLocalSnapshotFile {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			log.Warnf("Serving the local snapshot failed: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// the file is kept open while it is served, so it can be replaced by a new local snapshot at the same time
+		file, err := os.OpenFile(servedFilePath, os.O_RDONLY, 0666)
+		snapshotManifestLock.Unlock()
+		if err != nil {
+			if os.IsNotExist(err) {
+				http.Error(w, ErrNoLocalSnapshotFile.Error(), http.StatusNotFound)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer file.Close()
+
+		fileInfo, err := file.Stat()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		http.ServeContent(w, r, fileInfo.Name(), fileInfo.ModTime(), file)
+	}
+}
+
+func runSnapshotServer() {
+	if !parameter.NodeConfig.GetBool("snapshotServer.enabled") {
+		return
+	}
+
+	bindAddress := parameter.NodeConfig.GetString("snapshotServer.bindAddress")
+	filePath := parameter.NodeConfig.GetString("localSnapshots.path")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(SnapshotManifestRoute, serveSnapshotManifest(filePath))
+	mux.HandleFunc(SnapshotFileRoute, serveSnapshotFile(filePath))
+
+	server := &http.Server{
+		Addr:    bindAddress,
+		Handler: mux,
+	}
+
+	daemon.BackgroundWorker("Snapshot server", func(shutdownSignal <-chan struct{}) {
+		go func() {
+			log.Infof("Serving the local snapshot on http://%s%s", bindAddress, SnapshotManifestRoute)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Errorf("Stopping Snapshot server due to an error: %v", err)
+			}
+		}()
+
+		<-shutdownSignal
+		log.Info("Stopping Snapshot server ...")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := server.Shutdown(ctx); err != nil {
+			log.Error(err.Error())
+		}
+		cancel()
+
+		log.Info("Stopping Snapshot server ... done")
+	}, shutdown.ShutdownPrioritySnapshotServer)
+}
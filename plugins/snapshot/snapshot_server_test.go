@@ -0,0 +1,87 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+)
+
+func newTestLocalSnapshot(msIndex milestone_index.MilestoneIndex, hashChar string, balances map[string]uint64) *localSnapshotHeader {
+	msHash := strings.Repeat(hashChar, 81)
+	return &localSnapshotHeader{
+		msHash:              msHash,
+		msIndex:             msIndex,
+		msTimestamp:         int64(msIndex),
+		solidEntryPoints:    map[string]milestone_index.MilestoneIndex{msHash: msIndex},
+		seenMilestones:      map[string]milestone_index.MilestoneIndex{},
+		balances:            balances,
+		spentAddressesCount: int32(msIndex),
+		cuckooFilterBytes:   []byte{byte(msIndex)},
+	}
+}
+
+func TestGetSnapshotManifestServesDeltaChainEnd(t *testing.T) {
+
+	dir := testTempDir(t)
+	defer os.RemoveAll(dir)
+
+	addressA := strings.Repeat("A", 81)
+	addressB := strings.Repeat("B", 81)
+
+	base := newTestLocalSnapshot(100, "C", map[string]uint64{addressA: 10})
+	first := newTestLocalSnapshot(110, "D", map[string]uint64{addressA: 4, addressB: 6})
+	second := newTestLocalSnapshot(120, "E", map[string]uint64{addressB: 10})
+
+	filePath := filepath.Join(dir, "export.bin")
+	if err := writeLocalSnapshotFile(filePath, base, LocalSnapshotCodecGzip, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := GetSnapshotManifest(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.MilestoneIndex != 100 {
+		t.Fatalf("expected the local snapshot at milestone 100, got %d", manifest.MilestoneIndex)
+	}
+
+	for _, delta := range []*localSnapshotDelta{
+		newLocalSnapshotDelta(base, first, map[string]int64{addressA: -6, addressB: 6}),
+		newLocalSnapshotDelta(first, second, map[string]int64{addressA: -4, addressB: 4}),
+	} {
+		if err := writeLocalSnapshotFile(localSnapshotDeltaFilePath(filePath, delta.msIndex), delta, LocalSnapshotCodecGzip, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifest, err = GetSnapshotManifest(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.MilestoneIndex != 120 || manifest.MilestoneHash != second.msHash {
+		t.Fatalf("expected the end of the delta chain at milestone 120, got %d", manifest.MilestoneIndex)
+	}
+
+	// the served file is a full local snapshot at the end of the chain
+	_, served, err := readLocalSnapshot(filePath+servedSnapshotFileSuffix, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if served.msIndex != 120 || len(served.balances) != 1 || served.balances[addressB] != 10 {
+		t.Fatalf("unexpected served local snapshot at milestone %d with balances %v", served.msIndex, served.balances)
+	}
+
+	// the merged file is removed again once the deltas are merged into the local snapshot file
+	if err := removeLocalSnapshotDeltaFiles(filePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetSnapshotManifest(filePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filePath + servedSnapshotFileSuffix); !os.IsNotExist(err) {
+		t.Fatalf("the merged file was not removed: %v", err)
+	}
+}
@@ -0,0 +1,424 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/packages/compressed"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/packages/toolset"
+)
+
+func init() {
+	toolset.Register("snapshot info", "prints the header of a local snapshot file, checks its integrity and verifies the total supply", runSnapshotInfoTool)
+	toolset.Register("snapshot solid-entry-points", "lists the solid entry points of a local snapshot file", runSnapshotSolidEntryPointsTool)
+	toolset.Register("snapshot seen-milestones", "lists the seen milestones of a local snapshot file", runSnapshotSeenMilestonesTool)
+	toolset.Register("snapshot balances", "exports the balances of a local snapshot file to a CSV or NDJSON file", runSnapshotBalancesTool)
+	toolset.Register("snapshot diff", "shows the balance changes and the added or removed solid entry points between two local snapshot files", runSnapshotDiffTool)
+	toolset.Register("snapshot compact", "merges the delta files into the local snapshot file", runSnapshotCompactTool)
+}
+
+type snapshotFileInfoDump struct {
+	*snapshotFileHeaderDump
+	SolidEntryPointsCount int    `json:"solidEntryPointsCount"`
+	SeenMilestonesCount   int    `json:"seenMilestonesCount"`
+	BalancesCount         int    `json:"balancesCount"`
+	SpentAddressesCount   int32  `json:"spentAddressesCount"`
+	Supply                uint64 `json:"supply"`
+	SupplyValid           bool   `json:"supplyValid"`
+	// the error which prevented reading the entries, empty if they were read
+	ReadError string `json:"readError,omitempty"`
+}
+
+type snapshotDeltaInfoDump struct {
+	*snapshotFileHeaderDump
+	SolidEntryPointsCount        int   `json:"solidEntryPointsCount"`
+	RemovedSolidEntryPointsCount int   `json:"removedSolidEntryPointsCount"`
+	SeenMilestonesCount          int   `json:"seenMilestonesCount"`
+	LedgerDiffCount              int   `json:"ledgerDiffCount"`
+	SpentAddressesCount          int32 `json:"spentAddressesCount"`
+	SpentAddressesPatchCount     int   `json:"spentAddressesPatchCount"`
+	// the sum of the ledger diff has to be zero
+	LedgerDiffValid bool `json:"ledgerDiffValid"`
+	// the error which prevented reading the entries, empty if they were read
+	ReadError string `json:"readError,omitempty"`
+}
+
+type snapshotMilestoneIndexEntryDump struct {
+	Hash  string `json:"hash"`
+	Index uint32 `json:"index"`
+}
+
+type snapshotBalanceChangeDump struct {
+	Address string `json:"address"`
+	From    uint64 `json:"from"`
+	To      uint64 `json:"to"`
+	Diff    int64  `json:"diff"`
+}
+
+type snapshotDiffDump struct {
+	FromMilestoneIndex      uint32                             `json:"fromMilestoneIndex"`
+	ToMilestoneIndex        uint32                             `json:"toMilestoneIndex"`
+	BalanceChanges          []*snapshotBalanceChangeDump       `json:"balanceChanges"`
+	AddedSolidEntryPoints   []*snapshotMilestoneIndexEntryDump `json:"addedSolidEntryPoints"`
+	RemovedSolidEntryPoints []*snapshotMilestoneIndexEntryDump `json:"removedSolidEntryPoints"`
+}
+
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func parseSnapshotFileArg(name string, args []string) (string, error) {
+	flagSet := toolset.NewFlagSet(name)
+	filePath := flagSet.String("file", parameter.NodeConfig.GetString("localSnapshots.path"), "path of the local snapshot file")
+	if err := flagSet.Parse(args); err != nil {
+		return "", err
+	}
+	return *filePath, nil
+}
+
+// dumpMilestoneIndexEntries returns the entries sorted by milestone index and hash.
+func dumpMilestoneIndexEntries(entries map[string]milestone_index.MilestoneIndex) []*snapshotMilestoneIndexEntryDump {
+	dump := []*snapshotMilestoneIndexEntryDump{}
+	for hash, index := range entries {
+		dump = append(dump, &snapshotMilestoneIndexEntryDump{Hash: hash, Index: uint32(index)})
+	}
+
+	sort.Slice(dump, func(i, j int) bool {
+		if dump[i].Index != dump[j].Index {
+			return dump[i].Index < dump[j].Index
+		}
+		return dump[i].Hash < dump[j].Hash
+	})
+	return dump
+}
+
+func sortedBalanceAddresses(balances map[string]uint64) []trinary.Hash {
+	addresses := make([]trinary.Hash, 0, len(balances))
+	for address := range balances {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+func runSnapshotInfoTool(args []string) error {
+
+	filePath, err := parseSnapshotFileArg("snapshot info", args)
+	if err != nil {
+		return err
+	}
+
+	// the headers are printed even if the file is not supported or corrupted,
+	// the entries are only read if no problem was found in the file
+	headerDump, inspectErr := inspectLocalSnapshotFile(filePath)
+	if headerDump == nil {
+		return inspectErr
+	}
+
+	if headerDump.Delta {
+		return printSnapshotDeltaInfo(filePath, headerDump, inspectErr)
+	}
+
+	dump := &snapshotFileInfoDump{snapshotFileHeaderDump: headerDump}
+
+	if inspectErr == nil {
+		if _, lsh, err := readLocalSnapshot(filePath, false); err != nil {
+			inspectErr = err
+			dump.ReadError = err.Error()
+		} else {
+			dump.SolidEntryPointsCount = len(lsh.solidEntryPoints)
+			dump.SeenMilestonesCount = len(lsh.seenMilestones)
+			dump.BalancesCount = len(lsh.balances)
+			dump.SpentAddressesCount = lsh.spentAddressesCount
+
+			for _, balance := range lsh.balances {
+				dump.Supply += balance
+			}
+			dump.SupplyValid = dump.Supply == compressed.TOTAL_SUPPLY
+		}
+	}
+
+	if err := printJSON(dump); err != nil {
+		return err
+	}
+
+	if inspectErr != nil {
+		return inspectErr
+	}
+	if !dump.SupplyValid {
+		return errors.Wrapf(ErrLedgerSupplyMismatch, "%d != %d", dump.Supply, compressed.TOTAL_SUPPLY)
+	}
+	return nil
+}
+
+func printSnapshotDeltaInfo(filePath string, headerDump *snapshotFileHeaderDump, inspectErr error) error {
+
+	dump := &snapshotDeltaInfoDump{snapshotFileHeaderDump: headerDump}
+
+	var ledgerDiffSum int64
+	if inspectErr == nil {
+		if delta, err := readLocalSnapshotDelta(filePath); err != nil {
+			inspectErr = err
+			dump.ReadError = err.Error()
+		} else {
+			dump.SolidEntryPointsCount = len(delta.solidEntryPoints)
+			dump.RemovedSolidEntryPointsCount = len(delta.removedSolidEntryPoints)
+			dump.SeenMilestonesCount = len(delta.seenMilestones)
+			dump.LedgerDiffCount = len(delta.ledgerDiff)
+			dump.SpentAddressesCount = delta.spentAddressesCount
+			dump.SpentAddressesPatchCount = len(delta.cuckooFilterPatches)
+
+			for _, change := range delta.ledgerDiff {
+				ledgerDiffSum += change
+			}
+			dump.LedgerDiffValid = ledgerDiffSum == 0
+		}
+	}
+
+	if err := printJSON(dump); err != nil {
+		return err
+	}
+
+	if inspectErr != nil {
+		return inspectErr
+	}
+	if !dump.LedgerDiffValid {
+		return fmt.Errorf("ledger diff of the delta doesn't sum up to zero: %d", ledgerDiffSum)
+	}
+	return nil
+}
+
+func runSnapshotSolidEntryPointsTool(args []string) error {
+
+	filePath, err := parseSnapshotFileArg("snapshot solid-entry-points", args)
+	if err != nil {
+		return err
+	}
+
+	_, lsh, err := readLocalSnapshot(filePath, false)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(dumpMilestoneIndexEntries(lsh.solidEntryPoints))
+}
+
+func runSnapshotSeenMilestonesTool(args []string) error {
+
+	filePath, err := parseSnapshotFileArg("snapshot seen-milestones", args)
+	if err != nil {
+		return err
+	}
+
+	_, lsh, err := readLocalSnapshot(filePath, false)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(dumpMilestoneIndexEntries(lsh.seenMilestones))
+}
+
+func runSnapshotBalancesTool(args []string) error {
+
+	flagSet := toolset.NewFlagSet("snapshot balances")
+	filePath := flagSet.String("file", parameter.NodeConfig.GetString("localSnapshots.path"), "path of the local snapshot file")
+	format := flagSet.String("format", LedgerExportFormatCSV, "format of the export (csv or ndjson)")
+	outputPath := flagSet.String("output", "", "path of the export file (default \"snapshot_balances.<format>\")")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != LedgerExportFormatCSV && *format != LedgerExportFormatNDJSON {
+		return errors.Wrapf(ErrUnknownLedgerExportFormat, "%s", *format)
+	}
+
+	if *outputPath == "" {
+		*outputPath = "snapshot_balances." + *format
+	}
+
+	header, lsh, err := readLocalSnapshot(*filePath, false)
+	if err != nil {
+		return err
+	}
+
+	outputPathTmp := *outputPath + "_tmp"
+
+	// Remove old temp file
+	os.Remove(outputPathTmp)
+
+	exportFile, err := os.OpenFile(outputPathTmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	result := &LedgerExportResult{
+		MilestoneIndex: header.msIndex,
+		MilestoneHash:  header.msHash,
+	}
+
+	// the balances are exported in ascending address order, like the ledger export of the database
+	forEachBalance := func(consumer func(address trinary.Hash, balance uint64) bool) error {
+		for _, address := range sortedBalanceAddresses(lsh.balances) {
+			if consumer(address, lsh.balances[address]) {
+				break
+			}
+		}
+		return nil
+	}
+
+	exportErr := writeLedgerExport(exportFile, *format, result, forEachBalance, nil)
+	if closeErr := exportFile.Close(); closeErr != nil {
+		return closeErr
+	}
+
+	// a supply mismatch is reported, but the export is kept to be able to inspect it
+	if exportErr != nil && errors.Cause(exportErr) != ErrLedgerSupplyMismatch {
+		os.Remove(outputPathTmp)
+		return exportErr
+	}
+
+	if err := os.Rename(outputPathTmp, *outputPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %d addresses at milestone %d (%s) to %s, supply: %d, checksum: %s\n", result.AddressCount, result.MilestoneIndex, result.MilestoneHash, *outputPath, result.Supply, result.Checksum)
+	return exportErr
+}
+
+func runSnapshotDiffTool(args []string) error {
+
+	flagSet := toolset.NewFlagSet("snapshot diff")
+	fromPath := flagSet.String("from", "", "path of the older local snapshot file")
+	toPath := flagSet.String("to", parameter.NodeConfig.GetString("localSnapshots.path"), "path of the newer local snapshot file")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if *fromPath == "" {
+		return errors.New("no local snapshot file to compare with given, use --from <path>")
+	}
+
+	fromHeader, fromLsh, err := readLocalSnapshot(*fromPath, false)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", *fromPath)
+	}
+
+	toHeader, toLsh, err := readLocalSnapshot(*toPath, false)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", *toPath)
+	}
+
+	dump := &snapshotDiffDump{
+		FromMilestoneIndex:      uint32(fromHeader.msIndex),
+		ToMilestoneIndex:        uint32(toHeader.msIndex),
+		BalanceChanges:          []*snapshotBalanceChangeDump{},
+		AddedSolidEntryPoints:   []*snapshotMilestoneIndexEntryDump{},
+		RemovedSolidEntryPoints: []*snapshotMilestoneIndexEntryDump{},
+	}
+
+	addresses := make(map[string]uint64)
+	for address, balance := range fromLsh.balances {
+		addresses[address] = balance
+	}
+	for address, balance := range toLsh.balances {
+		addresses[address] = balance
+	}
+
+	for _, address := range sortedBalanceAddresses(addresses) {
+		fromBalance := fromLsh.balances[address]
+		toBalance := toLsh.balances[address]
+		if fromBalance == toBalance {
+			continue
+		}
+
+		dump.BalanceChanges = append(dump.BalanceChanges, &snapshotBalanceChangeDump{
+			Address: address,
+			From:    fromBalance,
+			To:      toBalance,
+			Diff:    int64(toBalance) - int64(fromBalance),
+		})
+	}
+
+	added := make(map[string]milestone_index.MilestoneIndex)
+	for hash, index := range toLsh.solidEntryPoints {
+		if _, exists := fromLsh.solidEntryPoints[hash]; !exists {
+			added[hash] = index
+		}
+	}
+	dump.AddedSolidEntryPoints = dumpMilestoneIndexEntries(added)
+
+	removed := make(map[string]milestone_index.MilestoneIndex)
+	for hash, index := range fromLsh.solidEntryPoints {
+		if _, exists := toLsh.solidEntryPoints[hash]; !exists {
+			removed[hash] = index
+		}
+	}
+	dump.RemovedSolidEntryPoints = dumpMilestoneIndexEntries(removed)
+
+	return printJSON(dump)
+}
+
+func runSnapshotCompactTool(args []string) error {
+
+	flagSet := toolset.NewFlagSet("snapshot compact")
+	filePath := flagSet.String("file", parameter.NodeConfig.GetString("localSnapshots.path"), "path of the local snapshot file")
+	outputPath := flagSet.String("output", "", "path of the merged local snapshot file (default: replaces the local snapshot file and removes the delta files)")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if *outputPath == "" {
+		*outputPath = *filePath
+	}
+
+	codec, err := LocalSnapshotCodecFromString(parameter.NodeConfig.GetString("localSnapshots.codec"))
+	if err != nil {
+		return err
+	}
+
+	lsh, deltaPaths, err := readLocalSnapshotChain(*filePath, false)
+	if err != nil {
+		return err
+	}
+
+	if len(deltaPaths) == 0 && *outputPath == *filePath {
+		fmt.Printf("No delta files found for %s\n", *filePath)
+		return nil
+	}
+
+	outputPathTmp := *outputPath + "_tmp"
+
+	// Remove old temp file
+	os.Remove(outputPathTmp)
+
+	if err := writeLocalSnapshotFile(outputPathTmp, lsh, codec, nil); err != nil {
+		os.Remove(outputPathTmp)
+		return err
+	}
+
+	if err := os.Rename(outputPathTmp, *outputPath); err != nil {
+		return err
+	}
+
+	// the delta files are included in the replaced local snapshot file now
+	if *outputPath == *filePath {
+		for _, deltaPath := range deltaPaths {
+			if err := os.Remove(deltaPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("Merged %d delta files into %s, milestone: %d\n", len(deltaPaths), *outputPath, lsh.msIndex)
+	return nil
+}
@@ -197,6 +197,7 @@ type nodestatus struct {
 	ServerMetrics      *servermetrics                 `json:"server_metrics"`
 	Mem                *memmetrics                    `json:"mem"`
 	Caches             *cachesmetric                  `json:"caches"`
+	Database           *metrics.DatabaseMetrics       `json:"database"`
 }
 
 type servermetrics struct {
@@ -294,6 +295,7 @@ func currentNodeStatus() *nodestatus {
 	status.MsRequestQueueSize = requestCount
 	status.CurrentRequestedMs = requestedMilestone
 	status.RequestQueueSize = requestCount
+	status.Database = metrics.GetDatabaseMetrics()
 
 	// cache metrics
 	reqQueueCache := gossip.RequestQueue.GetCache()
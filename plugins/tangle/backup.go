@@ -0,0 +1,326 @@
+package tangle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	hornetDB "github.com/gohornet/hornet/packages/database"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/packages/shutdown"
+	"github.com/gohornet/hornet/packages/toolset"
+)
+
+var (
+	ErrBackupAlreadyRunning = errors.New("a backup is already running")
+	ErrBackupChainBroken    = errors.New("backups don't build on each other")
+	ErrBackupInconsistent   = errors.New("restored ledger state doesn't belong to the milestone of the backup")
+
+	backupLock     sync.Mutex
+	backupRunning  bool
+	backupPath     string
+	backupInterval time.Duration
+	// scheduled backups are incremental on top of the latest backup
+	backupIncremental bool
+)
+
+func init() {
+	toolset.Register("db restore", "restores the database from a full backup and optional incremental backups, given in the order they were created", runDatabaseRestoreTool)
+}
+
+// BackupInfo is stored next to every backup file.
+type BackupInfo struct {
+	FilePath       string                         `json:"filePath"`
+	MilestoneIndex milestone_index.MilestoneIndex `json:"milestoneIndex"`
+	// the badger version of the backup, the next incremental backup contains all changes after it
+	Version uint64 `json:"version"`
+	// the version of the backup this incremental backup builds on, 0 for full backups
+	Since           uint64 `json:"since"`
+	Timestamp       int64  `json:"timestamp"`
+	DatabaseVersion byte   `json:"databaseVersion"`
+}
+
+func configureBackup() {
+	backupPath = parameter.NodeConfig.GetString("backup.path")
+	backupInterval = time.Duration(parameter.NodeConfig.GetInt("backup.intervalMinutes")) * time.Minute
+	backupIncremental = parameter.NodeConfig.GetBool("backup.incremental")
+}
+
+func runBackup() {
+	if backupInterval == 0 {
+		return
+	}
+
+	daemon.BackgroundWorker("Database Backup", func(shutdownSignal <-chan struct{}) {
+		log.Infof("Starting Database Backup ... done, interval: %v", backupInterval)
+
+		timeutil.Ticker(func() {
+			if _, err := CreateBackup(backupIncremental, shutdownSignal); err != nil {
+				log.Errorf("Database backup failed: %v", err)
+			}
+		}, backupInterval, shutdownSignal)
+
+		log.Info("Stopping Database Backup ... done")
+	}, shutdown.ShutdownPriorityDatabaseBackup)
+}
+
+func backupInfoFilePath(backupFilePath string) string {
+	return backupFilePath + ".json"
+}
+
+// GetLatestBackupInfo returns the info of the newest backup in the backup folder, or nil if there is none.
+func GetLatestBackupInfo() (*BackupInfo, error) {
+
+	infoFiles, err := filepath.Glob(filepath.Join(backupPath, "*.bak.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var latest *BackupInfo
+	for _, infoFile := range infoFiles {
+		info, err := readBackupInfo(infoFile)
+		if err != nil {
+			return nil, err
+		}
+		if latest == nil || info.Version > latest.Version {
+			latest = info
+		}
+	}
+
+	return latest, nil
+}
+
+func readBackupInfo(infoFilePath string) (*BackupInfo, error) {
+	data, err := ioutil.ReadFile(infoFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &BackupInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, errors.Wrapf(err, "invalid backup info %s", infoFilePath)
+	}
+	return info, nil
+}
+
+// CreateBackup writes a backup of the database while the node is running.
+// The ledger is read locked until the snapshot of the backup was taken, so that the ledger state, the ledger diffs
+// and the balance history in the backup all belong to the current solid milestone. The caches are flushed to
+// the database while the ledger is locked, so that the confirmed transactions, bundles, milestones and
+// approvers are part of the backup as well. The confirmed milestone index is stored in the backup and
+// checked when the backup is restored.
+// An incremental backup only contains the changes since the latest backup in the backup folder.
+func CreateBackup(incremental bool, abortSignal <-chan struct{}) (*BackupInfo, error) {
+
+	// only one backup at a time, a second one would only duplicate the work
+	backupLock.Lock()
+	if backupRunning {
+		backupLock.Unlock()
+		return nil, ErrBackupAlreadyRunning
+	}
+	backupRunning = true
+	backupLock.Unlock()
+
+	defer func() {
+		backupLock.Lock()
+		backupRunning = false
+		backupLock.Unlock()
+	}()
+
+	if err := os.MkdirAll(backupPath, 0700); err != nil {
+		return nil, err
+	}
+
+	var since uint64
+	if incremental {
+		latest, err := GetLatestBackupInfo()
+		if err != nil {
+			return nil, err
+		}
+		if latest != nil {
+			since = latest.Version
+		}
+	}
+
+	dbVersion, err := tangle.GetDatabaseVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	// the ledger is unlocked as soon as the snapshot of the backup was taken
+	var unlockLedgerOnce sync.Once
+	unlockLedger := func() { unlockLedgerOnce.Do(tangle.ReadUnlockLedger) }
+
+	tangle.ReadLockLedger()
+	defer unlockLedger()
+
+	// the metadata of the transactions confirmed by the ledger milestone may only be held in the caches
+	tangle.FlushMilestoneCache()
+	tangle.FlushBundleCache()
+	tangle.FlushTransactionCache()
+	tangle.FlushApproversCache()
+
+	info := &BackupInfo{
+		MilestoneIndex:  tangle.GetLedgerMilestoneIndexWithoutLocking(),
+		Since:           since,
+		Timestamp:       time.Now().Unix(),
+		DatabaseVersion: dbVersion,
+	}
+
+	backupType := "full"
+	if since != 0 {
+		backupType = "incremental"
+	}
+	info.FilePath = filepath.Join(backupPath, fmt.Sprintf("backup_%d_%d_%s.bak", info.MilestoneIndex, info.Timestamp, backupType))
+
+	if err := tangle.StoreBackupMilestoneIndex(info.MilestoneIndex); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Creating %s database backup at milestone %d ...", backupType, info.MilestoneIndex)
+	ts := time.Now()
+
+	version, err := hornetDB.BackupToFile(info.FilePath, since, abortSignal, unlockLedger)
+	if err != nil {
+		return nil, err
+	}
+
+	// an incremental backup without any changes doesn't contain a version
+	info.Version = version
+	if info.Version < since {
+		info.Version = since
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(backupInfoFilePath(info.FilePath), data, 0666); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Creating %s database backup at milestone %d ... done, took %v, file: %s", backupType, info.MilestoneIndex, time.Since(ts), info.FilePath)
+	return info, nil
+}
+
+// CreateBackupInBackground writes a backup of the database in a background worker of the node and waits for it,
+// so that the backup is only aborted by the shutdown of the node and not by the caller.
+func CreateBackupInBackground(incremental bool) (*BackupInfo, error) {
+
+	type backupResult struct {
+		info *BackupInfo
+		err  error
+	}
+
+	resultChan := make(chan *backupResult, 1)
+	if err := daemon.BackgroundWorker("Database Backup (manual)", func(shutdownSignal <-chan struct{}) {
+		info, err := CreateBackup(incremental, shutdownSignal)
+		resultChan <- &backupResult{info: info, err: err}
+	}, shutdown.ShutdownPriorityDatabaseBackup); err != nil {
+		return nil, err
+	}
+
+	result := <-resultChan
+	return result.info, result.err
+}
+
+func runDatabaseRestoreTool(args []string) error {
+
+	flagSet := toolset.NewFlagSet("db restore")
+	files := flagSet.StringSlice("file", nil, "backup file to restore, can be given multiple times, the full backup first")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if len(*files) == 0 {
+		return errors.New("no backup file given, use --file <path>")
+	}
+
+	log = logger.NewLogger("Tangle")
+
+	// check that the incremental backups build on each other, if the infos are available
+	var previous *BackupInfo
+	for i, file := range *files {
+		info, err := readBackupInfo(backupInfoFilePath(file))
+		if err != nil {
+			if os.IsNotExist(errors.Cause(err)) {
+				log.Warnf("No backup info found for %s, the order of the backups can't be checked", file)
+				previous = nil
+				continue
+			}
+			return err
+		}
+
+		if i == 0 && info.Since != 0 {
+			return errors.Wrapf(ErrBackupChainBroken, "%s is an incremental backup, the full backup has to be restored first", file)
+		}
+		if previous != nil && info.Since != previous.Version {
+			return errors.Wrapf(ErrBackupChainBroken, "%s builds on version %d, but the previous backup has version %d", file, info.Since, previous.Version)
+		}
+		previous = info
+	}
+
+	if err := toolset.OpenEmptyDatabase(); err != nil {
+		return err
+	}
+
+	restored := false
+	defer func() { toolset.CloseDatabaseAfterRepair(restored) }()
+
+	for _, file := range *files {
+		log.Infof("Restoring database backup %s ...", file)
+		if err := hornetDB.RestoreFromFile(file); err != nil {
+			return errors.Wrapf(err, "failed to restore %s", file)
+		}
+		log.Infof("Restoring database backup %s ... done", file)
+	}
+
+	if err := checkRestoredBackup(previous); err != nil {
+		return err
+	}
+
+	// the backup was taken while the node was running, so it contains the corrupted flag
+	restored = true
+	return nil
+}
+
+// checkRestoredBackup checks that the restored ledger state belongs to the confirmed milestone stored in the last backup.
+// lastInfo is the info of the last restored backup, or nil if it is not available.
+func checkRestoredBackup(lastInfo *BackupInfo) error {
+
+	backupMilestoneIndex, exists, err := tangle.GetBackupMilestoneIndex()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Warn("The backup contains no milestone index, the restored ledger state can't be checked")
+		return nil
+	}
+
+	if lastInfo != nil && lastInfo.MilestoneIndex != backupMilestoneIndex {
+		return errors.Wrapf(ErrBackupInconsistent, "the backup info belongs to milestone %d, but the backup to milestone %d", lastInfo.MilestoneIndex, backupMilestoneIndex)
+	}
+
+	ledgerMilestoneIndex, err := tangle.GetStoredLedgerMilestoneIndex()
+	if err != nil {
+		return err
+	}
+	if ledgerMilestoneIndex != backupMilestoneIndex {
+		return errors.Wrapf(ErrBackupInconsistent, "ledger milestone %d, backup milestone %d", ledgerMilestoneIndex, backupMilestoneIndex)
+	}
+
+	log.Infof("Restored ledger state at milestone %d", ledgerMilestoneIndex)
+	return nil
+}
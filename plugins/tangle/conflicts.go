@@ -0,0 +1,271 @@
+package tangle
+
+import (
+	"time"
+
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/syncutils"
+	"github.com/iotaledger/hive.go/workerpool"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/shutdown"
+)
+
+const (
+	// conflicts are forgotten if no new spend was seen for this amount of milestones
+	conflictRetentionMilestones = 100
+
+	// maximum amount of transactions per address loaded from the database to find former spends
+	conflictMaxAddressTxs = 1000
+)
+
+var (
+	conflictDetectorWorkerCount = 1
+	conflictDetectorQueueSize   = 10000
+	conflictDetectorWorkerPool  *workerpool.WorkerPool
+
+	// spends of all addresses with recently seen inputs
+	addressSpends     = make(map[trinary.Hash]*addressSpendSet)
+	addressSpendsLock syncutils.RWMutex
+)
+
+// ConflictingSpend is a bundle which spends from the address of a conflict.
+type ConflictingSpend struct {
+	BundleHash trinary.Hash
+	// The amount spent from the address
+	Value int64
+	// Unix time when the spend was seen first, the first spend of an address is the legitimate one
+	FirstSeen int64
+}
+
+// Conflict is a group of bundles with different bundle hashes which spend from the same address.
+type Conflict struct {
+	Address    trinary.Hash
+	Spends     []*ConflictingSpend
+	DetectedAt int64
+}
+
+func ConflictCaller(handler interface{}, params ...interface{}) {
+	handler.(func(conflict *Conflict))(params[0].(*Conflict))
+}
+
+type addressSpendSet struct {
+	spends     map[trinary.Hash]*ConflictingSpend
+	detectedAt int64
+	lastSeen   milestone_index.MilestoneIndex
+	// the former spends of the address were loaded from the database or are queued to be loaded
+	formerSpendsQueued bool
+}
+
+func (s *addressSpendSet) toConflict(address trinary.Hash) *Conflict {
+	conflict := &Conflict{Address: address, DetectedAt: s.detectedAt}
+	for _, spend := range s.spends {
+		spendCopy := *spend
+		conflict.Spends = append(conflict.Spends, &spendCopy)
+	}
+	return conflict
+}
+
+func configureConflictDetector() {
+	conflictDetectorWorkerPool = workerpool.New(func(task workerpool.Task) {
+		detectFormerSpends(task.Param(0).(trinary.Hash))
+		task.Return(nil)
+	}, workerpool.WorkerCount(conflictDetectorWorkerCount), workerpool.QueueSize(conflictDetectorQueueSize))
+}
+
+func runConflictDetector() {
+
+	notifySolidMilestoneChanged := events.NewClosure(func(msBundle *tangle.Bundle) {
+		cleanupAddressSpends(msBundle.GetMilestoneIndex())
+	})
+
+	daemon.BackgroundWorker("ConflictDetector", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting ConflictDetector ... done")
+		Events.SolidMilestoneChanged.Attach(notifySolidMilestoneChanged)
+		conflictDetectorWorkerPool.Start()
+		<-shutdownSignal
+		log.Info("Stopping ConflictDetector ...")
+		Events.SolidMilestoneChanged.Detach(notifySolidMilestoneChanged)
+		conflictDetectorWorkerPool.StopAndWait()
+		log.Info("Stopping ConflictDetector ... done")
+	}, shutdown.ShutdownPriorityConflictDetector)
+}
+
+// GetConflicts returns all currently known conflicts.
+func GetConflicts() []*Conflict {
+	addressSpendsLock.RLock()
+	defer addressSpendsLock.RUnlock()
+
+	var conflicts []*Conflict
+	for address, spendSet := range addressSpends {
+		if len(spendSet.spends) < 2 {
+			continue
+		}
+		conflicts = append(conflicts, spendSet.toConflict(address))
+	}
+	return conflicts
+}
+
+// detectConflicts checks whether the inputs of the given bundle were already spent by other bundles.
+// It only uses the spends in memory, the former spends of an address are loaded from the database by the conflict detector worker.
+func detectConflicts(bundle *tangle.Bundle, firstSeenLatestMilestoneIndex milestone_index.MilestoneIndex) {
+
+	ledgerChanges, _ := bundle.GetLedgerChanges()
+	bundleHash := bundle.GetHash()
+	now := time.Now().Unix()
+
+	for address, change := range ledgerChanges {
+		if change >= 0 {
+			continue
+		}
+
+		addSpends(address, []*ConflictingSpend{{BundleHash: bundleHash, Value: -change, FirstSeen: now}}, firstSeenLatestMilestoneIndex, false)
+	}
+}
+
+// detectFormerSpends adds the spends of the address which are stored in the database, e.g. from before the node was restarted.
+func detectFormerSpends(address trinary.Hash) {
+
+	txHashes, err := tangle.ReadTransactionHashesForAddressFromDatabase(address, conflictMaxAddressTxs)
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	var spends []*ConflictingSpend
+	for _, txHash := range txHashes {
+		tx, err := tangle.GetTransaction(txHash)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		if tx == nil || tx.Tx.Value >= 0 {
+			continue
+		}
+
+		firstSeen := int64(tx.GetSolidificationTimestamp())
+		if firstSeen == 0 {
+			firstSeen = tx.GetTimestamp()
+		}
+		spends = append(spends, &ConflictingSpend{BundleHash: tx.Tx.Bundle, Value: -tx.Tx.Value, FirstSeen: firstSeen})
+	}
+
+	addSpends(address, spends, 0, true)
+}
+
+// addSpends adds the spends to the spend set of the address and reports a conflict if a new bundle spends from the address.
+func addSpends(address trinary.Hash, spends []*ConflictingSpend, firstSeenLatestMilestoneIndex milestone_index.MilestoneIndex, formerSpends bool) {
+
+	addressSpendsLock.Lock()
+	spendSet, exists := addressSpends[address]
+	if !exists {
+		spendSet = &addressSpendSet{spends: make(map[trinary.Hash]*ConflictingSpend)}
+		addressSpends[address] = spendSet
+	}
+	if firstSeenLatestMilestoneIndex > spendSet.lastSeen {
+		spendSet.lastSeen = firstSeenLatestMilestoneIndex
+	}
+
+	if formerSpends {
+		spendSet.formerSpendsQueued = true
+	} else if !spendSet.formerSpendsQueued {
+		// former spends may have been persisted before the node was restarted.
+		// if the queue is full, the spends are loaded with the next spend of the address.
+		_, spendSet.formerSpendsQueued = conflictDetectorWorkerPool.TrySubmit(address)
+	}
+
+	// reattachments have the same bundle hash and are no conflicts
+	spendCountBefore := len(spendSet.spends)
+	for _, spend := range spends {
+		existingSpend, has := spendSet.spends[spend.BundleHash]
+		if !has {
+			spendSet.spends[spend.BundleHash] = spend
+			continue
+		}
+		if spend.FirstSeen < existingSpend.FirstSeen {
+			existingSpend.FirstSeen = spend.FirstSeen
+		}
+	}
+
+	if len(spendSet.spends) < 2 || len(spendSet.spends) == spendCountBefore {
+		addressSpendsLock.Unlock()
+		return
+	}
+
+	if spendSet.detectedAt == 0 {
+		spendSet.detectedAt = time.Now().Unix()
+	}
+	conflict := spendSet.toConflict(address)
+	addressSpendsLock.Unlock()
+
+	markConflictingBundles(conflict)
+
+	log.Warnf("Conflicting spends detected for address %s: %d bundles", address, len(conflict.Spends))
+	Events.ConflictDetected.Trigger(conflict)
+}
+
+// markConflictingBundles flags the unconfirmed bundles of all spends except the legitimate one as conflicting.
+// The legitimate spend is the confirmed one, or the first seen spend if none of them is confirmed yet.
+func markConflictingBundles(conflict *Conflict) {
+
+	bundleBuckets := make(map[trinary.Hash]*tangle.BundleBucket)
+	var legitimateSpend *ConflictingSpend
+	for _, spend := range conflict.Spends {
+		bundleBucket, err := tangle.GetBundleBucket(spend.BundleHash)
+		if err != nil || bundleBucket == nil {
+			continue
+		}
+		bundleBuckets[spend.BundleHash] = bundleBucket
+
+		if len(bundleBucket.GetConfirmed()) > 0 {
+			legitimateSpend = spend
+			break
+		}
+	}
+
+	if legitimateSpend == nil {
+		for _, spend := range conflict.Spends {
+			if legitimateSpend == nil || spend.FirstSeen < legitimateSpend.FirstSeen ||
+				(spend.FirstSeen == legitimateSpend.FirstSeen && spend.BundleHash < legitimateSpend.BundleHash) {
+				legitimateSpend = spend
+			}
+		}
+	}
+
+	for _, spend := range conflict.Spends {
+		if spend.BundleHash == legitimateSpend.BundleHash {
+			continue
+		}
+
+		bundleBucket, has := bundleBuckets[spend.BundleHash]
+		if !has {
+			var err error
+			if bundleBucket, err = tangle.GetBundleBucket(spend.BundleHash); err != nil || bundleBucket == nil {
+				continue
+			}
+		}
+
+		for _, bndl := range bundleBucket.Bundles() {
+			if !bndl.IsComplete() || bndl.IsConfirmed() || bndl.IsConflicting() {
+				continue
+			}
+			bndl.SetConflicting(true)
+		}
+	}
+}
+
+// cleanupAddressSpends removes the spends of addresses which were not used for a while.
+func cleanupAddressSpends(solidMilestoneIndex milestone_index.MilestoneIndex) {
+	addressSpendsLock.Lock()
+	defer addressSpendsLock.Unlock()
+
+	for address, spendSet := range addressSpends {
+		if spendSet.lastSeen+conflictRetentionMilestones < solidMilestoneIndex {
+			delete(addressSpends, address)
+		}
+	}
+}
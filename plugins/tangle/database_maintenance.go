@@ -0,0 +1,162 @@
+package tangle
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/timeutil"
+
+	hornetDB "github.com/gohornet/hornet/packages/database"
+	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/packages/shutdown"
+)
+
+var (
+	ErrCompactionAlreadyRunning = errors.New("a database compaction is already running")
+
+	valueLogGCInterval     time.Duration
+	valueLogGCDiscardRatio float64
+	valueLogGCAfterPruning bool
+
+	compactionLock   sync.Mutex
+	compactionStatus = &CompactionStatus{}
+)
+
+// CompactionResult contains the size of the database before and after a compaction.
+type CompactionResult struct {
+	LSMSizeBefore  int64                             `json:"lsmSizeBefore"`
+	LSMSizeAfter   int64                             `json:"lsmSizeAfter"`
+	VlogSizeBefore int64                             `json:"vlogSizeBefore"`
+	VlogSizeAfter  int64                             `json:"vlogSizeAfter"`
+	GCResult       *hornetDB.GarbageCollectionResult `json:"gcResult"`
+	// the duration of the compaction and the garbage collection in milliseconds
+	Duration int64 `json:"duration"`
+}
+
+// CompactionStatus contains the state of the current compaction and the result of the last one.
+type CompactionStatus struct {
+	Running   bool  `json:"running"`
+	StartTime int64 `json:"startTime"`
+	// the result of the last finished compaction
+	LastResult *CompactionResult `json:"lastResult"`
+	LastError  string            `json:"lastError"`
+}
+
+func configureDatabaseMaintenance() {
+	valueLogGCInterval = time.Duration(parameter.NodeConfig.GetInt("db.valueLogGCIntervalMinutes")) * time.Minute
+	valueLogGCAfterPruning = parameter.NodeConfig.GetBool("db.valueLogGCAfterPruning")
+
+	valueLogGCDiscardRatio = parameter.NodeConfig.GetFloat64("db.valueLogGCDiscardRatio")
+	if valueLogGCDiscardRatio <= 0 || valueLogGCDiscardRatio >= 1 {
+		log.Warnf("Parameter \"db.valueLogGCDiscardRatio\" is invalid (%v). Value was changed to 0.5", valueLogGCDiscardRatio)
+		valueLogGCDiscardRatio = 0.5
+	}
+}
+
+func runDatabaseMaintenance() {
+	if valueLogGCInterval == 0 {
+		return
+	}
+
+	daemon.BackgroundWorker("Badger garbage collection", func(shutdownSignal <-chan struct{}) {
+		log.Infof("Starting Badger garbage collection ... done, interval: %v, discard ratio: %v", valueLogGCInterval, valueLogGCDiscardRatio)
+		timeutil.Ticker(func() { RunDatabaseGarbageCollection() }, valueLogGCInterval, shutdownSignal)
+		log.Info("Stopping Badger garbage collection ... done")
+	}, shutdown.ShutdownPriorityBadgerGarbageCollection)
+}
+
+// IsDatabaseGarbageCollectionAfterPruningEnabled returns whether the value log garbage collection should run after pruning.
+func IsDatabaseGarbageCollectionAfterPruningEnabled() bool {
+	return valueLogGCAfterPruning
+}
+
+// RunDatabaseGarbageCollection runs the value log garbage collection of the database with the configured discard ratio.
+func RunDatabaseGarbageCollection() *hornetDB.GarbageCollectionResult {
+
+	result := hornetDB.RunGarbageCollection(valueLogGCDiscardRatio)
+	if result.Error != "" {
+		log.Warnf("Badger garbage collection failed: %s", result.Error)
+		return result
+	}
+
+	if result.RewrittenFiles > 0 {
+		log.Infof("Badger garbage collection rewrote %d value log files, reclaimed %s, took %v", result.RewrittenFiles, hornetDB.FormatSize(result.ReclaimedBytes), time.Duration(result.Duration)*time.Millisecond)
+	}
+
+	return result
+}
+
+// CompactDatabase starts to merge the levels of the LSM tree of the database in the background
+// and runs the value log garbage collection afterwards. Only one compaction can run at a time.
+func CompactDatabase() (*CompactionStatus, error) {
+
+	compactionLock.Lock()
+	if compactionStatus.Running {
+		compactionLock.Unlock()
+		return nil, ErrCompactionAlreadyRunning
+	}
+	compactionStatus.Running = true
+	compactionStatus.StartTime = time.Now().Unix()
+	compactionLock.Unlock()
+
+	if err := daemon.BackgroundWorker("Database compaction", func(shutdownSignal <-chan struct{}) {
+		result, err := compactDatabase()
+
+		compactionLock.Lock()
+		defer compactionLock.Unlock()
+
+		compactionStatus.Running = false
+		compactionStatus.LastResult = result
+		compactionStatus.LastError = ""
+		if err != nil {
+			log.Warn(err)
+			compactionStatus.LastError = err.Error()
+		}
+	}, shutdown.ShutdownPriorityBadgerGarbageCollection); err != nil {
+		compactionLock.Lock()
+		compactionStatus.Running = false
+		compactionLock.Unlock()
+		return nil, err
+	}
+
+	return GetCompactionStatus(), nil
+}
+
+// GetCompactionStatus returns whether a compaction is running and the result of the last one.
+func GetCompactionStatus() *CompactionStatus {
+	compactionLock.Lock()
+	defer compactionLock.Unlock()
+
+	status := *compactionStatus
+	return &status
+}
+
+func compactDatabase() (*CompactionResult, error) {
+
+	ts := time.Now()
+	result := &CompactionResult{}
+
+	var err error
+	if result.LSMSizeBefore, result.VlogSizeBefore, err = hornetDB.Size(); err != nil {
+		return nil, err
+	}
+
+	log.Info("Compacting the database ...")
+	if err := hornetDB.Compact(); err != nil {
+		return nil, errors.Wrap(err, "database compaction failed")
+	}
+
+	result.GCResult = RunDatabaseGarbageCollection()
+
+	if result.LSMSizeAfter, result.VlogSizeAfter, err = hornetDB.Size(); err != nil {
+		return nil, err
+	}
+	result.Duration = time.Since(ts).Milliseconds()
+
+	log.Infof("Compacting the database ... done, LSM: %s => %s, vlog: %s => %s, took %v", hornetDB.FormatSize(result.LSMSizeBefore), hornetDB.FormatSize(result.LSMSizeAfter), hornetDB.FormatSize(result.VlogSizeBefore), hornetDB.FormatSize(result.VlogSizeAfter), time.Since(ts))
+
+	return result, nil
+}
@@ -0,0 +1,559 @@
+package tangle
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/iota.go/guards"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/iotaledger/hive.go/bitmask"
+
+	"github.com/gohornet/hornet/packages/model/hornet"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/toolset"
+)
+
+var (
+	ErrInvalidHash          = errors.New("invalid hash")
+	ErrDatabaseCheckFailed  = errors.New("database check found problems")
+	ErrDatabaseRepairFailed = errors.New("database could not be repaired")
+)
+
+func init() {
+	toolset.Register("tool transaction", "dumps a transaction and its metadata from the database", runTransactionDumpTool)
+	toolset.Register("tool bundle", "dumps a bundle bucket and its bundles from the database", runBundleDumpTool)
+	toolset.Register("tool milestone", "dumps a milestone from the database", runMilestoneDumpTool)
+	toolset.Register("tool snapshot-info", "dumps the snapshot info and the ledger index from the database", runSnapshotInfoDumpTool)
+	toolset.Register("tool solid-entry-points", "dumps the solid entry points from the database", runSolidEntryPointsDumpTool)
+	toolset.Register("tool check", "checks the database for dangling approvers, missing milestones and an inconsistent ledger, --fix repairs the database", runDatabaseCheckTool)
+}
+
+type transactionDump struct {
+	Hash                    string   `json:"hash"`
+	Address                 string   `json:"address"`
+	Value                   int64    `json:"value"`
+	ObsoleteTag             string   `json:"obsoleteTag"`
+	Tag                     string   `json:"tag"`
+	Timestamp               uint64   `json:"timestamp"`
+	AttachmentTimestamp     int64    `json:"attachmentTimestamp"`
+	CurrentIndex            uint64   `json:"currentIndex"`
+	LastIndex               uint64   `json:"lastIndex"`
+	Bundle                  string   `json:"bundle"`
+	Trunk                   string   `json:"trunk"`
+	Branch                  string   `json:"branch"`
+	Nonce                   string   `json:"nonce"`
+	Solid                   bool     `json:"solid"`
+	SolidificationTimestamp int32    `json:"solidificationTimestamp"`
+	Confirmed               bool     `json:"confirmed"`
+	ConfirmationIndex       uint32   `json:"confirmationIndex"`
+	SolidEntryPoint         bool     `json:"solidEntryPoint"`
+	Approvers               []string `json:"approvers"`
+}
+
+type bundleDump struct {
+	TailHash       string   `json:"tailHash"`
+	Transactions   []string `json:"transactions"`
+	Solid          bool     `json:"solid"`
+	Complete       bool     `json:"complete"`
+	Valid          bool     `json:"valid"`
+	Validated      bool     `json:"validated"`
+	Confirmed      bool     `json:"confirmed"`
+	Conflicting    bool     `json:"conflicting"`
+	IsMilestone    bool     `json:"isMilestone"`
+	MilestoneIndex uint32   `json:"milestoneIndex,omitempty"`
+}
+
+type bundleBucketDump struct {
+	Hash         string        `json:"hash"`
+	Transactions []string      `json:"transactions"`
+	Bundles      []*bundleDump `json:"bundles"`
+}
+
+type milestoneDump struct {
+	Index          uint32      `json:"index"`
+	Hash           string      `json:"hash"`
+	Bundle         *bundleDump `json:"bundle"`
+	Solid          bool        `json:"solid"`
+	LedgerDiffSize int         `json:"ledgerDiffSize"`
+}
+
+type snapshotInfoDump struct {
+	Hash                 string `json:"hash"`
+	SnapshotIndex        uint32 `json:"snapshotIndex"`
+	PruningIndex         uint32 `json:"pruningIndex"`
+	Timestamp            int64  `json:"timestamp"`
+	LedgerIndex          uint32 `json:"ledgerIndex"`
+	SolidEntryPointCount int    `json:"solidEntryPointCount"`
+	Corrupted            bool   `json:"corrupted"`
+}
+
+type solidEntryPointDump struct {
+	Hash  string `json:"hash"`
+	Index uint32 `json:"index"`
+}
+
+type databaseCheckResult struct {
+	MissingMilestones []milestone_index.MilestoneIndex
+	// approvers which point to transactions that don't exist in the database, grouped by the approvee
+	DanglingApprovers      map[trinary.Hash]*tangle.Approvers
+	DanglingApproversCount int
+	// entries of the address index which point to transactions that don't exist in the database
+	DanglingAddressEntries []*tangle.TxHashForAddress
+	LedgerViolations       []string
+}
+
+func (r *databaseCheckResult) isHealthy() bool {
+	return len(r.MissingMilestones) == 0 && r.DanglingApproversCount == 0 && len(r.DanglingAddressEntries) == 0 && len(r.LedgerViolations) == 0
+}
+
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func parseHashArg(name string, args []string) (trinary.Hash, error) {
+	flagSet := toolset.NewFlagSet(name)
+	hash := flagSet.String("hash", "", "hash to dump")
+	if err := flagSet.Parse(args); err != nil {
+		return "", err
+	}
+
+	if !guards.IsTrytesOfExactLength(*hash, 81) {
+		return "", errors.Wrapf(ErrInvalidHash, "%s", *hash)
+	}
+	return *hash, nil
+}
+
+func dumpBundle(bndl *tangle.Bundle) *bundleDump {
+	metadata := bitmask.BitMask(bndl.GetMetadata())
+
+	dump := &bundleDump{
+		TailHash:     bndl.GetTailHash(),
+		Transactions: bndl.GetTransactionHashes(),
+		Solid:        metadata.HasFlag(tangle.HORNET_BUNDLE_METADATA_SOLID),
+		Complete:     metadata.HasFlag(tangle.HORNET_BUNDLE_METADATA_COMPLETE),
+		Valid:        metadata.HasFlag(tangle.HORNET_BUNDLE_METADATA_VALID),
+		Validated:    metadata.HasFlag(tangle.HORNET_BUNDLE_METADATA_VALIDATED),
+		Confirmed:    metadata.HasFlag(tangle.HORNET_BUNDLE_METADATA_CONFIRMED),
+		Conflicting:  metadata.HasFlag(tangle.HORNET_BUNDLE_METADATA_CONFLICTING),
+		IsMilestone:  metadata.HasFlag(tangle.HORNET_BUNDLE_METADATA_IS_MILESTONE),
+	}
+
+	if dump.IsMilestone && bndl.GetTail() != nil {
+		dump.MilestoneIndex = uint32(bndl.GetMilestoneIndex())
+	}
+
+	return dump
+}
+
+func runTransactionDumpTool(args []string) error {
+
+	txHash, err := parseHashArg("tool transaction", args)
+	if err != nil {
+		return err
+	}
+
+	if err := toolset.OpenDatabaseReadOnly(); err != nil {
+		return err
+	}
+	defer toolset.CloseDatabaseReadOnly()
+
+	tx, err := tangle.GetTransaction(txHash)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		return fmt.Errorf("transaction %s not found", txHash)
+	}
+
+	confirmed, confirmationIndex := tx.GetConfirmed()
+	dump := &transactionDump{
+		Hash:                    tx.GetHash(),
+		Address:                 tx.Tx.Address,
+		Value:                   tx.Tx.Value,
+		ObsoleteTag:             tx.Tx.ObsoleteTag,
+		Tag:                     tx.Tx.Tag,
+		Timestamp:               tx.Tx.Timestamp,
+		AttachmentTimestamp:     tx.Tx.AttachmentTimestamp,
+		CurrentIndex:            tx.Tx.CurrentIndex,
+		LastIndex:               tx.Tx.LastIndex,
+		Bundle:                  tx.Tx.Bundle,
+		Trunk:                   tx.GetTrunk(),
+		Branch:                  tx.GetBranch(),
+		Nonce:                   tx.Tx.Nonce,
+		Solid:                   tx.IsSolid(),
+		SolidificationTimestamp: tx.GetSolidificationTimestamp(),
+		Confirmed:               confirmed,
+		ConfirmationIndex:       uint32(confirmationIndex),
+		SolidEntryPoint:         tangle.SolidEntryPointsContain(txHash),
+		Approvers:               []string{},
+	}
+
+	approvers, err := tangle.GetApprovers(txHash)
+	if err != nil {
+		return err
+	}
+	if approvers != nil {
+		dump.Approvers = approvers.GetHashes()
+	}
+
+	return printJSON(dump)
+}
+
+func runBundleDumpTool(args []string) error {
+
+	bundleHash, err := parseHashArg("tool bundle", args)
+	if err != nil {
+		return err
+	}
+
+	if err := toolset.OpenDatabaseReadOnly(); err != nil {
+		return err
+	}
+	defer toolset.CloseDatabaseReadOnly()
+
+	bundleBucket, err := tangle.GetBundleBucket(bundleHash)
+	if err != nil {
+		return err
+	}
+	if bundleBucket == nil || len(bundleBucket.TransactionHashes()) == 0 {
+		return fmt.Errorf("bundle %s not found", bundleHash)
+	}
+
+	dump := &bundleBucketDump{
+		Hash:         bundleHash,
+		Transactions: bundleBucket.TransactionHashes(),
+		Bundles:      []*bundleDump{},
+	}
+
+	for _, bndl := range bundleBucket.Bundles() {
+		dump.Bundles = append(dump.Bundles, dumpBundle(bndl))
+	}
+
+	return printJSON(dump)
+}
+
+func runMilestoneDumpTool(args []string) error {
+
+	flagSet := toolset.NewFlagSet("tool milestone")
+	index := flagSet.Uint32("index", 0, "index of the milestone to dump")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if *index == 0 {
+		return errors.New("no milestone index given, use --index <index>")
+	}
+
+	if err := toolset.OpenDatabaseReadOnly(); err != nil {
+		return err
+	}
+	defer toolset.CloseDatabaseReadOnly()
+
+	milestoneIndex := milestone_index.MilestoneIndex(*index)
+
+	ms, err := tangle.GetMilestone(milestoneIndex)
+	if err != nil {
+		return err
+	}
+	if ms == nil {
+		return fmt.Errorf("milestone %d not found", milestoneIndex)
+	}
+
+	diff, err := tangle.GetLedgerDiffForMilestone(milestoneIndex, nil)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(&milestoneDump{
+		Index:          uint32(milestoneIndex),
+		Hash:           ms.GetMilestoneHash(),
+		Bundle:         dumpBundle(ms),
+		Solid:          milestoneIndex <= tangle.GetSolidMilestoneIndex(),
+		LedgerDiffSize: len(diff),
+	})
+}
+
+func runSnapshotInfoDumpTool(args []string) error {
+
+	flagSet := toolset.NewFlagSet("tool snapshot-info")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if err := toolset.OpenDatabaseReadOnly(); err != nil {
+		return err
+	}
+	defer toolset.CloseDatabaseReadOnly()
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return errors.New("no snapshot info found")
+	}
+
+	return printJSON(&snapshotInfoDump{
+		Hash:                 snapshotInfo.Hash,
+		SnapshotIndex:        uint32(snapshotInfo.SnapshotIndex),
+		PruningIndex:         uint32(snapshotInfo.PruningIndex),
+		Timestamp:            snapshotInfo.Timestamp,
+		LedgerIndex:          uint32(tangle.GetLedgerMilestoneIndexWithoutLocking()),
+		SolidEntryPointCount: len(tangle.GetSolidEntryPointsHashes()),
+		Corrupted:            tangle.IsDatabaseCorrupted(),
+	})
+}
+
+func runSolidEntryPointsDumpTool(args []string) error {
+
+	flagSet := toolset.NewFlagSet("tool solid-entry-points")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if err := toolset.OpenDatabaseReadOnly(); err != nil {
+		return err
+	}
+	defer toolset.CloseDatabaseReadOnly()
+
+	dump := []*solidEntryPointDump{}
+	for _, hash := range tangle.GetSolidEntryPointsHashes() {
+		index, _ := tangle.SolidEntryPointsIndex(hash)
+		dump = append(dump, &solidEntryPointDump{Hash: hash, Index: uint32(index)})
+	}
+
+	return printJSON(dump)
+}
+
+// checkDatabase searches the database for missing milestones, approvers and address index entries of
+// transactions that don't exist anymore, and violations of the ledger invariants.
+func checkDatabase() (*databaseCheckResult, error) {
+
+	result := &databaseCheckResult{DanglingApprovers: make(map[trinary.Hash]*tangle.Approvers)}
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return nil, errors.New("no snapshot info found")
+	}
+
+	// the milestones before the pruning index were removed, the snapshot milestone itself is only a solid entry point
+	firstIndex := snapshotInfo.SnapshotIndex
+	if snapshotInfo.PruningIndex > firstIndex {
+		firstIndex = snapshotInfo.PruningIndex
+	}
+	ledgerIndex := tangle.GetLedgerMilestoneIndexWithoutLocking()
+
+	fmt.Printf("Checking milestones %d-%d ...\n", firstIndex+1, ledgerIndex)
+	for msIndex := firstIndex + 1; msIndex <= ledgerIndex; msIndex++ {
+		contains, err := tangle.ContainsMilestone(msIndex)
+		if err != nil {
+			return nil, err
+		}
+		if !contains {
+			result.MissingMilestones = append(result.MissingMilestones, msIndex)
+		}
+	}
+
+	fmt.Println("Checking approvers ...")
+	var iterErr error
+	err := tangle.ForEachApproverInDatabase(func(txHash trinary.Hash, approverHash trinary.Hash) bool {
+		contains, err := tangle.ContainsTransaction(approverHash)
+		if err != nil {
+			iterErr = err
+			return true
+		}
+		if !contains {
+			if _, exists := result.DanglingApprovers[txHash]; !exists {
+				result.DanglingApprovers[txHash] = tangle.NewApprovers(txHash)
+			}
+			result.DanglingApprovers[txHash].Add(approverHash)
+			result.DanglingApproversCount++
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if iterErr != nil {
+		return nil, iterErr
+	}
+
+	fmt.Println("Checking address index ...")
+	err = tangle.ForEachTransactionHashForAddressInDatabase(func(address trinary.Hash, txHash trinary.Hash) bool {
+		contains, err := tangle.ContainsTransaction(txHash)
+		if err != nil {
+			iterErr = err
+			return true
+		}
+		if !contains {
+			result.DanglingAddressEntries = append(result.DanglingAddressEntries, &tangle.TxHashForAddress{Address: address, TxHash: txHash})
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if iterErr != nil {
+		return nil, iterErr
+	}
+
+	fmt.Println("Checking ledger ...")
+	if solidMilestoneIndex := tangle.GetSolidMilestoneIndex(); solidMilestoneIndex != ledgerIndex {
+		result.LedgerViolations = append(result.LedgerViolations, fmt.Sprintf("ledger index %d does not match the solid milestone index %d", ledgerIndex, solidMilestoneIndex))
+	}
+
+	for msIndex := firstIndex + 1; msIndex <= ledgerIndex; msIndex++ {
+		violations, err := tangle.CheckStoredLedgerDiffInvariants(msIndex)
+		if err != nil {
+			return nil, err
+		}
+		result.LedgerViolations = append(result.LedgerViolations, violations...)
+	}
+
+	auditResult, err := tangle.AuditLedger(nil)
+	if err != nil {
+		return nil, err
+	}
+	result.LedgerViolations = append(result.LedgerViolations, auditResult.Violations...)
+
+	return result, nil
+}
+
+// rebuildAddressIndex removes the whole address index and recreates it from the stored transactions.
+func rebuildAddressIndex() (int, error) {
+
+	if err := tangle.DeleteAllTransactionHashesForAddressesInDatabase(); err != nil {
+		return 0, err
+	}
+
+	var count int
+	var storeErr error
+	var addresses []*tangle.TxHashForAddress
+
+	err := tangle.ForEachTransactionInDatabase(func(tx *hornet.Transaction) bool {
+		addresses = append(addresses, &tangle.TxHashForAddress{Address: tx.Tx.Address, TxHash: tx.GetHash()})
+		count++
+
+		if len(addresses) >= 1000 {
+			storeErr = tangle.StoreTransactionHashesForAddressesInDatabase(addresses)
+			addresses = nil
+		}
+		return storeErr != nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if storeErr != nil {
+		return 0, storeErr
+	}
+
+	if err := tangle.StoreTransactionHashesForAddressesInDatabase(addresses); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func printDatabaseCheckResult(result *databaseCheckResult) {
+	for _, msIndex := range result.MissingMilestones {
+		fmt.Printf("Missing milestone: %d\n", msIndex)
+	}
+	for txHash, approvers := range result.DanglingApprovers {
+		for _, approverHash := range approvers.GetHashes() {
+			fmt.Printf("Dangling approver: %s of transaction %s\n", approverHash, txHash)
+		}
+	}
+	for _, entry := range result.DanglingAddressEntries {
+		fmt.Printf("Dangling address index entry: %s of address %s\n", entry.TxHash, entry.Address)
+	}
+	for _, violation := range result.LedgerViolations {
+		fmt.Printf("Ledger violation: %s\n", violation)
+	}
+
+	fmt.Printf("Missing milestones: %d, dangling approvers: %d, dangling address index entries: %d, ledger violations: %d\n",
+		len(result.MissingMilestones), result.DanglingApproversCount, len(result.DanglingAddressEntries), len(result.LedgerViolations))
+}
+
+func runDatabaseCheckTool(args []string) error {
+
+	flagSet := toolset.NewFlagSet("tool check")
+	fix := flagSet.Bool("fix", false, "removes dangling approvers, rebuilds the address index and clears the corrupted flag if the database is consistent afterwards")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if !*fix {
+		if err := toolset.OpenDatabaseReadOnly(); err != nil {
+			return err
+		}
+		defer toolset.CloseDatabaseReadOnly()
+
+		if tangle.IsDatabaseCorrupted() {
+			fmt.Println("Database is marked as corrupted")
+		}
+
+		result, err := checkDatabase()
+		if err != nil {
+			return err
+		}
+		printDatabaseCheckResult(result)
+
+		if !result.isHealthy() {
+			return ErrDatabaseCheckFailed
+		}
+		return nil
+	}
+
+	corrupted, err := toolset.OpenDatabaseForRepair()
+	if err != nil {
+		return err
+	}
+
+	healthy := false
+	defer func() { toolset.CloseDatabaseAfterRepair(healthy) }()
+
+	if corrupted {
+		fmt.Println("Database is marked as corrupted")
+	}
+
+	result, err := checkDatabase()
+	if err != nil {
+		return err
+	}
+	printDatabaseCheckResult(result)
+
+	if result.DanglingApproversCount > 0 {
+		var approvers []*tangle.Approvers
+		for _, app := range result.DanglingApprovers {
+			approvers = append(approvers, app)
+			tangle.DiscardApproversFromCache(app.GetHash())
+		}
+
+		if err := tangle.DeleteApproversInDatabase(approvers); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %d dangling approvers\n", result.DanglingApproversCount)
+	}
+
+	fmt.Println("Rebuilding address index ...")
+	txCount, err := rebuildAddressIndex()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Rebuilt address index with %d transactions\n", txCount)
+
+	if len(result.MissingMilestones) > 0 || len(result.LedgerViolations) > 0 {
+		// missing milestones and an invalid ledger can't be repaired offline, the database has to be replaced by a snapshot
+		return errors.Wrap(ErrDatabaseRepairFailed, "missing milestones or ledger violations remain, the database stays marked as corrupted")
+	}
+
+	healthy = true
+	if corrupted {
+		fmt.Println("Cleared the corrupted flag of the database")
+	}
+	return nil
+}
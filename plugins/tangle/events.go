@@ -17,6 +17,8 @@ var Events = pluginEvents{
 	LatestMilestoneChanged:        events.NewEvent(tangle.BundleCaller),
 	SolidMilestoneChanged:         events.NewEvent(tangle.BundleCaller),
 	SnapshotMilestoneIndexChanged: events.NewEvent(milestone_index.MilestoneIndexCaller),
+	ConflictDetected:              events.NewEvent(ConflictCaller),
+	LedgerInvariantViolated:       events.NewEvent(LedgerInvariantViolationCaller),
 }
 
 type pluginEvents struct {
@@ -29,4 +31,6 @@ type pluginEvents struct {
 	LatestMilestoneChanged        *events.Event
 	SolidMilestoneChanged         *events.Event
 	SnapshotMilestoneIndexChanged *events.Event
+	ConflictDetected              *events.Event
+	LedgerInvariantViolated       *events.Event
 }
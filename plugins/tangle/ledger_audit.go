@@ -0,0 +1,97 @@
+package tangle
+
+import (
+	"strings"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/packages/shutdown"
+)
+
+var (
+	ledgerAuditEnabled          bool
+	ledgerAuditRecountOnStartup bool
+)
+
+func LedgerInvariantViolationCaller(handler interface{}, params ...interface{}) {
+	handler.(func(reason string))(params[0].(string))
+}
+
+func configureLedgerAudit() {
+	ledgerAuditEnabled = parameter.NodeConfig.GetBool("ledgerAudit.enabled")
+	ledgerAuditRecountOnStartup = parameter.NodeConfig.GetBool("ledgerAudit.recountOnStartup")
+}
+
+func runLedgerAudit() {
+	if !ledgerAuditEnabled {
+		return
+	}
+
+	// the event is triggered while the ledger is still write locked after the ledger diff was applied
+	notifySolidMilestoneChanged := events.NewClosure(func(msBundle *tangle.Bundle) {
+		auditMilestone(msBundle.GetMilestoneIndex())
+	})
+
+	daemon.BackgroundWorker("LedgerAudit", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting LedgerAudit ... done")
+		Events.SolidMilestoneChanged.Attach(notifySolidMilestoneChanged)
+
+		if ledgerAuditRecountOnStartup {
+			AuditLedger(shutdownSignal)
+		}
+
+		<-shutdownSignal
+		log.Info("Stopping LedgerAudit ...")
+		Events.SolidMilestoneChanged.Detach(notifySolidMilestoneChanged)
+		log.Info("Stopping LedgerAudit ... done")
+	}, shutdown.ShutdownPriorityLedgerAudit)
+}
+
+// auditMilestone checks the persisted balances and the running supply total after the given milestone.
+// WriteLockLedger must be held while entering this function.
+func auditMilestone(msIndex milestone_index.MilestoneIndex) {
+	violations, err := tangle.CheckLedgerDiffInvariantsWithoutLocking(msIndex)
+	if err != nil {
+		log.Errorf("Ledger audit of milestone %d failed: %v", msIndex, err)
+		return
+	}
+
+	if len(violations) > 0 {
+		onLedgerInvariantViolated(violations)
+	}
+}
+
+// AuditLedger recounts the whole ledger and checks it against the total supply.
+// A detected violation is handled the same way as a violation found by the incremental check.
+func AuditLedger(abortSignal <-chan struct{}) (*tangle.LedgerAuditResult, error) {
+	log.Info("Recounting the ledger ...")
+
+	result, err := tangle.AuditLedger(abortSignal)
+	if err != nil {
+		log.Errorf("Recounting the ledger failed: %v", err)
+		return nil, err
+	}
+
+	if len(result.Violations) > 0 {
+		onLedgerInvariantViolated(result.Violations)
+		return result, nil
+	}
+
+	log.Infof("Recounting the ledger ... done. Milestone: %d, addresses: %d, supply: %d", result.MilestoneIndex, result.AddressCount, result.Supply)
+	return result, nil
+}
+
+func onLedgerInvariantViolated(violations []string) {
+	for _, violation := range violations {
+		log.Errorf("Ledger invariant violated: %s", violation)
+	}
+	log.Error("Balances are not served anymore until the ledger is verified by an operator")
+
+	reason := strings.Join(violations, "; ")
+	tangle.SetLedgerInvariantViolated(reason)
+	Events.LedgerInvariantViolated.Trigger(reason)
+}
@@ -0,0 +1,80 @@
+package tangle
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/hive.go/logger"
+
+	hornetDB "github.com/gohornet/hornet/packages/database"
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/packages/toolset"
+)
+
+func init() {
+	toolset.Register("db migrate", "migrates the database to the current version, --dry-run only shows the pending migrations", runDatabaseMigrationTool)
+}
+
+// migrateDatabase creates a backup point of the database if enabled and runs all pending migrations.
+func migrateDatabase(dryRun bool, backup bool) error {
+
+	version, err := tangle.GetDatabaseVersion()
+	if err != nil {
+		return err
+	}
+
+	migrations, err := tangle.GetPendingDatabaseMigrations()
+	if err != nil {
+		return err
+	}
+
+	if len(migrations) == 0 {
+		log.Infof("Database is already at version %d", version)
+		return nil
+	}
+
+	for _, migration := range migrations {
+		log.Infof("Pending database migration from version %d to %d: %s", migration.FromVersion, migration.ToVersion, migration.Description)
+	}
+
+	if backup && !dryRun {
+		backupPath := fmt.Sprintf("%s_v%d.bak", parameter.NodeConfig.GetString("db.path"), version)
+
+		log.Infof("Creating database backup %s ...", backupPath)
+		if _, err := hornetDB.BackupToFile(backupPath, 0, nil, nil); err != nil {
+			return err
+		}
+		log.Infof("Creating database backup %s ... done", backupPath)
+	}
+
+	if dryRun {
+		log.Info("Dry run, the database is not modified")
+	}
+
+	return tangle.MigrateDatabase(dryRun, log.Infof)
+}
+
+func runDatabaseMigrationTool(args []string) error {
+
+	flagSet := toolset.NewFlagSet("db migrate")
+	dryRun := flagSet.Bool("dry-run", false, "runs the migrations without modifying the database")
+	backup := flagSet.Bool("backup", parameter.NodeConfig.GetBool("db.migrationBackup"), "creates a backup of the database before it is migrated")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	log = logger.NewLogger("Tangle")
+
+	if err := toolset.OpenDatabaseForMigration(*dryRun); err != nil {
+		return err
+	}
+
+	if *dryRun {
+		defer toolset.CloseDatabaseReadOnly()
+		return migrateDatabase(true, false)
+	}
+
+	err := migrateDatabase(false, *backup)
+	toolset.CloseDatabaseAfterRepair(err == nil)
+	return err
+}
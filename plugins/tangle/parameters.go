@@ -8,6 +8,39 @@ func init() {
 	// "Path to the database folder"
 	parameter.NodeConfig.SetDefault("db.path", "mainnetdb")
 
+	// "Migrate the database to the current version at startup instead of refusing to start"
+	parameter.NodeConfig.SetDefault("db.autoMigrate", true)
+
+	// "Create a backup of the database before it is migrated"
+	parameter.NodeConfig.SetDefault("db.migrationBackup", true)
+
+	// "Interval of the value log garbage collection of the database in minutes, 0 disables it"
+	parameter.NodeConfig.SetDefault("db.valueLogGCIntervalMinutes", 5)
+
+	// "Ratio of discardable data at which a value log file of the database is rewritten"
+	parameter.NodeConfig.SetDefault("db.valueLogGCDiscardRatio", 0.5)
+
+	// "Run the value log garbage collection after the database was pruned"
+	parameter.NodeConfig.SetDefault("db.valueLogGCAfterPruning", true)
+
 	// "Auto. set LSM as LSMI if enabled"
 	parameter.NodeConfig.SetDefault("compass.loadLSMIAsLMI", false)
+
+	// "Path to the transaction policy config file"
+	parameter.NodeConfig.SetDefault("policy.config", "policy.json")
+
+	// "Check the ledger invariants after every confirmed milestone"
+	parameter.NodeConfig.SetDefault("ledgerAudit.enabled", true)
+
+	// "Recount the whole ledger against the total supply at startup"
+	parameter.NodeConfig.SetDefault("ledgerAudit.recountOnStartup", false)
+
+	// "Path to the folder of the database backups"
+	parameter.NodeConfig.SetDefault("backup.path", "backups")
+
+	// "Interval of the scheduled database backups in minutes, 0 disables them"
+	parameter.NodeConfig.SetDefault("backup.intervalMinutes", 0)
+
+	// "Create the scheduled backups incremental on top of the latest backup"
+	parameter.NodeConfig.SetDefault("backup.incremental", false)
 }
@@ -26,16 +26,24 @@ var (
 	firstSeenTxBatchSize              = 1000
 	firstSeenTxBatchCollectionTimeout = 1000 * time.Millisecond
 	firstSeenTxWorkerPool             *batchworkerpool.BatchWorkerPool
+
+	bundleTailPersisterWorkerCount            = 1
+	bundleTailPersisterQueueSize              = 10000
+	bundleTailPersisterBatchSize              = 1000
+	bundleTailPersisterBatchCollectionTimeout = 1000 * time.Millisecond
+	bundleTailPersisterWorkerPool             *batchworkerpool.BatchWorkerPool
 )
 
 func configurePersisters() {
 	configureAddressPersister()
 	configureFirstSeenTransactionPersister()
+	configureBundleTailPersister()
 }
 
 func runPersisters() {
 	runAddressPersister()
 	runFirstSeenTransactionPersister()
+	runBundleTailPersister()
 }
 
 // Address persister
@@ -121,6 +129,53 @@ func runFirstSeenTransactionPersister() {
 	}, shutdown.ShutdownPriorityPersisters)
 }
 
+// Bundle tail persister
+func configureBundleTailPersister() {
+
+	bundleTailPersisterWorkerPool = batchworkerpool.New(func(tasks []batchworkerpool.Task) {
+
+		var bundleTails []*tangle.BundleTail
+		for _, task := range tasks {
+			bundleTails = append(bundleTails, task.Param(0).(*tangle.BundleTail))
+		}
+
+		err := tangle.StoreBundleTailsInDatabase(bundleTails)
+		if err != nil {
+			panic(err)
+		}
+
+		for _, task := range tasks {
+			task.Return(nil)
+		}
+	}, batchworkerpool.BatchCollectionTimeout(bundleTailPersisterBatchCollectionTimeout), batchworkerpool.BatchSize(bundleTailPersisterBatchSize), batchworkerpool.WorkerCount(bundleTailPersisterWorkerCount), batchworkerpool.QueueSize(bundleTailPersisterQueueSize), batchworkerpool.FlushTasksAtShutdown(true))
+}
+
+func runBundleTailPersister() {
+
+	notifyNewTx := events.NewClosure(func(transaction *hornet.Transaction, firstSeenLatestMilestoneIndex milestone_index.MilestoneIndex, latestSolidMilestoneIndex milestone_index.MilestoneIndex) {
+		// every tail is a (re)attachment of the bundle
+		if transaction.IsTail() {
+			bundleTailPersisterWorkerPool.Submit(&tangle.BundleTail{
+				BundleHash:                    transaction.Tx.Bundle,
+				TailHash:                      transaction.GetHash(),
+				FirstSeenTimestamp:            time.Now().Unix(),
+				FirstSeenLatestMilestoneIndex: firstSeenLatestMilestoneIndex,
+			})
+		}
+	})
+
+	daemon.BackgroundWorker("BundleTailPersister", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting BundleTailPersister ... done")
+		Events.ReceivedNewTransaction.Attach(notifyNewTx)
+		bundleTailPersisterWorkerPool.Start()
+		<-shutdownSignal
+		log.Info("Stopping BundleTailPersister ...")
+		Events.ReceivedNewTransaction.Detach(notifyNewTx)
+		bundleTailPersisterWorkerPool.StopAndWait()
+		log.Info("Stopping BundleTailPersister ... done")
+	}, shutdown.ShutdownPriorityPersisters)
+}
+
 // Tx stored event
 func onEvictTransactions(evicted []*hornet.Transaction) {
 	for _, tx := range evicted {
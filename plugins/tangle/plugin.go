@@ -49,7 +49,13 @@ func configure(plugin *node.Plugin) {
 	}
 
 	if !tangle.IsCorrectDatabaseVersion() {
-		log.Panic("HORNET database version mismatch. The database scheme was updated. Please delete the database folder and start with a new local snapshot.")
+		if !parameter.NodeConfig.GetBool("db.autoMigrate") {
+			log.Panic("HORNET database version mismatch. The database scheme was updated. Please run \"hornet db migrate\" or delete the database folder and start with a new local snapshot.")
+		}
+
+		if err := migrateDatabase(false, parameter.NodeConfig.GetBool("db.migrationBackup")); err != nil {
+			log.Panicf("HORNET database migration failed: %v. Please restore the database backup or delete the database folder and start with a new local snapshot.", err)
+		}
 	}
 
 	// Create a background worker that marks the database as corrupted at clean startup.
@@ -79,7 +85,12 @@ func configure(plugin *node.Plugin) {
 		}
 		log.Info("Flushing caches to database... done")
 
-		tangle.MarkDatabaseHealthy()
+		if tangle.IsLedgerInvariantViolated() {
+			// keep the database marked as corrupted, the operator has to take care of the ledger
+			log.Warn("Ledger invariant violation was not resolved, database stays marked as corrupted")
+		} else {
+			tangle.MarkDatabaseHealthy()
+		}
 
 		log.Info("Syncing database to disk...")
 		hornetDB.GetBadgerInstance().Close()
@@ -100,18 +111,19 @@ func configure(plugin *node.Plugin) {
 
 	tangle.LoadInitialValuesFromDatabase()
 	configureTangleProcessor(plugin)
+	configureLedgerAudit()
+	configureBackup()
+	configureDatabaseMaintenance()
 }
 
 func run(plugin *node.Plugin) {
 	runTangleProcessor(plugin)
+	runLedgerAudit()
+	runBackup()
+	runDatabaseMaintenance()
 
 	// create a background worker that prints a status message every second
 	daemon.BackgroundWorker("Tangle status reporter", func(shutdownSignal <-chan struct{}) {
 		timeutil.Ticker(printStatus, 1*time.Second, shutdownSignal)
 	}, shutdown.ShutdownPriorityStatusReport)
-
-	// create a db cleanup worker
-	daemon.BackgroundWorker("Badger garbage collection", func(shutdownSignal <-chan struct{}) {
-		timeutil.Ticker(hornetDB.CleanupBadgerInstance, 5*time.Minute, shutdownSignal)
-	}, shutdown.ShutdownPriorityBadgerGarbageCollection)
 }
@@ -0,0 +1,62 @@
+package tangle
+
+import (
+	"os"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+
+	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/packages/policy"
+)
+
+var (
+	policyConfig = viper.New()
+)
+
+// configurePolicy loads the transaction policy rules and watches the config file for changes
+func configurePolicy() {
+
+	policyConfigPath := parameter.NodeConfig.GetString("policy.config")
+	if policyConfigPath == "" {
+		return
+	}
+
+	if _, err := os.Stat(policyConfigPath); os.IsNotExist(err) {
+		log.Infof("Transaction policy config %s not found, all transactions are accepted", policyConfigPath)
+		return
+	}
+
+	policyConfig.SetConfigFile(policyConfigPath)
+	if err := policyConfig.ReadInConfig(); err != nil {
+		log.Panicf("Loading transaction policy config %s failed: %v", policyConfigPath, err)
+	}
+
+	if err := loadPolicyRules(); err != nil {
+		log.Panicf("Loading transaction policy rules failed: %v", err)
+	}
+
+	policyConfig.OnConfigChange(func(e fsnotify.Event) {
+		// keep the old rules if the new ones are invalid
+		if err := loadPolicyRules(); err != nil {
+			log.Errorf("Reloading transaction policy rules failed: %v", err)
+			return
+		}
+		log.Info("Reloaded transaction policy rules due to config change")
+	})
+	policyConfig.WatchConfig()
+}
+
+func loadPolicyRules() error {
+	var rules []policy.RuleConfig
+	if err := policyConfig.UnmarshalKey("rules", &rules); err != nil {
+		return err
+	}
+
+	if err := policy.SetRules(rules); err != nil {
+		return err
+	}
+
+	log.Infof("Transaction policy contains %d rules", len(rules))
+	return nil
+}
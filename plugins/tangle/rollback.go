@@ -0,0 +1,202 @@
+package tangle
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/iotaledger/hive.go/logger"
+
+	"github.com/gohornet/hornet/packages/model/hornet"
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/toolset"
+)
+
+var (
+	ErrRollbackTargetTooNew = errors.New("rollback target is too new")
+	ErrRollbackTargetTooOld = errors.New("rollback target is too old")
+)
+
+func init() {
+	toolset.Register("ledger rollback", "reverts the ledger to an earlier milestone, the node re-confirms the following milestones after the next start", runLedgerRollbackTool)
+}
+
+// RollbackLedger reverts the ledger to the state of the target milestone while the node is running.
+// The inverse ledger diffs are applied, the transactions confirmed by newer milestones are marked as unconfirmed
+// and the solid milestone index is reset. The solidifier re-confirms the following milestones afterwards.
+func RollbackLedger(targetIndex milestone_index.MilestoneIndex, abortSignal <-chan struct{}) error {
+	if err := rollbackLedger(targetIndex, abortSignal); err != nil {
+		return err
+	}
+
+	// Run check for next milestone
+	milestoneSolidifierWorkerPool.TrySubmit(milestone_index.MilestoneIndex(0))
+	return nil
+}
+
+func rollbackLedger(targetIndex milestone_index.MilestoneIndex, abortSignal <-chan struct{}) error {
+
+	// Stop possible running solidifications, otherwise a milestone could be confirmed on top of the old ledger state
+	abortMilestoneSolidification()
+
+	solidifierLock.Lock()
+	defer solidifierLock.Unlock()
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		return errors.New("no snapshot info found")
+	}
+
+	tangle.WriteLockLedger()
+	defer tangle.WriteUnlockLedger()
+
+	solidMilestoneIndex := tangle.GetSolidMilestoneIndex()
+
+	if targetIndex >= solidMilestoneIndex {
+		return errors.Wrapf(ErrRollbackTargetTooNew, "maximum: %d, actual: %d", solidMilestoneIndex-1, targetIndex)
+	}
+
+	// the solid entry points of the snapshot and the pruned ledger diffs can't be restored
+	minimumIndex := snapshotInfo.SnapshotIndex
+	if snapshotInfo.PruningIndex > minimumIndex {
+		minimumIndex = snapshotInfo.PruningIndex
+	}
+	if targetIndex < minimumIndex {
+		return errors.Wrapf(ErrRollbackTargetTooOld, "minimum: %d, actual: %d", minimumIndex, targetIndex)
+	}
+
+	log.Infof("Rolling back the ledger from milestone %d to %d ...", solidMilestoneIndex, targetIndex)
+	ts := time.Now()
+
+	for msIndex := solidMilestoneIndex; msIndex > targetIndex; msIndex-- {
+		select {
+		case <-abortSignal:
+			// the ledger is consistent after every rolled back milestone
+			log.Infof("Rolling back the ledger was aborted at milestone %d", msIndex)
+			return tangle.ErrOperationAborted
+		default:
+		}
+
+		ms, err := tangle.GetMilestone(msIndex)
+		if err != nil {
+			return err
+		}
+		if ms == nil {
+			return fmt.Errorf("milestone %d not found", msIndex)
+		}
+
+		// the ledger is rolled back first, a milestone with already unconfirmed transactions
+		// would be walked again by the confirmation after a crash in between.
+		if err := tangle.RollbackLedgerDiffWithoutLocking(msIndex); err != nil {
+			return err
+		}
+
+		txCount, err := unconfirmMilestone(msIndex, ms.GetTail())
+		if err != nil {
+			return err
+		}
+
+		tangle.ResetSolidMilestoneIndex(msIndex - 1)
+		log.Infof("Rolled back milestone %d, unconfirmed %d transactions", msIndex, txCount)
+	}
+
+	log.Infof("Rolling back the ledger from milestone %d to %d ... done, took %v", solidMilestoneIndex, targetIndex, time.Since(ts))
+	return nil
+}
+
+// unconfirmMilestone traverses the cone of the milestone and marks all transactions confirmed by it as unconfirmed.
+func unconfirmMilestone(milestoneIndex milestone_index.MilestoneIndex, milestoneTail *hornet.Transaction) (int, error) {
+
+	var txCount int
+	txsChecked := make(map[string]struct{})
+	txsToTraverse := make(map[string]struct{})
+	txsToTraverse[milestoneTail.GetHash()] = struct{}{}
+
+	// Loop as long as new transactions are added in every loop cycle
+	for len(txsToTraverse) != 0 {
+
+		for txHash := range txsToTraverse {
+			delete(txsToTraverse, txHash)
+
+			if _, checked := txsChecked[txHash]; checked {
+				// Tx was already checked => ignore
+				continue
+			}
+			txsChecked[txHash] = struct{}{}
+
+			if tangle.SolidEntryPointsContain(txHash) {
+				// Ignore solid entry points (snapshot milestone included)
+				continue
+			}
+
+			tx, _ := tangle.GetTransaction(txHash)
+			if tx == nil {
+				return 0, fmt.Errorf("unconfirmMilestone: Transaction not found: %v", txHash)
+			}
+
+			confirmed, at := tx.GetConfirmed()
+			if !confirmed || at != milestoneIndex {
+				// Tx was confirmed by an older milestone => ignore
+				continue
+			}
+
+			tx.SetConfirmed(false, 0)
+			txCount++
+
+			if tx.IsTail() {
+				bundleBucket, err := tangle.GetBundleBucket(tx.Tx.Bundle)
+				if err != nil {
+					return 0, err
+				}
+				if bundleBucket != nil {
+					if bundle := bundleBucket.GetBundleOfTailTransaction(txHash); bundle != nil {
+						bundle.ResetConfirmed()
+					}
+				}
+			}
+
+			// Mark the approvees to be traversed
+			txsToTraverse[tx.GetTrunk()] = struct{}{}
+			txsToTraverse[tx.GetBranch()] = struct{}{}
+		}
+	}
+
+	return txCount, nil
+}
+
+func runLedgerRollbackTool(args []string) error {
+
+	flagSet := toolset.NewFlagSet("ledger rollback")
+	targetIndex := flagSet.Uint32("to", 0, "milestone index to roll back the ledger to")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+
+	if *targetIndex == 0 {
+		return errors.New("no target index given, use --to <index>")
+	}
+
+	log = logger.NewLogger("Tangle")
+
+	corrupted, err := toolset.OpenDatabaseForRepair()
+	if err != nil {
+		return err
+	}
+
+	// the database stays marked as corrupted if the rollback fails halfway
+	healthy := false
+	defer func() { toolset.CloseDatabaseAfterRepair(healthy) }()
+
+	if corrupted {
+		return toolset.ErrDatabaseCorrupted
+	}
+
+	if err := rollbackLedger(milestone_index.MilestoneIndex(*targetIndex), nil); err != nil {
+		return err
+	}
+
+	healthy = true
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/gohornet/hornet/packages/model/hornet"
 	"github.com/gohornet/hornet/packages/model/milestone_index"
 	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/policy"
 	"github.com/gohornet/hornet/packages/shutdown"
 	"github.com/gohornet/hornet/plugins/gossip"
 	"github.com/gohornet/hornet/plugins/gossip/server"
@@ -25,6 +26,11 @@ var (
 	receiveTxQueueSize   = 10000
 	receiveTxWorkerPool  *workerpool.WorkerPool
 
+	// deprioritized transactions are processed by a single worker and dropped if the queue is full
+	deprioritizedTxWorkerCount = 1
+	deprioritizedTxQueueSize   = 1000
+	deprioritizedTxWorkerPool  *workerpool.WorkerPool
+
 	lastIncomingTPS uint32
 	lastNewTPS      uint32
 	lastOutgoingTPS uint32
@@ -37,12 +43,20 @@ func configureTangleProcessor(plugin *node.Plugin) {
 
 	configureGossipSolidifier()
 	configurePersisters()
+	configurePolicy()
+	configureConflictDetector()
 
 	receiveTxWorkerPool = workerpool.New(func(task workerpool.Task) {
-		processIncomingTx(plugin, task.Param(0).(*hornet.Transaction))
+		unrequestedTx, _ := task.Param(1).(*gossip.UnrequestedTransaction)
+		processIncomingTx(plugin, task.Param(0).(*hornet.Transaction), unrequestedTx)
 		task.Return(nil)
 	}, workerpool.WorkerCount(receiveTxWorkerCount), workerpool.QueueSize(receiveTxQueueSize))
 
+	deprioritizedTxWorkerPool = workerpool.New(func(task workerpool.Task) {
+		processIncomingTx(plugin, task.Param(0).(*hornet.Transaction), nil)
+		task.Return(nil)
+	}, workerpool.WorkerCount(deprioritizedTxWorkerCount), workerpool.QueueSize(deprioritizedTxQueueSize))
+
 	checkForMilestoneWorkerPool = workerpool.New(func(task workerpool.Task) {
 		checkBundleForMilestone(task.Param(0).(*tangle.Bundle))
 		task.Return(nil)
@@ -65,19 +79,28 @@ func runTangleProcessor(plugin *node.Plugin) {
 
 	runGossipSolidifier()
 	runPersisters()
+	runConflictDetector()
 
 	notifyReceivedTx := events.NewClosure(func(transaction *hornet.Transaction) {
-		receiveTxWorkerPool.Submit(transaction)
+		receiveTxWorkerPool.Submit(transaction, nil)
+	})
+
+	notifyReceivedUnrequestedTx := events.NewClosure(func(unrequestedTx *gossip.UnrequestedTransaction) {
+		receiveTxWorkerPool.Submit(unrequestedTx.Tx, unrequestedTx)
 	})
 
 	daemon.BackgroundWorker("TangleProcessor[ReceiveTx]", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting TangleProcessor[ReceiveTx] ... done")
 		gossip.Events.ReceivedTransaction.Attach(notifyReceivedTx)
+		gossip.Events.ReceivedUnrequestedTx.Attach(notifyReceivedUnrequestedTx)
 		receiveTxWorkerPool.Start()
+		deprioritizedTxWorkerPool.Start()
 		<-shutdownSignal
 		log.Info("Stopping TangleProcessor[ReceiveTx] ...")
 		gossip.Events.ReceivedTransaction.Detach(notifyReceivedTx)
+		gossip.Events.ReceivedUnrequestedTx.Detach(notifyReceivedUnrequestedTx)
 		receiveTxWorkerPool.StopAndWait()
+		deprioritizedTxWorkerPool.StopAndWait()
 		log.Info("Stopping TangleProcessor[ReceiveTx] ... done")
 	}, shutdown.ShutdownPriorityReceiveTxWorker)
 
@@ -100,11 +123,30 @@ func runTangleProcessor(plugin *node.Plugin) {
 	}, shutdown.ShutdownPriorityMilestoneSolidifier)
 }
 
-func processIncomingTx(plugin *node.Plugin, transaction *hornet.Transaction) {
+func processIncomingTx(plugin *node.Plugin, transaction *hornet.Transaction, unrequestedTx *gossip.UnrequestedTransaction) {
 
 	txHash := transaction.GetHash()
 	known, _ := tangle.ContainsTransaction(txHash)
 
+	if unrequestedTx != nil {
+		// unrequested transactions have to pass the transaction policy, known transactions were already accepted before
+		verdict := policy.VerdictAccept
+		if !known {
+			verdict = policy.Evaluate(transaction, unrequestedTx.Source)
+		}
+		unrequestedTx.SetVerdict(verdict)
+
+		switch verdict {
+		case policy.VerdictReject:
+			return
+
+		case policy.VerdictDeprioritize:
+			// deprioritized transactions are processed if there are free resources, but never broadcasted
+			deprioritizedTxWorkerPool.TrySubmit(transaction)
+			return
+		}
+	}
+
 	if !known {
 		server.SharedServerMetrics.IncrNewTransactionsCount()
 		// ToDo: Bundle should be added before storing the tx in cache, so that the solidifier can't solidify
@@ -149,6 +191,7 @@ func processIncomingTx(plugin *node.Plugin, transaction *hornet.Transaction) {
 								markedSpentAddrs.Inc()
 							}
 						}
+						detectConflicts(bundle, latestMilestoneIndex)
 					} else {
 						// Milestone bundles itself do not mutate the ledger
 						// => Check bundle for a milestone
@@ -0,0 +1,45 @@
+package webapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+func init() {
+	addEndpoint("createBackup", createBackup, implementedAPIcalls)
+}
+
+func createBackup(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	cb := &CreateBackup{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, cb)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	info, err := tanglePlugin.CreateBackupInBackground(cb.Incremental)
+	if err != nil {
+		e.Error = err.Error()
+		if errors.Cause(err) == tanglePlugin.ErrBackupAlreadyRunning {
+			c.JSON(http.StatusConflict, e)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, &CreateBackupReturn{
+		FilePath:       info.FilePath,
+		MilestoneIndex: uint32(info.MilestoneIndex),
+		Version:        info.Version,
+		Since:          info.Since,
+	})
+}
@@ -1,19 +1,31 @@
 package webapi
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
 
 	"github.com/iotaledger/iota.go/address"
 
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
 	"github.com/gohornet/hornet/packages/model/tangle"
 )
 
+const (
+	balanceHistoryDefaultLimit = 100
+	balanceHistoryMaxLimit     = 1000
+)
+
 func init() {
 	addEndpoint("getBalances", getBalances, implementedAPIcalls)
+	addEndpoint("getBalancesAt", getBalancesAt, implementedAPIcalls)
+	addEndpoint("getAddressBalanceHistory", getAddressBalanceHistory, implementedAPIcalls)
 }
 
 func getBalances(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
@@ -27,6 +39,10 @@ func getBalances(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
 		return
 	}
 
+	if ledgerInvariantViolated(c) {
+		return
+	}
+
 	if len(gb.Addresses) == 0 {
 		e.Error = "No addresses provided"
 		c.JSON(http.StatusBadRequest, e)
@@ -77,3 +93,120 @@ func getBalances(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
 	gbr.References = []string{lsm.GetMilestoneHash()}
 	c.JSON(http.StatusOK, gbr)
 }
+
+func getBalancesAt(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	gba := &GetBalancesAt{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, gba)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if ledgerInvariantViolated(c) {
+		return
+	}
+
+	if len(gba.Addresses) == 0 {
+		e.Error = "No addresses provided"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	var addresses []trinary.Hash
+	for _, addr := range gba.Addresses {
+		// Check if address is valid
+		if err := address.ValidAddress(addr); err != nil {
+			e.Error = "Invalid address: " + addr
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		addresses = append(addresses, addr[:81])
+	}
+
+	targetIndex := milestone_index.MilestoneIndex(gba.MilestoneIndex)
+
+	balances, err := tangle.GetBalancesAtMilestone(addresses, targetIndex, abortSignal)
+	if err != nil {
+		switch errors.Cause(err) {
+		case tangle.ErrMilestoneBelowPruningIndex, tangle.ErrMilestoneAboveSolidIndex:
+			e.Error = fmt.Sprintf("Invalid milestone index supplied: %v", err)
+			c.JSON(http.StatusBadRequest, e)
+		default:
+			e.Error = errors.Wrap(err, "Internal error").Error()
+			c.JSON(http.StatusInternalServerError, e)
+		}
+		return
+	}
+
+	gbar := &GetBalancesAtReturn{MilestoneIndex: gba.MilestoneIndex}
+
+	for _, addr := range addresses {
+		gbar.Balances = append(gbar.Balances, strconv.FormatUint(balances[addr], 10))
+	}
+
+	// The milestone itself may already be pruned
+	ms, _ := tangle.GetMilestone(targetIndex)
+	if ms != nil {
+		gbar.References = []string{ms.GetMilestoneHash()}
+	}
+
+	c.JSON(http.StatusOK, gbar)
+}
+
+func getAddressBalanceHistory(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	gabh := &GetAddressBalanceHistory{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, gabh)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if ledgerInvariantViolated(c) {
+		return
+	}
+
+	if err := address.ValidAddress(gabh.Address); err != nil {
+		e.Error = "Invalid address: " + gabh.Address
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	limit := gabh.Limit
+	if limit == 0 {
+		limit = balanceHistoryDefaultLimit
+	}
+	if limit < 0 || limit > balanceHistoryMaxLimit {
+		e.Error = fmt.Sprintf("Invalid limit supplied, maximum: %d", balanceHistoryMaxLimit)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	changes, nextIndex, err := tangle.GetBalanceHistoryForAddress(gabh.Address[:81], milestone_index.MilestoneIndex(gabh.StartIndex), limit)
+	if err != nil {
+		e.Error = errors.Wrap(err, "Internal error").Error()
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	gabhr := &GetAddressBalanceHistoryReturn{
+		Address:   gabh.Address[:81],
+		Changes:   []*BalanceChange{},
+		NextIndex: uint32(nextIndex),
+	}
+
+	for _, change := range changes {
+		gabhr.Changes = append(gabhr.Changes, &BalanceChange{
+			MilestoneIndex: uint32(change.MilestoneIndex),
+			Change:         change.Change,
+			Balance:        strconv.FormatUint(change.Balance, 10),
+		})
+	}
+
+	c.JSON(http.StatusOK, gabhr)
+}
@@ -0,0 +1,542 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/iotaledger/iota.go/address"
+	"github.com/iotaledger/iota.go/bundle"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/signing"
+	"github.com/iotaledger/iota.go/transaction"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/packages/model/tangle"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+func init() {
+	addEndpoint("validateBundle", validateBundle, implementedAPIcalls)
+	addEndpoint("prepareBundleEssence", prepareBundleEssence, implementedAPIcalls)
+	addEndpoint("finalizeSignedBundle", finalizeSignedBundle, implementedAPIcalls)
+}
+
+// validateBundle checks the given bundle trytes and simulates the ledger mutations against the current solid ledger.
+// nothing is stored or broadcasted.
+func validateBundle(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	vb := &ValidateBundle{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, vb)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if len(vb.Trytes) == 0 {
+		e.Error = "No trytes provided"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	for _, trytes := range vb.Trytes {
+		if err := trinary.ValidTrytes(trytes); err != nil || len(trytes) != consts.TransactionTrytesSize {
+			e.Error = "Trytes invalid"
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+	}
+
+	if !tangle.IsNodeSynced() {
+		e.Error = "Node not synced"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	txs, err := transaction.AsTransactionObjects(vb.Trytes, nil)
+	if err != nil {
+		e.Error = fmt.Sprint(err)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, validateBundleTransactions(txs))
+}
+
+// validateBundleTransactions checks the structure, the signatures and the ledger mutations of the given transactions.
+func validateBundleTransactions(txs transaction.Transactions) *ValidateBundleReturn {
+
+	result := &ValidateBundleReturn{}
+
+	// the trytes may be given in any order (e.g. reversed like the result of attachToTangle)
+	bndl := make(bundle.Bundle, len(txs))
+	copy(bndl, txs)
+	sort.Slice(bndl, func(i, j int) bool { return bndl[i].CurrentIndex < bndl[j].CurrentIndex })
+
+	result.BundleHash = bndl[0].Bundle
+	for _, tx := range bndl {
+		if tx.Bundle != result.BundleHash {
+			result.Info = fmt.Sprintf("bundle hash mismatch: tx %d has bundle hash %s", tx.CurrentIndex, tx.Bundle)
+			return result
+		}
+	}
+
+	// validate bundle semantics and signatures
+	if err := bundle.ValidBundle(bndl); err != nil {
+		if err != consts.ErrInvalidSignature {
+			result.Info = fmt.Sprint(err)
+			return result
+		}
+		result.Info = "invalid signature"
+		result.InvalidSignatures = invalidBundleSignatures(bndl)
+		return result
+	}
+
+	ledgerChanges := map[trinary.Hash]int64{}
+	for _, tx := range bndl {
+		if tx.Value == 0 {
+			continue
+		}
+		ledgerChanges[tx.Address] += tx.Value
+	}
+
+	tangle.ReadLockLedger()
+	defer tangle.ReadUnlockLedger()
+
+	// the diff of the not yet confirmed cone referenced by the bundle
+	diff := map[trinary.Hash]int64{}
+	approved := map[trinary.Hash]struct{}{}
+	for _, solidEntryPoint := range tangle.GetSolidEntryPointsHashes() {
+		approved[solidEntryPoint] = struct{}{}
+	}
+
+	// it is safe to cache the below max depth flag of transactions as long as the same milestone is solid.
+	tanglePlugin.BelowDepthMemoizationCache.ResetIfNewerMilestone(tangle.GetSolidMilestoneIndex())
+
+	result.ConeChecked = true
+	for _, ref := range externalBundleReferences(bndl) {
+		if _, alreadyApproved := approved[ref]; alreadyApproved {
+			continue
+		}
+
+		// unattached bundles or unknown references can't be checked
+		refTx, err := tangle.GetTransaction(ref)
+		if err != nil {
+			log.Panic(err)
+		}
+		if refTx == nil || !refTx.IsSolid() {
+			result.ConeChecked = false
+			continue
+		}
+
+		// the references are not necessarily tails, so the cones of the bundles they belong to are checked
+		bundleBucket, err := tangle.GetBundleBucket(refTx.Tx.Bundle)
+		if err != nil {
+			log.Panic(err)
+		}
+		if bundleBucket == nil {
+			result.ConeChecked = false
+			continue
+		}
+
+		refBundles := bundleBucket.GetBundlesOfTransaction(ref)
+		if len(refBundles) == 0 {
+			result.ConeChecked = false
+			continue
+		}
+
+		for _, refBundle := range refBundles {
+			if !refBundle.IsComplete() {
+				result.ConeChecked = false
+				continue
+			}
+
+			if !refBundle.IsValid() {
+				result.Info = fmt.Sprintf("referenced bundle is invalid: %s", ref)
+				return result
+			}
+
+			if _, alreadyApproved := approved[refBundle.GetTailHash()]; alreadyApproved {
+				continue
+			}
+
+			if !tanglePlugin.CheckConsistencyOfConeAndMutateDiff(refBundle.GetTailHash(), approved, diff) {
+				result.Info = fmt.Sprintf("referenced cone is not consistent: %s", ref)
+				return result
+			}
+		}
+	}
+
+	for addr, change := range ledgerChanges {
+		if change >= 0 {
+			continue
+		}
+
+		balance, _, err := tangle.GetBalanceForAddressWithoutLocking(addr)
+		if err != nil {
+			log.Panic(err)
+		}
+
+		if int64(balance)+diff[addr]+change < 0 {
+			result.Overspends = append(result.Overspends, &BundleOverspend{
+				Address:  addr,
+				Balance:  balance,
+				ConeDiff: diff[addr],
+				Change:   change,
+			})
+		}
+	}
+
+	if len(result.Overspends) > 0 {
+		result.Info = "inputs would lead to an inconsistent ledger state"
+		return result
+	}
+
+	result.Valid = true
+	return result
+}
+
+// invalidBundleSignatures returns the addresses of all inputs with an invalid signature.
+func invalidBundleSignatures(bndl bundle.Bundle) []trinary.Hash {
+	var invalid []trinary.Hash
+
+	for i := range bndl {
+		tx := &bndl[i]
+		if tx.Value >= 0 {
+			continue
+		}
+
+		// collect the signature message fragments of the adjacent txs of this input, like the node does
+		fragments := []trinary.Trytes{tx.SignatureMessageFragment}
+		for j := i + 1; j < len(bndl); j++ {
+			if bndl[j].Value != 0 || bndl[j].Address != tx.Address {
+				break
+			}
+			fragments = append(fragments, bndl[j].SignatureMessageFragment)
+		}
+
+		if valid, err := signing.ValidateSignatures(tx.Address, fragments, tx.Bundle); err != nil || !valid {
+			invalid = append(invalid, tx.Address)
+		}
+	}
+
+	return invalid
+}
+
+// externalBundleReferences returns the trunk and branch hashes which are not part of the bundle itself.
+// unattached bundles don't reference any transactions.
+func externalBundleReferences(bndl bundle.Bundle) []trinary.Hash {
+	nullHash := trinary.Hash(consts.NullHashTrytes)
+
+	inBundle := make(map[trinary.Hash]struct{}, len(bndl))
+	for _, tx := range bndl {
+		inBundle[tx.Hash] = struct{}{}
+	}
+
+	seen := make(map[trinary.Hash]struct{})
+	var refs []trinary.Hash
+	for _, tx := range bndl {
+		for _, ref := range []trinary.Hash{tx.TrunkTransaction, tx.BranchTransaction} {
+			if ref == nullHash {
+				continue
+			}
+			if _, has := inBundle[ref]; has {
+				continue
+			}
+			if _, has := seen[ref]; has {
+				continue
+			}
+			seen[ref] = struct{}{}
+			refs = append(refs, ref)
+		}
+	}
+
+	return refs
+}
+
+// prepareBundleEssence builds an unsigned bundle out of the given inputs and outputs.
+// the full balance of every input is spent, the remainder is sent to the remainder address.
+func prepareBundleEssence(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	pbe := &PrepareBundleEssence{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, pbe)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if len(pbe.Inputs) == 0 {
+		e.Error = "No inputs provided"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	if len(pbe.Outputs) == 0 {
+		e.Error = "No outputs provided"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	if !tangle.IsNodeSynced() {
+		e.Error = "Node not synced"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	timestamp := uint64(time.Now().Unix())
+	inputAddresses := make(map[trinary.Hash]struct{})
+
+	var outputSum uint64
+	transfers := make(bundle.Transfers, 0, len(pbe.Outputs))
+	for _, output := range pbe.Outputs {
+		if err := address.ValidAddress(output.Address); err != nil {
+			e.Error = fmt.Sprintf("Provided address invalid: %s", output.Address)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		if len(output.Tag) > consts.TagTrinarySize/3 || (output.Tag != "" && trinary.ValidTrytes(output.Tag) != nil) {
+			e.Error = fmt.Sprintf("Provided tag invalid: %s", output.Tag)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		if output.Message != "" && trinary.ValidTrytes(output.Message) != nil {
+			e.Error = "Provided message invalid"
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		if output.Value > 0 && tangle.WasAddressSpentFrom(output.Address[:consts.HashTrytesSize]) {
+			e.Error = fmt.Sprintf("Output address was already spent from: %s", output.Address)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		outputSum += output.Value
+		transfers = append(transfers, bundle.Transfer{
+			Address: output.Address,
+			Value:   output.Value,
+			Message: output.Message,
+			Tag:     output.Tag,
+		})
+	}
+
+	entries, err := bundle.TransfersToBundleEntries(timestamp, transfers...)
+	if err != nil {
+		e.Error = fmt.Sprint(err)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	var inputSum uint64
+	for _, input := range pbe.Inputs {
+		if err := address.ValidAddress(input.Address); err != nil {
+			e.Error = fmt.Sprintf("Provided address invalid: %s", input.Address)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		addr := input.Address[:consts.HashTrytesSize]
+
+		if input.Security < int(consts.SecurityLevelLow) || input.Security > int(consts.SecurityLevelHigh) {
+			e.Error = fmt.Sprintf("Invalid security level for input %s: %d", input.Address, input.Security)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		if _, exists := inputAddresses[addr]; exists {
+			e.Error = fmt.Sprintf("Input address used twice: %s", input.Address)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		inputAddresses[addr] = struct{}{}
+
+		if tangle.WasAddressSpentFrom(addr) {
+			e.Error = fmt.Sprintf("Input address was already spent from: %s", input.Address)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		balance, _, err := tangle.GetBalanceForAddress(addr)
+		if err != nil {
+			e.Error = "Internal error"
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+
+		if balance == 0 {
+			e.Error = fmt.Sprintf("Input address has no balance: %s", input.Address)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		inputSum += balance
+		entries = append(entries, bundle.BundleEntry{
+			Address:   addr,
+			Value:     -int64(balance),
+			Length:    uint64(input.Security),
+			Timestamp: timestamp,
+		})
+	}
+
+	if inputSum < outputSum {
+		e.Error = fmt.Sprintf("Insufficient balance: inputs %d, outputs %d", inputSum, outputSum)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	remainder := inputSum - outputSum
+	if remainder > 0 {
+		if err := address.ValidAddress(pbe.RemainderAddress); err != nil {
+			e.Error = fmt.Sprintf("Remainder of %d needs a valid remainder address", remainder)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		remainderAddr := pbe.RemainderAddress[:consts.HashTrytesSize]
+
+		if _, isInput := inputAddresses[remainderAddr]; isInput || tangle.WasAddressSpentFrom(remainderAddr) {
+			e.Error = fmt.Sprintf("Remainder address was already spent from: %s", pbe.RemainderAddress)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		entries = append(entries, bundle.BundleEntry{
+			Address:   remainderAddr,
+			Value:     int64(remainder),
+			Timestamp: timestamp,
+		})
+	}
+
+	bndl := bundle.Bundle{}
+	for _, entry := range entries {
+		bndl = bundle.AddEntry(bndl, entry)
+	}
+
+	bndl, err = bundle.Finalize(bndl)
+	if err != nil {
+		e.Error = fmt.Sprint(err)
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, PrepareBundleEssenceReturn{
+		BundleHash: bndl[0].Bundle,
+		Trytes:     bundleToAttachTrytes(bndl),
+		Remainder:  remainder,
+	})
+}
+
+// finalizeSignedBundle adds the given signatures to the unsigned bundle and validates the result.
+// nothing is stored or broadcasted, the signed trytes can be passed to attachToTangle.
+func finalizeSignedBundle(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	fsb := &FinalizeSignedBundle{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, fsb)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if len(fsb.Trytes) == 0 {
+		e.Error = "No trytes provided"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	for _, trytes := range fsb.Trytes {
+		if trinary.ValidTrytes(trytes) != nil || len(trytes) != consts.TransactionTrytesSize {
+			e.Error = "Trytes invalid"
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+	}
+
+	if !tangle.IsNodeSynced() {
+		e.Error = "Node not synced"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	txs, err := transaction.AsTransactionObjects(fsb.Trytes, nil)
+	if err != nil {
+		e.Error = fmt.Sprint(err)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	bndl := bundle.Bundle(txs)
+	sort.Slice(bndl, func(i, j int) bool { return bndl[i].CurrentIndex < bndl[j].CurrentIndex })
+
+	for _, sig := range fsb.Signatures {
+		if err := address.ValidAddress(sig.Address); err != nil {
+			e.Error = fmt.Sprintf("Provided address invalid: %s", sig.Address)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+
+		if err := addBundleSignature(bndl, sig.Address[:consts.HashTrytesSize], sig.SignatureMessageFragments); err != nil {
+			e.Error = err.Error()
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, FinalizeSignedBundleReturn{
+		Trytes:     bundleToAttachTrytes(bndl),
+		Validation: validateBundleTransactions(transaction.Transactions(bndl)),
+	})
+}
+
+// addBundleSignature sets the signature message fragments of the input with the given address.
+// the first fragment belongs to the input transaction, the others to the subsequent zero value transactions of the same address.
+func addBundleSignature(bndl bundle.Bundle, addr trinary.Hash, fragments []trinary.Trytes) error {
+	var txIndexes []int
+	for i := range bndl {
+		if bndl[i].Address != addr {
+			continue
+		}
+		if len(txIndexes) == 0 && bndl[i].Value >= 0 {
+			continue
+		}
+		if len(txIndexes) > 0 && bndl[i].Value != 0 {
+			break
+		}
+		txIndexes = append(txIndexes, i)
+	}
+
+	if len(txIndexes) == 0 {
+		return fmt.Errorf("no input found for address %s", addr)
+	}
+
+	if len(fragments) != len(txIndexes) {
+		return fmt.Errorf("input %s needs %d signature fragments, got %d", addr, len(txIndexes), len(fragments))
+	}
+
+	for i, fragment := range fragments {
+		if trinary.ValidTrytes(fragment) != nil || len(fragment) != consts.SignatureMessageFragmentSizeInTrytes {
+			return fmt.Errorf("invalid signature fragment %d for input %s", i, addr)
+		}
+		bndl[txIndexes[i]].SignatureMessageFragment = fragment
+	}
+
+	return nil
+}
+
+// bundleToAttachTrytes returns the trytes of the bundle in the order expected by attachToTangle (head first).
+func bundleToAttachTrytes(bndl bundle.Bundle) []trinary.Trytes {
+	trytes := make([]trinary.Trytes, len(bndl))
+	for i := range bndl {
+		trytes[len(bndl)-1-i] = transaction.MustTransactionToTrytes(&bndl[i])
+	}
+	return trytes
+}
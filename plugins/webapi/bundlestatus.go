@@ -0,0 +1,93 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/iotaledger/iota.go/guards"
+
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/parameter"
+)
+
+func init() {
+	addEndpoint("getBundleStatus", getBundleStatus, implementedAPIcalls)
+}
+
+func getBundleStatus(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	gbs := &GetBundleStatus{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, gbs)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if len(gbs.Bundles) == 0 {
+		e.Error = "No bundle hashes provided"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	maxFindTransactions := parameter.NodeConfig.GetInt("api.maxFindTransactions")
+	if len(gbs.Bundles) > maxFindTransactions {
+		e.Error = "Too many bundle hashes. Max. allowed: " + strconv.Itoa(maxFindTransactions)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	for _, bundleHash := range gbs.Bundles {
+		if !guards.IsHash(bundleHash) {
+			e.Error = fmt.Sprintf("Invalid bundle hash supplied: %s", bundleHash)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+	}
+
+	result := &GetBundleStatusReturn{}
+	for _, bundleHash := range gbs.Bundles {
+		bundleBucket, err := tangle.GetBundleBucket(bundleHash)
+		if err != nil || bundleBucket == nil {
+			e.Error = "Internal error"
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+
+		tailStatus, err := bundleBucket.GetTailStatus()
+		if err != nil {
+			e.Error = "Internal error"
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+
+		// oldest attachment first
+		sort.Slice(tailStatus, func(i, j int) bool { return tailStatus[i].FirstSeenTimestamp < tailStatus[j].FirstSeenTimestamp })
+
+		status := &BundleStatus{BundleHash: bundleHash, Tails: make([]*BundleTailInfo, 0, len(tailStatus))}
+		for _, tail := range tailStatus {
+			if tail.IsConfirmed() {
+				status.ConfirmedTail = tail.TailHash
+				status.ConfirmationIndex = uint32(tail.ConfirmationIndex)
+			}
+			status.Tails = append(status.Tails, &BundleTailInfo{
+				TailHash:                      tail.TailHash,
+				FirstSeenTimestamp:            tail.FirstSeenTimestamp,
+				FirstSeenLatestMilestoneIndex: uint32(tail.FirstSeenLatestMilestoneIndex),
+				Complete:                      tail.Complete,
+				Valid:                         tail.Valid,
+				Confirmed:                     tail.IsConfirmed(),
+				ConfirmationIndex:             uint32(tail.ConfirmationIndex),
+			})
+		}
+		result.Bundles = append(result.Bundles, status)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
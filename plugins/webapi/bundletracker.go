@@ -0,0 +1,141 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/iotaledger/iota.go/guards"
+
+	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/plugins/bundletracker"
+)
+
+func init() {
+	addEndpoint("trackBundles", trackBundles, implementedAPIcalls)
+	addEndpoint("untrackBundles", untrackBundles, implementedAPIcalls)
+	addEndpoint("getTrackedBundles", getTrackedBundles, implementedAPIcalls)
+}
+
+func trackBundles(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	tb := &TrackBundles{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, tb)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if len(tb.Tails) == 0 {
+		e.Error = "No tails provided"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	for _, tail := range tb.Tails {
+		if !guards.IsTransactionHash(tail) {
+			e.Error = fmt.Sprintf("Invalid tail hash supplied: %s", tail)
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+	}
+
+	result := &GetTrackedBundlesReturn{}
+	for _, tail := range tb.Tails {
+		trackedBundle, err := bundletracker.TrackBundle(tail)
+		if err != nil {
+			e.Error = err.Error()
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		result.TrackedBundles = append(result.TrackedBundles, newTrackedBundleStatus(trackedBundle))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func untrackBundles(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	ub := &UntrackBundles{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, ub)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if len(ub.Tails) == 0 {
+		e.Error = "No tails provided"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	for _, tail := range ub.Tails {
+		if err := bundletracker.UntrackBundle(tail); err != nil {
+			e.Error = err.Error()
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, UntrackBundlesReturn{})
+}
+
+func getTrackedBundles(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	gtb := &GetTrackedBundles{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, gtb)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	var trackedBundles []*tangle.TrackedBundle
+
+	// return all tracked bundles if no tails are given
+	if len(gtb.Tails) == 0 {
+		trackedBundles, err = bundletracker.GetTrackedBundles()
+		if err != nil {
+			e.Error = err.Error()
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+	}
+
+	for _, tail := range gtb.Tails {
+		trackedBundle, err := bundletracker.GetTrackedBundle(tail)
+		if err != nil {
+			e.Error = err.Error()
+			c.JSON(http.StatusBadRequest, e)
+			return
+		}
+		trackedBundles = append(trackedBundles, trackedBundle)
+	}
+
+	result := &GetTrackedBundlesReturn{TrackedBundles: make([]*TrackedBundleStatus, 0, len(trackedBundles))}
+	for _, trackedBundle := range trackedBundles {
+		result.TrackedBundles = append(result.TrackedBundles, newTrackedBundleStatus(trackedBundle))
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func newTrackedBundleStatus(trackedBundle *tangle.TrackedBundle) *TrackedBundleStatus {
+	return &TrackedBundleStatus{
+		TailHash:          trackedBundle.TailHash,
+		BundleHash:        trackedBundle.BundleHash,
+		LatestTailHash:    trackedBundle.LatestTailHash,
+		RegisteredAt:      trackedBundle.RegisteredAt,
+		Promotions:        trackedBundle.Promotions,
+		Reattachments:     trackedBundle.Reattachments,
+		Confirmed:         trackedBundle.IsConfirmed(),
+		ConfirmationIndex: uint32(trackedBundle.ConfirmationIndex),
+	}
+}
@@ -0,0 +1,64 @@
+package webapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gohornet/hornet/packages/model/tangle"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+func init() {
+	addEndpoint("getConflicts", getConflicts, implementedAPIcalls)
+}
+
+func getConflicts(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	e := ErrorReturn{}
+
+	result := &GetConflictsReturn{Conflicts: []*ConflictInfo{}}
+	for _, conflict := range tanglePlugin.GetConflicts() {
+		conflictInfo := &ConflictInfo{
+			Address:    conflict.Address,
+			DetectedAt: conflict.DetectedAt,
+			Status:     "pending",
+		}
+
+		for _, spend := range conflict.Spends {
+			bundleBucket, err := tangle.GetBundleBucket(spend.BundleHash)
+			if err != nil || bundleBucket == nil {
+				e.Error = "Internal error"
+				c.JSON(http.StatusInternalServerError, e)
+				return
+			}
+
+			spendInfo := &ConflictingSpendInfo{
+				BundleHash: spend.BundleHash,
+				Value:      spend.Value,
+				Tails:      []string{},
+			}
+
+			for _, bndl := range bundleBucket.Bundles() {
+				spendInfo.Tails = append(spendInfo.Tails, bndl.GetTailHash())
+				if !bndl.IsConfirmed() {
+					continue
+				}
+				spendInfo.Confirmed = true
+				if _, at := bndl.GetTail().GetConfirmed(); at != 0 {
+					spendInfo.ConfirmationIndex = uint32(at)
+				}
+			}
+
+			// only one of the conflicting bundles can ever be confirmed
+			if spendInfo.Confirmed {
+				conflictInfo.Status = "resolved"
+			}
+
+			conflictInfo.Spends = append(conflictInfo.Spends, spendInfo)
+		}
+
+		result.Conflicts = append(result.Conflicts, conflictInfo)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,55 @@
+package webapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+func init() {
+	addEndpoint("compactDatabase", compactDatabase, implementedAPIcalls)
+	addEndpoint("getCompactionStatus", getCompactionStatus, implementedAPIcalls)
+}
+
+func compactDatabase(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	cd := &CompactDatabase{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, cd)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	status, err := tanglePlugin.CompactDatabase()
+	if err != nil {
+		e.Error = err.Error()
+		if errors.Cause(err) == tanglePlugin.ErrCompactionAlreadyRunning {
+			c.JSON(http.StatusConflict, e)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, &CompactDatabaseReturn{Compaction: status})
+}
+
+func getCompactionStatus(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	gcs := &GetCompactionStatus{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, gcs)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, &CompactDatabaseReturn{Compaction: tanglePlugin.GetCompactionStatus()})
+}
@@ -7,10 +7,12 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	hornetDB "github.com/gohornet/hornet/packages/database"
 	"github.com/gohornet/hornet/packages/model/tangle"
 	"github.com/gohornet/hornet/packages/parameter"
 	"github.com/gohornet/hornet/plugins/cli"
 	"github.com/gohornet/hornet/plugins/gossip"
+	"github.com/gohornet/hornet/plugins/metrics"
 )
 
 func init() {
@@ -85,6 +87,13 @@ func getNodeInfo(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
 	// Coo addr
 	info.CoordinatorAddress = parameter.NodeConfig.GetString("milestones.coordinator")
 
+	// Database size and value log GC
+	if dbMetrics := metrics.GetDatabaseMetrics(); dbMetrics != nil {
+		info.DatabaseSizeLSM = dbMetrics.LSMSize
+		info.DatabaseSizeValueLog = dbMetrics.VlogSize
+	}
+	info.LastValueLogGC = hornetDB.GetLastGarbageCollectionResult()
+
 	// Return node info
 	c.JSON(http.StatusOK, info)
 }
@@ -0,0 +1,111 @@
+package webapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/gohornet/hornet/packages/model/tangle"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+func init() {
+	addEndpoint("auditLedger", auditLedger, implementedAPIcalls)
+	addEndpoint("getLedgerAuditStatus", getLedgerAuditStatus, implementedAPIcalls)
+	addEndpoint("resolveLedgerAudit", resolveLedgerAudit, implementedAPIcalls)
+}
+
+// ledgerInvariantViolated writes an error and returns true if balances must not be served.
+func ledgerInvariantViolated(c *gin.Context) bool {
+	violation := tangle.GetLedgerInvariantViolation()
+	if violation == "" {
+		return false
+	}
+
+	e := ErrorReturn{Error: "Ledger invariant violated, balances are not served: " + violation}
+	c.JSON(http.StatusServiceUnavailable, e)
+	return true
+}
+
+func auditLedger(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	al := &AuditLedger{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, al)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	result, err := tanglePlugin.AuditLedger(abortSignal)
+	if err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, ledgerAuditResultToReturn(result))
+}
+
+func getLedgerAuditStatus(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	glas := &GetLedgerAuditStatus{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, glas)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	violation := tangle.GetLedgerInvariantViolation()
+	c.JSON(http.StatusOK, &GetLedgerAuditStatusReturn{
+		Violated:  violation != "",
+		Violation: violation,
+	})
+}
+
+// resolveLedgerAudit allows the operator to serve balances again after a violation was detected.
+// The ledger is recounted first, the violation is only reset if the recount succeeds or if it is forced.
+func resolveLedgerAudit(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	rla := &ResolveLedgerAudit{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, rla)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if !rla.Force {
+		result, err := tangle.AuditLedger(abortSignal)
+		if err != nil {
+			e.Error = err.Error()
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+
+		if len(result.Violations) > 0 {
+			c.JSON(http.StatusConflict, ledgerAuditResultToReturn(result))
+			return
+		}
+	}
+
+	tangle.ResetLedgerInvariantViolation()
+	log.Warn("Ledger invariant violation was resolved by the operator")
+
+	c.JSON(http.StatusOK, &GetLedgerAuditStatusReturn{})
+}
+
+func ledgerAuditResultToReturn(result *tangle.LedgerAuditResult) *AuditLedgerReturn {
+	return &AuditLedgerReturn{
+		MilestoneIndex: uint32(result.MilestoneIndex),
+		AddressCount:   result.AddressCount,
+		Supply:         result.Supply,
+		Violations:     result.Violations,
+		Valid:          len(result.Violations) == 0,
+	}
+}
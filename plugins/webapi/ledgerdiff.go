@@ -1,6 +1,7 @@
 package webapi
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -10,11 +11,13 @@ import (
 
 	"github.com/gohornet/hornet/packages/model/milestone_index"
 	"github.com/gohornet/hornet/packages/model/tangle"
+	"github.com/gohornet/hornet/packages/parameter"
 )
 
 func init() {
 	addEndpoint("getLedgerDiff", getLedgerDiff, implementedAPIcalls)
 	addEndpoint("getLedgerDiffExt", getLedgerDiffExt, implementedAPIcalls)
+	addEndpoint("streamLedgerDiffs", streamLedgerDiffs, implementedAPIcalls)
 }
 
 func getLedgerDiff(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
@@ -87,6 +90,120 @@ func getLedgerDiffExt(i interface{}, c *gin.Context, abortSignal <-chan struct{}
 	c.JSON(http.StatusOK, ldr)
 }
 
+// streamLedgerDiffs streams the ledger diffs of a milestone range as NDJSON.
+// The last line contains the index to resume with, in case the range was limited or the stream was aborted.
+func streamLedgerDiffs(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	sld := &StreamLedgerDiffs{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, sld)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	snapshotInfo := tangle.GetSnapshotInfo()
+	if snapshotInfo == nil {
+		e.Error = "Snapshot info not found"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	smi := tangle.GetSolidMilestoneIndex()
+	fromIndex := milestone_index.MilestoneIndex(sld.FromIndex)
+	toIndex := milestone_index.MilestoneIndex(sld.ToIndex)
+	if toIndex == 0 {
+		toIndex = smi
+	}
+
+	if fromIndex <= snapshotInfo.PruningIndex {
+		e.Error = fmt.Sprintf("Invalid fromIndex supplied, ledger diffs are pruned up to %d", snapshotInfo.PruningIndex)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	if toIndex > smi {
+		e.Error = fmt.Sprintf("Invalid toIndex supplied, lsmi is %d", smi)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	if fromIndex > toIndex {
+		e.Error = "Invalid range supplied, fromIndex is greater than toIndex"
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	// the client has to resume with the returned nextIndex if the range is too big
+	maxLedgerDiffRange := milestone_index.MilestoneIndex(parameter.NodeConfig.GetInt("api.maxLedgerDiffRange"))
+	lastIndex := toIndex
+	if lastIndex-fromIndex+1 > maxLedgerDiffRange {
+		lastIndex = fromIndex + maxLedgerDiffRange - 1
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+
+	jsonEncoder := json.NewEncoder(c.Writer)
+
+	nextIndex := fromIndex
+	for ; nextIndex <= lastIndex; nextIndex++ {
+		select {
+		case <-abortSignal:
+			writeLedgerDiffStreamEnd(c, jsonEncoder, nextIndex, toIndex)
+			return
+		case <-c.Request.Context().Done():
+			// the client closed the connection
+			return
+		default:
+		}
+
+		entry, err := getLedgerDiffStreamEntry(nextIndex, sld.IncludeBundles, abortSignal)
+		if err != nil {
+			jsonEncoder.Encode(&LedgerDiffStreamError{Type: "error", MilestoneIndex: uint64(nextIndex), Error: err.Error()})
+			break
+		}
+
+		if err := jsonEncoder.Encode(entry); err != nil {
+			return
+		}
+		c.Writer.Flush()
+	}
+
+	writeLedgerDiffStreamEnd(c, jsonEncoder, nextIndex, toIndex)
+}
+
+func getLedgerDiffStreamEntry(milestoneIndex milestone_index.MilestoneIndex, includeBundles bool, abortSignal <-chan struct{}) (*LedgerDiffStreamEntry, error) {
+
+	entry := &LedgerDiffStreamEntry{Type: "diff", MilestoneIndex: uint64(milestoneIndex)}
+
+	if ms, _ := tangle.GetMilestone(milestoneIndex); ms != nil {
+		entry.MilestoneHash = ms.GetMilestoneHash()
+	}
+
+	diff, err := tangle.GetLedgerDiffForMilestone(milestoneIndex, abortSignal)
+	if err != nil {
+		return nil, err
+	}
+	entry.Diff = diff
+
+	if includeBundles {
+		_, confirmedBundlesWithValue, _, err := getMilestoneStateDiff(milestoneIndex)
+		if err != nil {
+			return nil, err
+		}
+		entry.ConfirmedBundlesWithValue = confirmedBundlesWithValue
+	}
+
+	return entry, nil
+}
+
+func writeLedgerDiffStreamEnd(c *gin.Context, jsonEncoder *json.Encoder, nextIndex milestone_index.MilestoneIndex, toIndex milestone_index.MilestoneIndex) {
+	jsonEncoder.Encode(&LedgerDiffStreamEnd{Type: "end", NextIndex: uint64(nextIndex), Complete: nextIndex > toIndex})
+	c.Writer.Flush()
+}
+
 func getMilestoneStateDiff(milestoneIndex milestone_index.MilestoneIndex) (confirmedTxWithValue []*TxHashWithValue, confirmedBundlesWithValue []*BundleWithValue, totalLedgerChanges map[string]int64, err error) {
 
 	reqMilestone, err := tangle.GetMilestone(milestoneIndex)
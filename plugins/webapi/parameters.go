@@ -46,4 +46,7 @@ func init() {
 	// "Set a maximum number of characters that the body of an API call may contain"
 	parameter.NodeConfig.SetDefault("api.maxBodyLength", 1000000)
 
+	// "Set a maximum number of milestones that may be returned by a single streamLedgerDiffs call"
+	parameter.NodeConfig.SetDefault("api.maxLedgerDiffRange", 10000)
+
 }
@@ -0,0 +1,17 @@
+package webapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gohornet/hornet/packages/policy"
+)
+
+func init() {
+	addEndpoint("getPolicyStatus", getPolicyStatus, implementedAPIcalls)
+}
+
+func getPolicyStatus(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	c.JSON(http.StatusOK, GetPolicyStatusReturn{Rules: policy.GetRuleStatus()})
+}
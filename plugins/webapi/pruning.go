@@ -0,0 +1,80 @@
+package webapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/plugins/snapshot"
+)
+
+func init() {
+	addEndpoint("pruneDatabase", pruneDatabase, implementedAPIcalls)
+	addEndpoint("abortPruning", abortPruning, implementedAPIcalls)
+	addEndpoint("getPruningStatus", getPruningStatus, implementedAPIcalls)
+}
+
+func pruneDatabase(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	pd := &PruneDatabase{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, pd)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	status, err := snapshot.PruneDatabase(milestone_index.MilestoneIndex(pd.TargetIndex))
+	if err != nil {
+		e.Error = err.Error()
+		switch errors.Cause(err) {
+		case snapshot.ErrPruningAlreadyRunning:
+			c.JSON(http.StatusConflict, e)
+		case snapshot.ErrPruningTargetTooOld, snapshot.ErrPruningTargetTooNew:
+			c.JSON(http.StatusBadRequest, e)
+		default:
+			c.JSON(http.StatusInternalServerError, e)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, GetPruningStatusReturn{PruningStatus: status})
+}
+
+func abortPruning(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	ap := &AbortPruning{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, ap)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if err := snapshot.AbortPruning(); err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, GetPruningStatusReturn{PruningStatus: snapshot.GetPruningStatus()})
+}
+
+func getPruningStatus(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	gps := &GetPruningStatus{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, gps)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, GetPruningStatusReturn{PruningStatus: snapshot.GetPruningStatus()})
+}
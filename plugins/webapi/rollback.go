@@ -0,0 +1,44 @@
+package webapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+
+	"github.com/gohornet/hornet/packages/model/milestone_index"
+	"github.com/gohornet/hornet/packages/model/tangle"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
+)
+
+func init() {
+	addEndpoint("rollbackLedger", rollbackLedger, implementedAPIcalls)
+}
+
+func rollbackLedger(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	rl := &RollbackLedger{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, rl)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	err = tanglePlugin.RollbackLedger(milestone_index.MilestoneIndex(rl.TargetIndex), abortSignal)
+	if err != nil {
+		switch errors.Cause(err) {
+		case tanglePlugin.ErrRollbackTargetTooNew, tanglePlugin.ErrRollbackTargetTooOld:
+			e.Error = err.Error()
+			c.JSON(http.StatusBadRequest, e)
+		default:
+			e.Error = err.Error()
+			c.JSON(http.StatusInternalServerError, e)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, &RollbackLedgerReturn{SolidMilestoneIndex: uint32(tangle.GetSolidMilestoneIndex())})
+}
@@ -1,6 +1,7 @@
 package webapi
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -14,6 +15,8 @@ import (
 func init() {
 	addEndpoint("getSnapshot", getSnapshot, implementedAPIcalls)
 	addEndpoint("createSnapshot", createSnapshot, implementedAPIcalls)
+	addEndpoint("exportLedger", exportLedger, implementedAPIcalls)
+	addEndpoint("listSnapshots", listSnapshots, implementedAPIcalls)
 }
 
 func getSnapshot(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
@@ -27,6 +30,10 @@ func getSnapshot(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
 		return
 	}
 
+	if ledgerInvariantViolated(c) {
+		return
+	}
+
 	snr := &GetSnapshotReturn{}
 
 	balances, index, err := tangle.GetAllBalances(abortSignal)
@@ -64,3 +71,74 @@ func createSnapshot(i interface{}, c *gin.Context, abortSignal <-chan struct{})
 
 	c.JSON(http.StatusOK, snr)
 }
+
+func exportLedger(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	el := &ExportLedger{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, el)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	if ledgerInvariantViolated(c) {
+		return
+	}
+
+	format := el.Format
+	if format == "" {
+		format = snapshot.LedgerExportFormatCSV
+	}
+
+	contentType := "text/csv"
+	switch format {
+	case snapshot.LedgerExportFormatCSV:
+	case snapshot.LedgerExportFormatNDJSON:
+		contentType = "application/x-ndjson"
+	default:
+		e.Error = fmt.Sprintf("Unknown format: %s", format)
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=ledger_export.%s", format))
+	c.Status(http.StatusOK)
+
+	if _, err := snapshot.ExportLedger(c.Writer, format, abortSignal); err != nil {
+		if !c.Writer.Written() {
+			// nothing was sent yet, e.g. if the supply of the ledger doesn't match
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			e.Error = err.Error()
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+
+		// the status was already sent, so errors can only be logged
+		log.Errorf("Ledger export failed: %v", err)
+	}
+}
+
+func listSnapshots(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	ls := &ListSnapshots{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, ls)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	snapshots, err := snapshot.ListLocalSnapshots()
+	if err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, ListSnapshotsReturn{Snapshots: snapshots})
+}
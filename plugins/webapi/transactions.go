@@ -13,6 +13,7 @@ import (
 
 	"github.com/gohornet/hornet/packages/model/tangle"
 	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/plugins/archive"
 	"github.com/gohornet/hornet/plugins/gossip"
 )
 
@@ -48,13 +49,17 @@ func broadcastTransactions(i interface{}, c *gin.Context, abortSignal <-chan str
 		}
 	}
 
-	for _, trytes := range bt.Trytes {
-		err = gossip.BroadcastTransactionFromAPI(trytes)
-		if err != nil {
-			e.Error = err.Error()
+	if err := gossip.BroadcastTransactionsFromAPI(bt.Trytes); err != nil {
+		e.Error = err.Error()
+		switch err {
+		case gossip.ErrTxRejectedByPolicy:
+			c.JSON(http.StatusForbidden, e)
+		case gossip.ErrTxPolicyTimeout:
+			c.JSON(http.StatusServiceUnavailable, e)
+		default:
 			c.JSON(http.StatusBadRequest, e)
-			return
 		}
+		return
 	}
 	c.JSON(http.StatusOK, BradcastTransactionsReturn{})
 }
@@ -100,6 +105,16 @@ func findTransactions(i interface{}, c *gin.Context, abortSignal <-chan struct{}
 		}
 
 		txHashes = append(txHashes, bundleBucket.TransactionHashes()...)
+
+		if archive.IsAPIFallbackEnabled() {
+			archivedTxHashes, err := archive.FindTransactionHashesForBundle(bdl, maxFindTransactions)
+			if err != nil {
+				e.Error = "Internal error"
+				c.JSON(http.StatusInternalServerError, e)
+				return
+			}
+			txHashes = append(txHashes, archivedTxHashes...)
+		}
 	}
 
 	// Searching for transactions that contains the given address
@@ -116,9 +131,24 @@ func findTransactions(i interface{}, c *gin.Context, abortSignal <-chan struct{}
 				return
 			}
 			txHashes = append(txHashes, tx...)
+
+			if archive.IsAPIFallbackEnabled() {
+				archivedTxHashes, err := archive.FindTransactionHashesForAddress(addr, maxFindTransactions)
+				if err != nil {
+					e.Error = "Internal error"
+					c.JSON(http.StatusInternalServerError, e)
+					return
+				}
+				txHashes = append(txHashes, archivedTxHashes...)
+			}
 		}
 	}
 
+	if archive.IsAPIFallbackEnabled() {
+		// transactions which were received again after they were pruned are in both databases
+		txHashes = removeDuplicateHashes(txHashes)
+	}
+
 	c.JSON(http.StatusOK, FindTransactionsReturn{Hashes: txHashes})
 }
 
@@ -126,3 +156,16 @@ func findTransactions(i interface{}, c *gin.Context, abortSignal <-chan struct{}
 func storeTransactions(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
 	broadcastTransactions(i, c, abortSignal)
 }
+
+func removeDuplicateHashes(hashes []string) []string {
+	seen := make(map[string]struct{}, len(hashes))
+	result := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		if _, exists := seen[hash]; exists {
+			continue
+		}
+		seen[hash] = struct{}{}
+		result = append(result, hash)
+	}
+	return result
+}
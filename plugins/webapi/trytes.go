@@ -14,6 +14,7 @@ import (
 
 	"github.com/gohornet/hornet/packages/model/tangle"
 	"github.com/gohornet/hornet/packages/parameter"
+	"github.com/gohornet/hornet/plugins/archive"
 )
 
 func init() {
@@ -57,6 +58,15 @@ func getTrytes(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
 			return
 		}
 
+		if t == nil && archive.IsAPIFallbackEnabled() {
+			t, err = archive.GetTransaction(hash)
+			if err != nil {
+				e.Error = "Internal error"
+				c.JSON(http.StatusInternalServerError, e)
+				return
+			}
+		}
+
 		if t != nil {
 			tx, err := transaction.TransactionToTrytes(t.Tx)
 			if err != nil {
@@ -1,8 +1,12 @@
 package webapi
 
 import (
+	hornetDB "github.com/gohornet/hornet/packages/database"
 	"github.com/gohornet/hornet/packages/model/queue"
+	"github.com/gohornet/hornet/packages/policy"
 	"github.com/gohornet/hornet/plugins/gossip"
+	"github.com/gohornet/hornet/plugins/snapshot"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
 )
 
 //////////////////// addNeighbors /////////////////////////////////
@@ -129,6 +133,53 @@ type GetBalancesReturn struct {
 
 ///////////////////////////////////////////////////////////////////
 
+/////////////////////// getBalancesAt /////////////////////////////
+
+// GetBalancesAt struct
+type GetBalancesAt struct {
+	Command        string   `json:"command"`
+	Addresses      []string `json:"addresses"`
+	MilestoneIndex uint32   `json:"milestoneIndex"`
+}
+
+// GetBalancesAtReturn struct
+type GetBalancesAtReturn struct {
+	Balances       []string `json:"balances"`
+	References     []string `json:"references"`
+	MilestoneIndex uint32   `json:"milestoneIndex"`
+	Duration       int      `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+////////////////// getAddressBalanceHistory ///////////////////////
+
+// GetAddressBalanceHistory struct
+type GetAddressBalanceHistory struct {
+	Command    string `json:"command"`
+	Address    string `json:"address"`
+	StartIndex uint32 `json:"startIndex"`
+	Limit      int    `json:"limit"`
+}
+
+// BalanceChange struct
+type BalanceChange struct {
+	MilestoneIndex uint32 `json:"milestoneIndex"`
+	Change         int64  `json:"change"`
+	Balance        string `json:"balance"`
+}
+
+// GetAddressBalanceHistoryReturn struct
+type GetAddressBalanceHistoryReturn struct {
+	Address string           `json:"address"`
+	Changes []*BalanceChange `json:"changes"`
+	// The start index of the next page, 0 if there are no more changes
+	NextIndex uint32 `json:"nextIndex"`
+	Duration  int    `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
 /////////////////// getInclusionStates ////////////////////////////
 
 // GetInclusionStates struct
@@ -184,7 +235,11 @@ type GetNodeInfoReturn struct {
 	TransactionsToRequest              int      `json:"transactionsToRequest"`
 	Features                           []string `json:"features"`
 	CoordinatorAddress                 string   `json:"coordinatorAddress"`
-	Duration                           int      `json:"duration"`
+	// the size of the LSM tree and the value log files of the database in bytes
+	DatabaseSizeLSM      int64                             `json:"dbSizeLSM"`
+	DatabaseSizeValueLog int64                             `json:"dbSizeValueLog"`
+	LastValueLogGC       *hornetDB.GarbageCollectionResult `json:"lastValueLogGC,omitempty"`
+	Duration             int                               `json:"duration"`
 }
 
 ///////////////////////////////////////////////////////////////////
@@ -352,6 +407,40 @@ type GetLedgerDiffExtReturn struct {
 	Duration                  int                `json:"duration"`
 }
 
+// StreamLedgerDiffs struct
+type StreamLedgerDiffs struct {
+	Command   string `json:"command"`
+	FromIndex uint64 `json:"fromIndex"`
+	// 0 streams up to the latest solid milestone
+	ToIndex        uint64 `json:"toIndex"`
+	IncludeBundles bool   `json:"includeBundles"`
+}
+
+// LedgerDiffStreamEntry struct
+type LedgerDiffStreamEntry struct {
+	Type                      string             `json:"type"`
+	MilestoneIndex            uint64             `json:"milestoneIndex"`
+	MilestoneHash             string             `json:"milestoneHash,omitempty"`
+	Diff                      map[string]int64   `json:"diff"`
+	ConfirmedBundlesWithValue []*BundleWithValue `json:"confirmedBundlesWithValue,omitempty"`
+}
+
+// LedgerDiffStreamError struct
+type LedgerDiffStreamError struct {
+	Type           string `json:"type"`
+	MilestoneIndex uint64 `json:"milestoneIndex"`
+	Error          string `json:"error"`
+}
+
+// LedgerDiffStreamEnd struct
+type LedgerDiffStreamEnd struct {
+	Type string `json:"type"`
+	// The index to resume the stream with
+	NextIndex uint64 `json:"nextIndex"`
+	// Whether all milestones of the requested range were streamed
+	Complete bool `json:"complete"`
+}
+
 ///////////////////////////////////////////////////////////////////
 
 /////////////////// createSnapshot ////////////////////////
@@ -381,3 +470,394 @@ type GetRequestsReturn struct {
 }
 
 ///////////////////////////////////////////////////////////////////
+
+/////////////////// getPolicyStatus ///////////////////////////////
+
+// GetPolicyStatus struct
+type GetPolicyStatus struct {
+	Command string `json:"command"`
+}
+
+// GetPolicyStatusReturn struct
+type GetPolicyStatusReturn struct {
+	Rules    []*policy.RuleStatus `json:"rules"`
+	Duration int                  `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+/////////////////// validateBundle ////////////////////////////////
+
+// ValidateBundle struct
+type ValidateBundle struct {
+	Command string   `json:"command"`
+	Trytes  []string `json:"trytes"`
+}
+
+// BundleOverspend struct
+type BundleOverspend struct {
+	Address  string `json:"address"`
+	Balance  uint64 `json:"balance"`
+	ConeDiff int64  `json:"coneDiff"`
+	Change   int64  `json:"change"`
+}
+
+// ValidateBundleReturn struct
+type ValidateBundleReturn struct {
+	Valid             bool               `json:"valid"`
+	BundleHash        string             `json:"bundleHash"`
+	Info              string             `json:"info,omitempty"`
+	InvalidSignatures []string           `json:"invalidSignatures,omitempty"`
+	Overspends        []*BundleOverspend `json:"overspends,omitempty"`
+	ConeChecked       bool               `json:"coneChecked"`
+	Duration          int                `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+/////////////////// prepareBundleEssence //////////////////////////
+
+// BundleInput struct
+type BundleInput struct {
+	Address  string `json:"address"`
+	Security int    `json:"security"`
+}
+
+// BundleOutput struct
+type BundleOutput struct {
+	Address string `json:"address"`
+	Value   uint64 `json:"value"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+// PrepareBundleEssence struct
+type PrepareBundleEssence struct {
+	Command          string          `json:"command"`
+	Inputs           []*BundleInput  `json:"inputs"`
+	Outputs          []*BundleOutput `json:"outputs"`
+	RemainderAddress string          `json:"remainderAddress"`
+}
+
+// PrepareBundleEssenceReturn struct
+type PrepareBundleEssenceReturn struct {
+	BundleHash string   `json:"bundleHash"`
+	Trytes     []string `json:"trytes"`
+	Remainder  uint64   `json:"remainder"`
+	Duration   int      `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+/////////////////// finalizeSignedBundle //////////////////////////
+
+// BundleSignature struct
+type BundleSignature struct {
+	Address                   string   `json:"address"`
+	SignatureMessageFragments []string `json:"signatureMessageFragments"`
+}
+
+// FinalizeSignedBundle struct
+type FinalizeSignedBundle struct {
+	Command    string             `json:"command"`
+	Trytes     []string           `json:"trytes"`
+	Signatures []*BundleSignature `json:"signatures"`
+}
+
+// FinalizeSignedBundleReturn struct
+type FinalizeSignedBundleReturn struct {
+	Trytes     []string              `json:"trytes"`
+	Validation *ValidateBundleReturn `json:"validation"`
+	Duration   int                   `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+/////////////////// bundle tracker ////////////////////////////////
+
+// TrackBundles struct
+type TrackBundles struct {
+	Command string   `json:"command"`
+	Tails   []string `json:"tails"`
+}
+
+// UntrackBundles struct
+type UntrackBundles struct {
+	Command string   `json:"command"`
+	Tails   []string `json:"tails"`
+}
+
+// UntrackBundlesReturn struct
+type UntrackBundlesReturn struct {
+	Duration int `json:"duration"`
+}
+
+// GetTrackedBundles struct
+type GetTrackedBundles struct {
+	Command string   `json:"command"`
+	Tails   []string `json:"tails"`
+}
+
+// TrackedBundleStatus struct
+type TrackedBundleStatus struct {
+	TailHash          string `json:"tailHash"`
+	BundleHash        string `json:"bundleHash"`
+	LatestTailHash    string `json:"latestTailHash"`
+	RegisteredAt      int64  `json:"registeredAt"`
+	Promotions        uint32 `json:"promotions"`
+	Reattachments     uint32 `json:"reattachments"`
+	Confirmed         bool   `json:"confirmed"`
+	ConfirmationIndex uint32 `json:"confirmationIndex"`
+}
+
+// GetTrackedBundlesReturn struct
+type GetTrackedBundlesReturn struct {
+	TrackedBundles []*TrackedBundleStatus `json:"trackedBundles"`
+	Duration       int                    `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+/////////////////// getBundleStatus ///////////////////////////////
+
+// GetBundleStatus struct
+type GetBundleStatus struct {
+	Command string   `json:"command"`
+	Bundles []string `json:"bundles"`
+}
+
+// BundleTailInfo struct
+type BundleTailInfo struct {
+	TailHash                      string `json:"tailHash"`
+	FirstSeenTimestamp            int64  `json:"firstSeenTimestamp"`
+	FirstSeenLatestMilestoneIndex uint32 `json:"firstSeenLatestMilestoneIndex"`
+	Complete                      bool   `json:"complete"`
+	Valid                         bool   `json:"valid"`
+	Confirmed                     bool   `json:"confirmed"`
+	ConfirmationIndex             uint32 `json:"confirmationIndex"`
+}
+
+// BundleStatus struct
+type BundleStatus struct {
+	BundleHash        string            `json:"bundleHash"`
+	Tails             []*BundleTailInfo `json:"tails"`
+	ConfirmedTail     string            `json:"confirmedTail,omitempty"`
+	ConfirmationIndex uint32            `json:"confirmationIndex"`
+}
+
+// GetBundleStatusReturn struct
+type GetBundleStatusReturn struct {
+	Bundles  []*BundleStatus `json:"bundles"`
+	Duration int             `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+/////////////////// getConflicts //////////////////////////////////
+
+// GetConflicts struct
+type GetConflicts struct {
+	Command string `json:"command"`
+}
+
+// ConflictingSpendInfo struct
+type ConflictingSpendInfo struct {
+	BundleHash        string   `json:"bundleHash"`
+	Value             int64    `json:"value"`
+	Tails             []string `json:"tails"`
+	Confirmed         bool     `json:"confirmed"`
+	ConfirmationIndex uint32   `json:"confirmationIndex"`
+}
+
+// ConflictInfo struct
+type ConflictInfo struct {
+	Address    string                  `json:"address"`
+	Status     string                  `json:"status"`
+	DetectedAt int64                   `json:"detectedAt"`
+	Spends     []*ConflictingSpendInfo `json:"spends"`
+}
+
+// GetConflictsReturn struct
+type GetConflictsReturn struct {
+	Conflicts []*ConflictInfo `json:"conflicts"`
+	Duration  int             `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+//////////////////////// listSnapshots ////////////////////////////
+
+// ListSnapshots struct
+type ListSnapshots struct {
+	Command string `json:"command"`
+}
+
+// ListSnapshotsReturn struct
+type ListSnapshotsReturn struct {
+	Snapshots []*snapshot.LocalSnapshotFileInfo `json:"snapshots"`
+	Duration  int                               `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+/////////////////////// compactDatabase ///////////////////////////
+
+// CompactDatabase struct
+type CompactDatabase struct {
+	Command string `json:"command"`
+}
+
+// GetCompactionStatus struct
+type GetCompactionStatus struct {
+	Command string `json:"command"`
+}
+
+// CompactDatabaseReturn struct
+type CompactDatabaseReturn struct {
+	Compaction *tanglePlugin.CompactionStatus `json:"compaction"`
+	Duration   int                            `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+//////////////////////// pruning //////////////////////////////////
+
+// PruneDatabase struct
+type PruneDatabase struct {
+	Command     string `json:"command"`
+	TargetIndex uint32 `json:"targetIndex"`
+}
+
+// AbortPruning struct
+type AbortPruning struct {
+	Command string `json:"command"`
+}
+
+// GetPruningStatus struct
+type GetPruningStatus struct {
+	Command string `json:"command"`
+}
+
+// GetPruningStatusReturn struct
+type GetPruningStatusReturn struct {
+	PruningStatus *snapshot.PruningStatus `json:"pruningStatus"`
+	Duration      int                     `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+/////////////////////// watchlist ///////////////////////////////
+
+// AddWatchlistEntries struct
+type AddWatchlistEntries struct {
+	Command   string   `json:"command"`
+	Addresses []string `json:"addresses"`
+	Tags      []string `json:"tags"`
+}
+
+// RemoveWatchlistEntries struct
+type RemoveWatchlistEntries struct {
+	Command   string   `json:"command"`
+	Addresses []string `json:"addresses"`
+	Tags      []string `json:"tags"`
+}
+
+// GetWatchlist struct
+type GetWatchlist struct {
+	Command string `json:"command"`
+}
+
+// GetWatchlistReturn struct
+type GetWatchlistReturn struct {
+	// the addresses are returned without checksum, the tags padded to 27 trytes
+	Addresses []string `json:"addresses"`
+	Tags      []string `json:"tags"`
+	Duration  int      `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+//////////////////////// exportLedger /////////////////////////////
+
+// ExportLedger struct
+type ExportLedger struct {
+	Command string `json:"command"`
+	// "csv" or "ndjson"
+	Format string `json:"format"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+//////////////////////// auditLedger //////////////////////////////
+
+// AuditLedger struct
+type AuditLedger struct {
+	Command string `json:"command"`
+}
+
+// AuditLedgerReturn struct
+type AuditLedgerReturn struct {
+	Valid          bool     `json:"valid"`
+	MilestoneIndex uint32   `json:"milestoneIndex"`
+	AddressCount   int      `json:"addressCount"`
+	Supply         uint64   `json:"supply"`
+	Violations     []string `json:"violations,omitempty"`
+	Duration       int      `json:"duration"`
+}
+
+// GetLedgerAuditStatus struct
+type GetLedgerAuditStatus struct {
+	Command string `json:"command"`
+}
+
+// GetLedgerAuditStatusReturn struct
+type GetLedgerAuditStatusReturn struct {
+	Violated  bool   `json:"violated"`
+	Violation string `json:"violation,omitempty"`
+	Duration  int    `json:"duration"`
+}
+
+// ResolveLedgerAudit struct
+type ResolveLedgerAudit struct {
+	Command string `json:"command"`
+	// Reset the violation without recounting the ledger
+	Force bool `json:"force"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+/////////////////////// rollbackLedger ////////////////////////////
+
+// RollbackLedger struct
+type RollbackLedger struct {
+	Command     string `json:"command"`
+	TargetIndex uint32 `json:"targetIndex"`
+}
+
+// RollbackLedgerReturn struct
+type RollbackLedgerReturn struct {
+	SolidMilestoneIndex uint32 `json:"solidMilestoneIndex"`
+	Duration            int    `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
+
+/////////////////////// createBackup //////////////////////////////
+
+// CreateBackup struct
+type CreateBackup struct {
+	Command string `json:"command"`
+	// Only back up the changes since the latest backup
+	Incremental bool `json:"incremental"`
+}
+
+// CreateBackupReturn struct
+type CreateBackupReturn struct {
+	FilePath       string `json:"filePath"`
+	MilestoneIndex uint32 `json:"milestoneIndex"`
+	Version        uint64 `json:"version"`
+	Since          uint64 `json:"since"`
+	Duration       int    `json:"duration"`
+}
+
+///////////////////////////////////////////////////////////////////
@@ -0,0 +1,143 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mitchellh/mapstructure"
+
+	"github.com/iotaledger/iota.go/address"
+	"github.com/iotaledger/iota.go/consts"
+	"github.com/iotaledger/iota.go/guards"
+	"github.com/iotaledger/iota.go/trinary"
+
+	"github.com/gohornet/hornet/packages/model/tangle"
+)
+
+func init() {
+	addEndpoint("addWatchlistEntries", addWatchlistEntries, implementedAPIcalls)
+	addEndpoint("removeWatchlistEntries", removeWatchlistEntries, implementedAPIcalls)
+	addEndpoint("getWatchlist", getWatchlist, implementedAPIcalls)
+}
+
+// validateWatchlistEntries checks the given addresses and tags and returns the addresses without checksum.
+func validateWatchlistEntries(addresses []string, tags []string) ([]trinary.Hash, error) {
+
+	if len(addresses) == 0 && len(tags) == 0 {
+		return nil, fmt.Errorf("No addresses or tags provided")
+	}
+
+	var result []trinary.Hash
+	for _, addr := range addresses {
+		if err := address.ValidAddress(addr); err != nil {
+			return nil, fmt.Errorf("Invalid address supplied: %s", addr)
+		}
+		result = append(result, addr[:81])
+	}
+
+	for _, tag := range tags {
+		if tag == "" || !guards.IsTrytesOfMaxLength(tag, consts.TagTrinarySize/3) {
+			return nil, fmt.Errorf("Invalid tag supplied: %s", tag)
+		}
+	}
+
+	return result, nil
+}
+
+func addWatchlistEntries(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	aw := &AddWatchlistEntries{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, aw)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	addresses, err := validateWatchlistEntries(aw.Addresses, aw.Tags)
+	if err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	for _, addr := range addresses {
+		if err := tangle.AddWatchlistAddress(addr); err != nil {
+			e.Error = err.Error()
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+	}
+
+	for _, tag := range aw.Tags {
+		if err := tangle.AddWatchlistTag(tag); err != nil {
+			e.Error = err.Error()
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, newGetWatchlistReturn())
+}
+
+func removeWatchlistEntries(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	rw := &RemoveWatchlistEntries{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, rw)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	addresses, err := validateWatchlistEntries(rw.Addresses, rw.Tags)
+	if err != nil {
+		e.Error = err.Error()
+		c.JSON(http.StatusBadRequest, e)
+		return
+	}
+
+	for _, addr := range addresses {
+		if err := tangle.RemoveWatchlistAddress(addr); err != nil {
+			e.Error = err.Error()
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+	}
+
+	for _, tag := range rw.Tags {
+		if err := tangle.RemoveWatchlistTag(tag); err != nil {
+			e.Error = err.Error()
+			c.JSON(http.StatusInternalServerError, e)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, newGetWatchlistReturn())
+}
+
+func getWatchlist(i interface{}, c *gin.Context, abortSignal <-chan struct{}) {
+	gw := &GetWatchlist{}
+	e := ErrorReturn{}
+
+	err := mapstructure.Decode(i, gw)
+	if err != nil {
+		e.Error = "Internal error"
+		c.JSON(http.StatusInternalServerError, e)
+		return
+	}
+
+	c.JSON(http.StatusOK, newGetWatchlistReturn())
+}
+
+func newGetWatchlistReturn() *GetWatchlistReturn {
+	addresses, tags := tangle.GetWatchlist()
+	sort.Strings(addresses)
+	sort.Strings(tags)
+
+	return &GetWatchlistReturn{Addresses: addresses, Tags: tags}
+}
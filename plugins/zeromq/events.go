@@ -10,6 +10,7 @@ import (
 	"github.com/gohornet/hornet/packages/model/hornet"
 	"github.com/gohornet/hornet/packages/model/milestone_index"
 	"github.com/gohornet/hornet/packages/model/tangle"
+	tanglePlugin "github.com/gohornet/hornet/plugins/tangle"
 )
 
 var (
@@ -66,6 +67,20 @@ func onNewSolidMilestone(bundle *tangle.Bundle) {
 	}
 }
 
+func onConflictDetected(conflict *tanglePlugin.Conflict) {
+	err := publishConflict(conflict)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
+func onLedgerInvariantViolated(reason string) {
+	err := publishLedgerAlert(reason)
+	if err != nil {
+		log.Error(err.Error())
+	}
+}
+
 // Publish latest milestone index
 func publishLMI(lmi milestone_index.MilestoneIndex) error {
 
@@ -167,3 +182,26 @@ func publishConfTxForAddress(tx *hornet.Transaction, msIndex milestone_index.Mil
 
 	return publisher.Send(addr, messages)
 }
+
+// Publish a conflict between bundles spending from the same address
+func publishConflict(conflict *tanglePlugin.Conflict) error {
+
+	messages := []string{
+		conflict.Address, // Address
+	}
+	for _, spend := range conflict.Spends {
+		messages = append(messages, spend.BundleHash+":"+strconv.FormatInt(spend.Value, 10)) // Bundle hash and spent value
+	}
+
+	return publisher.Send(topicConflict, messages)
+}
+
+// Publish a violation of the ledger invariants, balances are not served anymore until an operator acts
+func publishLedgerAlert(reason string) error {
+
+	messages := []string{
+		reason, // Description of the violated invariants
+	}
+
+	return publisher.Send(topicLedgerAlert, messages)
+}
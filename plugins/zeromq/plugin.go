@@ -43,6 +43,14 @@ var (
 	newSolidMilestoneWorkerQueueSize = 100
 	newSolidMilestoneWorkerPool      *workerpool.WorkerPool
 
+	conflictWorkerCount     = 1
+	conflictWorkerQueueSize = 100
+	conflictWorkerPool      *workerpool.WorkerPool
+
+	ledgerAlertWorkerCount     = 1
+	ledgerAlertWorkerQueueSize = 100
+	ledgerAlertWorkerPool      *workerpool.WorkerPool
+
 	wasSyncBefore = false
 
 	publisher *Publisher
@@ -71,6 +79,16 @@ func configure(plugin *node.Plugin) {
 		onNewSolidMilestone(task.Param(0).(*tanglePackage.Bundle))
 		task.Return(nil)
 	}, workerpool.WorkerCount(newSolidMilestoneWorkerCount), workerpool.QueueSize(newSolidMilestoneWorkerQueueSize))
+
+	conflictWorkerPool = workerpool.New(func(task workerpool.Task) {
+		onConflictDetected(task.Param(0).(*tangle.Conflict))
+		task.Return(nil)
+	}, workerpool.WorkerCount(conflictWorkerCount), workerpool.QueueSize(conflictWorkerQueueSize))
+
+	ledgerAlertWorkerPool = workerpool.New(func(task workerpool.Task) {
+		onLedgerInvariantViolated(task.Param(0).(string))
+		task.Return(nil)
+	}, workerpool.WorkerCount(ledgerAlertWorkerCount), workerpool.QueueSize(ledgerAlertWorkerQueueSize))
 }
 
 // Start the zeromq plugin
@@ -115,6 +133,19 @@ func run(plugin *node.Plugin) {
 		newSolidMilestoneWorkerPool.TrySubmit(bundle)
 	})
 
+	notifyConflictDetected := events.NewClosure(func(conflict *tangle.Conflict) {
+		if !wasSyncBefore {
+			return
+		}
+
+		conflictWorkerPool.TrySubmit(conflict)
+	})
+
+	// the alert is triggered while the ledger is write locked, so it is published by the worker pool
+	notifyLedgerInvariantViolated := events.NewClosure(func(reason string) {
+		ledgerAlertWorkerPool.TrySubmit(reason)
+	})
+
 	daemon.BackgroundWorker("ZeroMQ Publisher", func(shutdownSignal <-chan struct{}) {
 		log.Info("Starting ZeroMQ Publisher ... done")
 		log.Infof("You can now listen to ZMQ via: %s://%s:%d", parameter.NodeConfig.GetString("zmq.protocol"), parameter.NodeConfig.GetString("zmq.host"), parameter.NodeConfig.GetInt("zmq.port"))
@@ -178,6 +209,28 @@ func run(plugin *node.Plugin) {
 		newSolidMilestoneWorkerPool.StopAndWait()
 		log.Info("Stopping ZeroMQ[NewSolidMilestoneWorker] ... done")
 	}, shutdown.ShutdownPriorityMetricsPublishers)
+
+	daemon.BackgroundWorker("ZeroMQ[ConflictWorker]", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting ZeroMQ[ConflictWorker] ... done")
+		tangle.Events.ConflictDetected.Attach(notifyConflictDetected)
+		conflictWorkerPool.Start()
+		<-shutdownSignal
+		log.Info("Stopping ZeroMQ[ConflictWorker] ...")
+		tangle.Events.ConflictDetected.Detach(notifyConflictDetected)
+		conflictWorkerPool.StopAndWait()
+		log.Info("Stopping ZeroMQ[ConflictWorker] ... done")
+	}, shutdown.ShutdownPriorityMetricsPublishers)
+
+	daemon.BackgroundWorker("ZeroMQ[LedgerAlertWorker]", func(shutdownSignal <-chan struct{}) {
+		log.Info("Starting ZeroMQ[LedgerAlertWorker] ... done")
+		tangle.Events.LedgerInvariantViolated.Attach(notifyLedgerInvariantViolated)
+		ledgerAlertWorkerPool.Start()
+		<-shutdownSignal
+		log.Info("Stopping ZeroMQ[LedgerAlertWorker] ...")
+		tangle.Events.LedgerInvariantViolated.Detach(notifyLedgerInvariantViolated)
+		ledgerAlertWorkerPool.StopAndWait()
+		log.Info("Stopping ZeroMQ[LedgerAlertWorker] ... done")
+	}, shutdown.ShutdownPriorityMetricsPublishers)
 }
 
 // Start the zmq publisher.
@@ -10,12 +10,14 @@ import (
 
 // Topic names
 const (
-	topicLMI      = "lmi"
-	topicLMSI     = "lmsi"
-	topicLMHS     = "lmhs"
-	topicSN       = "sn"
-	topicTxTrytes = "tx_trytes"
-	topicTX       = "tx"
+	topicLMI         = "lmi"
+	topicLMSI        = "lmsi"
+	topicLMHS        = "lmhs"
+	topicSN          = "sn"
+	topicTxTrytes    = "tx_trytes"
+	topicTX          = "tx"
+	topicConflict    = "conflict"
+	topicLedgerAlert = "ledger_alert"
 )
 
 var (
@@ -27,6 +29,8 @@ var (
 		topicSN,
 		topicTxTrytes,
 		topicTX,
+		topicConflict,
+		topicLedgerAlert,
 	}
 
 	addressTopics AddressTopics